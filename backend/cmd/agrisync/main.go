@@ -0,0 +1,217 @@
+// Command agrisync runs operator tasks against the configured database:
+//
+//	agrisync backup [-dir d] [-no-compress] [-key-file f] [-no-prune]
+//	agrisync list [-dir d]
+//	agrisync verify [-key-file f] <backup>
+//	agrisync prune [-dir d]
+//	agrisync restore [-db path] [-key-file f] <backup>
+//	agrisync config print [--redacted]
+//	agrisync admin-token [-user id]
+//
+// Settings come from the config file and environment (see package
+// config). Backups are taken online; restore needs the API stopped.
+// Admins have no login: admin-token prints a token signed with jwt_secret
+// that lasts token_ttl.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"agri-sync-backend/internal/auth"
+	"agri-sync-backend/internal/backup"
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/database"
+)
+
+var commands = map[string]func(cfg *config.Config, args []string){
+	"backup":      runBackup,
+	"list":        runList,
+	"verify":      runVerify,
+	"prune":       runPrune,
+	"restore":     runRestore,
+	"admin-token": runAdminToken,
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	// config loads the settings itself, to show them even when invalid
+	if os.Args[1] == "config" {
+		runConfig(os.Args[2:])
+		return
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	run(config.LoadConfig(), os.Args[2:])
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: agrisync backup|list|verify|prune|restore|config|admin-token [flags] [args]")
+	os.Exit(2)
+}
+
+// backupFlags adds the flags shared by the backup commands, defaulting to
+// cfg, and returns a function that builds the Options once parsed.
+func backupFlags(fs *flag.FlagSet, cfg *config.Config) func() backup.Options {
+	dir := fs.String("dir", cfg.BackupDir, "backup folder")
+	keyFile := fs.String("key-file", cfg.BackupKeyFile, "32-byte key file; encrypts new backups, decrypts old ones")
+	return func() backup.Options {
+		cfg.BackupDir, cfg.BackupKeyFile = *dir, *keyFile
+		opts, err := backup.OptionsFrom(cfg)
+		if err != nil {
+			log.Fatalf("Invalid backup settings: %v", err)
+		}
+		return opts
+	}
+}
+
+func requireSQLite(cfg *config.Config) {
+	if cfg.DBDriver != config.DriverSQLite {
+		log.Fatalf("Backups cover the SQLite database only; use pg_dump for %s", cfg.DBDriver)
+	}
+}
+
+func runBackup(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	options := backupFlags(fs, cfg)
+	noCompress := fs.Bool("no-compress", !cfg.BackupCompress, "write the database uncompressed")
+	noPrune := fs.Bool("no-prune", false, "keep every old backup")
+	fs.Parse(args)
+	requireSQLite(cfg)
+
+	opts := options()
+	opts.Compress = !*noCompress
+	if *noPrune {
+		opts.Policy = backup.Policy{}
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect DB: %v", err)
+	}
+	defer db.Close()
+
+	m, err := backup.Create(context.Background(), db, opts)
+	if m == nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	fmt.Printf("✅ %s (schema version %d, %d bytes)\n", m.File, m.SchemaVersion, m.Size)
+	if err != nil {
+		log.Fatalf("⚠️ %v", err)
+	}
+}
+
+func runList(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	options := backupFlags(fs, cfg)
+	fs.Parse(args)
+
+	list, err := backup.List(options().Dir)
+	if err != nil {
+		log.Fatalf("List failed: %v", err)
+	}
+	for _, m := range list {
+		flags := ""
+		if m.Compressed {
+			flags += " gz"
+		}
+		if m.Encrypted {
+			flags += " enc"
+		}
+		fmt.Printf("%s  %s  v%d  %d bytes%s\n", m.CreatedAt.Local().Format(time.DateTime), m.File, m.SchemaVersion, m.Size, flags)
+	}
+	fmt.Printf("%d backup(s)\n", len(list))
+}
+
+func runVerify(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	options := backupFlags(fs, cfg)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("Usage: agrisync verify [-key-file f] <backup>")
+	}
+
+	m, err := backup.Verify(fs.Arg(0), options().Key)
+	if err != nil {
+		log.Fatalf("❌ %s: %v", fs.Arg(0), err)
+	}
+	fmt.Printf("✅ %s is valid (schema version %d)\n", m.File, m.SchemaVersion)
+}
+
+func runPrune(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	options := backupFlags(fs, cfg)
+	fs.Parse(args)
+
+	opts := options()
+	deleted, err := backup.Prune(opts.Dir, opts.Policy, time.Now())
+	for _, name := range deleted {
+		fmt.Println("deleted", name)
+	}
+	if err != nil {
+		log.Fatalf("Prune failed: %v", err)
+	}
+	fmt.Printf("%d backup(s) pruned\n", len(deleted))
+}
+
+func runRestore(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	options := backupFlags(fs, cfg)
+	dbPath := fs.String("db", cfg.DBPath, "SQLite database to replace")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("Usage: agrisync restore [-db path] [-key-file f] <backup>")
+	}
+	requireSQLite(cfg)
+
+	restored, err := backup.Restore(fs.Arg(0), *dbPath, options().Key)
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+	fmt.Printf("✅ Restored %s to %s (schema version %d)\n", restored.Manifest.File, *dbPath, restored.Manifest.SchemaVersion)
+	if restored.Previous != "" {
+		fmt.Println("Previous database kept at", restored.Previous)
+	}
+	if restored.Pending > 0 {
+		fmt.Printf("%d migration(s) will run when the API starts\n", restored.Pending)
+	}
+}
+
+func runAdminToken(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("admin-token", flag.ExitOnError)
+	user := fs.String("user", "admin", "user id the token acts as, as recorded in the audit log")
+	fs.Parse(args)
+
+	auth.Configure(cfg.JWTSecret, cfg.TokenTTL)
+	token, err := auth.GenerateJWT(*user, "admin")
+	if err != nil {
+		log.Fatalf("Failed to sign token: %v", err)
+	}
+	fmt.Println(token)
+}
+
+func runConfig(args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		log.Fatal("Usage: agrisync config print [--redacted]")
+	}
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	redact := fs.Bool("redacted", false, "hide secrets")
+	fs.Parse(args[1:])
+
+	cfg, err := config.Load()
+	if printErr := cfg.Print(os.Stdout, *redact); printErr != nil {
+		log.Fatal(printErr)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"agri-sync-backend/internal/config"
+)
+
+// TestBackupFlags checks the last layer of precedence: a flag given on the
+// command line beats the loaded config, and one left out keeps it.
+func TestBackupFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no flag keeps the config", nil, "/from/config"},
+		{"flag over config", []string{"-dir", "/from/flag"}, "/from/flag"},
+		{"empty flag is still a flag", []string{"-dir="}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Defaults()
+			cfg.BackupDir = "/from/config"
+			fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+			options := backupFlags(fs, cfg)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := options().Dir; got != tt.want {
+				t.Errorf("dir = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,27 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"agri-sync-backend/internal/buildinfo"
 	"agri-sync-backend/internal/config"
 	"agri-sync-backend/internal/database"
+	"agri-sync-backend/internal/logging"
+	"agri-sync-backend/internal/repository"
 	"agri-sync-backend/internal/server"
+	"agri-sync-backend/internal/tracing"
 )
 
+// traceFlushTimeout bounds sending the last spans on shutdown.
+const traceFlushTimeout = 5 * time.Second
+
 func main() {
 	// router := server.SetupRouter(db)
 
@@ -18,25 +31,44 @@ func main() {
 	// }
 
 	cfg := config.LoadConfig()
+	if err := logging.Setup(os.Stderr, cfg.LogFormat, cfg.LogLevel); err != nil {
+		log.Fatalf("Logging setup failed: %v", err)
+	}
+	slog.Info("AgriSync API", "version", buildinfo.Version, "commit", buildinfo.Commit, "built", buildinfo.BuildTime)
+	switch {
+	case cfg.DevMode && cfg.Source("jwt_secret") == "dev_mode":
+		slog.Warn("dev_mode is on and tokens are signed with the public development secret; never use it in production")
+	case cfg.DevMode:
+		slog.Warn("dev_mode is on; never use it in production")
+	}
+
+	flushTraces, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal("Tracing setup failed", err)
+	}
 
 	// -------------------------
-	// 1️⃣ Connect to SQLite
+	// 1️⃣ Connect to the database (SQLite or PostgreSQL)
 	// -------------------------
-	migrationsDir := "./internal/database/migrations"      // folder with your .up.sql / .down.sql files
-
-	db, err := database.ConnectSQLite(cfg.DBPath)
+	db, err := database.Connect(cfg)
 	if err != nil {
-		log.Fatalf("DB connection failed: %v", err)
+		fatal("DB connection failed", err)
 	}
 	defer db.Close()
+	repository.SetQueryTimeout(cfg.DBQueryTimeout)
 
-	fmt.Println("Using DB at:", cfg.DBPath)
+	slog.Info("Using database", "db", database.Describe(cfg))
 
 	// -------------------------
-	// 2️⃣ Run migrations
+	// 2️⃣ Run migrations (embedded in the binary). A dirty schema, left by
+	// a migration that failed partway, must be repaired before serving.
 	// -------------------------
-	if err := database.RunMigrations(db, migrationsDir); err != nil {
-		log.Fatalf("Migrations failed: %v", err)
+	if err := database.RunMigrations(db, cfg.DBDriver); err != nil {
+		var dirty *database.DirtyError
+		if errors.As(err, &dirty) {
+			fatal("Refusing to start", err)
+		}
+		fatal("Migrations failed", err)
 	}
 
 	// -------------------------
@@ -51,12 +83,40 @@ func main() {
 	// ...
 	// TODO: wire handlers and HTTP server here
 
-	log.Println("✅ Database ready, services can be initialized here")
+	slog.Info("Database ready")
 
-	router := server.SetupRouter(db)
+	srv, err := server.New(db, cfg)
+	if err != nil {
+		fatal("Server setup failed", err)
+	}
+
+	// SIGTERM (or Ctrl-C) starts a graceful shutdown; a second one stops
+	// the process at once.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
-	log.Println("AgriSync API starting on :8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	err = srv.Run(ctx)
+
+	// Spans still buffered go out before the process ends
+	flushCtx, cancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+	if flushErr := flushTraces(flushCtx); flushErr != nil {
+		slog.Warn("Some traces were not sent", "error", flushErr)
 	}
-}
\ No newline at end of file
+	cancel()
+
+	if err != nil {
+		slog.Error("Server stopped", "error", err)
+		db.Close()
+		os.Exit(1)
+	}
+	slog.Info("Shut down cleanly")
+}
+
+// fatal logs err and exits. Deferred calls do not run.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
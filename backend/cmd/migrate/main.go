@@ -1,40 +1,121 @@
+// Command migrate manages the schema of the configured database. The
+// migrations are compiled in, so it runs from any folder:
+//
+//	migrate [-dry-run] up            apply every pending migration
+//	migrate [-dry-run] down          roll back the latest migration
+//	migrate [-dry-run] goto <version> migrate up or down to version
+//	migrate status                   print the version and dirty flag
+//	migrate force <version>          record version after a dirty schema
+//	                                 was repaired by hand (-1: none)
+//	migrate create <name>            add empty up/down files for both drivers
+//
+// -dry-run prints the SQL that would run instead of running it. create
+// writes to -dir, the source folder; rebuild to pick the new files up.
 package main
 
 import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"agri-sync-backend/internal/config"
 	"agri-sync-backend/internal/database"
 )
 
 func main() {
-	action := flag.String("action", "up", "migration action: up, down, status")
+	action := flag.String("action", "up", "migration action: up, down, goto, status, force, create (or give it as the first argument)")
+	dryRun := flag.Bool("dry-run", false, "print the pending SQL instead of running it (up, down, goto)")
+	dir := flag.String("dir", "./internal/database/migrations", "migrations source folder, for create")
 	flag.Parse()
 
+	args := flag.Args()
+	if len(args) > 0 {
+		*action, args = args[0], args[1:]
+	}
+	arg := func(what string) string {
+		if len(args) != 1 {
+			log.Fatalf("Usage: migrate %s <%s>", *action, what)
+		}
+		return args[0]
+	}
+
+	if *action == "create" {
+		if err := create(*dir, arg("name")); err != nil {
+			log.Fatalf("Create failed: %v", err)
+		}
+		return
+	}
+
 	cfg := config.LoadConfig()
 
-	db, err := database.ConnectSQLite(cfg.DBPath)
+	db, err := database.Connect(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect DB: %v", err)
 	}
+	defer db.Close()
 
-	fmt.Println("Using DB at:", cfg.DBPath)
+	fmt.Println("Using DB at:", database.Describe(cfg))
 
 	switch *action {
 	case "up":
-		if err := database.RunMigrations(db, "./internal/database/migrations"); err != nil {
+		if *dryRun {
+			latest, err := database.LatestVersion(cfg.DBDriver)
+			if err != nil {
+				log.Fatalf("Failed to read migrations: %v", err)
+			}
+			printPlan(db, cfg.DBDriver, int(latest))
+			return
+		}
+		if err := database.RunMigrations(db, cfg.DBDriver); err != nil {
 			log.Fatalf("Migration up failed: %v", err)
 		}
 		log.Println("✅ Migrations applied")
 	case "down":
-		if err := database.RunMigrationsDown(db, "./internal/database/migrations"); err != nil {
+		if *dryRun {
+			version, _, err := database.MigrationStatus(db, cfg.DBDriver)
+			if err != nil {
+				log.Fatalf("Migration status failed: %v", err)
+			}
+			target, err := database.PreviousVersion(cfg.DBDriver, version)
+			if err != nil {
+				log.Fatalf("Failed to read migrations: %v", err)
+			}
+			printPlan(db, cfg.DBDriver, target)
+			return
+		}
+		if err := database.RunMigrationsDown(db, cfg.DBDriver); err != nil {
 			log.Fatalf("Migration down failed: %v", err)
 		}
 		log.Println("⬇️ Migration rolled back")
+	case "goto":
+		version, err := strconv.ParseUint(arg("version"), 10, 0)
+		if err != nil {
+			log.Fatalf("Invalid version %q", args[0])
+		}
+		if *dryRun {
+			printPlan(db, cfg.DBDriver, int(version))
+			return
+		}
+		if err := database.MigrateTo(db, cfg.DBDriver, uint(version)); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Printf("✅ Migrated to version %d\n", version)
+	case "force":
+		version, err := strconv.Atoi(arg("version"))
+		if err != nil || version < database.NoVersion {
+			log.Fatalf("Invalid version %q", args[0])
+		}
+		if err := database.ForceMigrationVersion(db, cfg.DBDriver, version); err != nil {
+			log.Fatalf("Force failed: %v", err)
+		}
+		log.Printf("Version set to %d, dirty flag cleared\n", version)
 	case "status":
-		version, dirty, err := database.MigrationStatus(db, "./internal/database/migrations")
+		version, dirty, err := database.MigrationStatus(db, cfg.DBDriver)
 		if err != nil {
 			log.Fatalf("Migration status failed: %v", err)
 		}
@@ -43,3 +124,60 @@ func main() {
 		log.Fatalf("Unknown action: %s", *action)
 	}
 }
+
+// printPlan prints the SQL that migrating to target would run.
+func printPlan(db *database.DB, driver string, target int) {
+	plan, err := database.PendingMigrations(db, driver, target)
+	if err != nil {
+		log.Fatalf("Dry run failed: %v", err)
+	}
+	if len(plan) == 0 {
+		log.Println("Nothing to run")
+		return
+	}
+	for _, m := range plan {
+		fmt.Printf("-- %s\n%s\n", m.Name, strings.TrimRight(m.SQL, "\n"))
+	}
+}
+
+var (
+	migrationFile = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+	unsafeChars   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// create adds the next version's up and down files for every driver. The
+// version follows the highest one in either driver's folder.
+func create(dir, name string) error {
+	name = strings.Trim(unsafeChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return fmt.Errorf("name must contain letters or digits")
+	}
+
+	drivers := []string{config.DriverSQLite, config.DriverPostgres}
+	var next uint64 = 1
+	for _, driver := range drivers {
+		entries, err := os.ReadDir(filepath.Join(dir, driver))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if m := migrationFile.FindStringSubmatch(e.Name()); m != nil {
+				if v, _ := strconv.ParseUint(m[1], 10, 0); v >= next {
+					next = v + 1
+				}
+			}
+		}
+	}
+
+	for _, driver := range drivers {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, driver, fmt.Sprintf("%06d_%s.%s.sql", next, name, direction))
+			body := fmt.Sprintf("-- %s (%s, %s)\n", strings.ReplaceAll(name, "_", " "), driver, direction)
+			if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+				return err
+			}
+			fmt.Println("created", path)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -14,19 +15,21 @@ import (
 func main() {
 
 	cfg := config.LoadConfig()
+	ctx := context.Background()
 
-	// 1️⃣ Connect to SQLite
-	db, err := database.ConnectSQLite(cfg.DBPath)
+	// 1️⃣ Connect to the configured database
+	db, err := database.Connect(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect DB: %v", err)
 	}
 
-	fmt.Println("Using DB at:", cfg.DBPath)
+	fmt.Println("Using DB at:", database.Describe(cfg))
 
 	// 2️⃣ Create repositories
-	farmerRepo := repository.NewFarmerRepository(db)
-	collectorRepo := repository.NewCollectorRepository(db)
-	collectionRepo := repository.NewCollectionRepository(db)
+	stores := repository.NewStores(db, cfg.DBDriver)
+	farmerRepo := stores.Farmers
+	collectorRepo := stores.Collectors
+	collectionRepo := stores.Collections
 
 	// --------------------------
 	// 3️⃣ Test Farmer CRUD
@@ -41,20 +44,20 @@ func main() {
 		Version:      1,
 	}
 
-	if err := farmerRepo.Create(farmer); err != nil {
+	if err := farmerRepo.Create(ctx, farmer); err != nil {
 		log.Fatalf("Create farmer failed: %v", err)
 	}
 	fmt.Println("✅ Farmer created")
 
-	gotFarmer, _ := farmerRepo.GetByID(farmer.ID)
+	gotFarmer, _ := farmerRepo.GetByID(ctx, farmer.ID)
 	fmt.Printf("👀 Got farmer: %+v\n", gotFarmer)
 
 	farmer.Phone = "+254711111111"
 	farmer.UpdatedAt = time.Now()
-	_ = farmerRepo.Update(farmer)
+	_ = farmerRepo.Update(ctx, farmer)
 	fmt.Println("✏️ Farmer updated")
 
-	_ = farmerRepo.Delete(farmer.ID)
+	_ = farmerRepo.Delete(ctx, farmer.ID)
 	fmt.Println("🗑️ Farmer deleted")
 
 	// --------------------------
@@ -70,25 +73,25 @@ func main() {
 		Version:      1,
 	}
 
-	_ = collectorRepo.Create(collector)
+	_ = collectorRepo.Create(ctx, collector)
 	fmt.Println("✅ Collector created")
 
-	gotCollector, _ := collectorRepo.GetByID(collector.ID)
+	gotCollector, _ := collectorRepo.GetByID(ctx, collector.ID)
 	fmt.Printf("👀 Got collector: %+v\n", gotCollector)
 
 	collector.Phone = "+254733333333"
 	collector.UpdatedAt = time.Now()
-	_ = collectorRepo.Update(collector)
+	_ = collectorRepo.Update(ctx, collector)
 	fmt.Println("✏️ Collector updated")
 
-	_ = collectorRepo.Delete(collector.ID)
+	_ = collectorRepo.Delete(ctx, collector.ID)
 	fmt.Println("🗑️ Collector deleted")
 
 	// --------------------------
 	// 5️⃣ Test Collection CRUD
 	// --------------------------
-	_ = farmerRepo.Create(farmer)   // recreate for FK
-	_ = collectorRepo.Create(collector) // recreate for FK
+	_ = farmerRepo.Create(ctx, farmer)   // recreate for FK
+	_ = collectorRepo.Create(ctx, collector) // recreate for FK
 
 	collection := &models.Collection{
 		ID:          "uuid-collection-789",
@@ -102,21 +105,21 @@ func main() {
 		Version:     1,
 	}
 
-	_ = collectionRepo.Create(collection)
+	_ = collectionRepo.Create(ctx, collection)
 	fmt.Println("✅ Collection created")
 
-	gotCollection, _ := collectionRepo.GetByID(collection.ID)
+	gotCollection, _ := collectionRepo.GetByID(ctx, collection.ID)
 	fmt.Printf("👀 Got collection: %+v\n", gotCollection)
 
 	collection.WeightKg = 15
 	collection.UpdatedAt = time.Now()
-	_ = collectionRepo.Update(collection)
+	_ = collectionRepo.Update(ctx, collection)
 	fmt.Println("✏️ Collection updated")
 
-	_ = collectionRepo.Delete(collection.ID)
+	_ = collectionRepo.Delete(ctx, collection.ID)
 	fmt.Println("🗑️ Collection deleted")
 
 	// Cleanup
-	_ = farmerRepo.Delete(farmer.ID)
-	_ = collectorRepo.Delete(collector.ID)
+	_ = farmerRepo.Delete(ctx, farmer.ID)
+	_ = collectorRepo.Delete(ctx, collector.ID)
 }
\ No newline at end of file
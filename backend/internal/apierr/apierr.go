@@ -0,0 +1,111 @@
+// Package apierr is the API's error model. Handlers describe what went
+// wrong as an *Error with a stable Code, and Abort sends it as an RFC 7807
+// problem (application/problem+json) in the client's language. Clients
+// branch on the code, never on the wording.
+package apierr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error is an API error. It is sent with its code's status. The cause, if
+// any, is logged but never sent.
+type Error struct {
+	Code Code
+	// Detail says what went wrong in this case, in English, for
+	// developers. It may change; the code does not.
+	Detail string
+	// Fields lists the request fields that are wrong.
+	Fields []FieldError
+	// Extra holds extension members sent beside the standard ones, such
+	// as the stored record on a version conflict.
+	Extra map[string]any
+
+	cause error
+}
+
+// FieldError is a problem with one request field. Rule says which check
+// failed, such as "required" or "unknown"; Param is the check's argument,
+// such as the 8 of min=8.
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  Rule   `json:"rule"`
+	Param string `json:"param,omitempty"`
+	// Message is set in the client's language when the error is sent.
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// With adds an extension member.
+func (e *Error) With(key string, value any) *Error {
+	if e.Extra == nil {
+		e.Extra = make(map[string]any)
+	}
+	e.Extra[key] = value
+	return e
+}
+
+// New returns an error with code.
+func New(code Code, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Newf is New with a formatted detail.
+func Newf(code Code, format string, args ...any) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// Internal reports a failure that is not the client's fault. detail says
+// what the server was doing, as in "Failed to load farm"; err is logged.
+func Internal(err error, detail string) *Error {
+	e := New(InternalError, detail)
+	e.cause = err
+	return e
+}
+
+// Invalid reports a malformed request field: VALIDATION_FAILED (400).
+func Invalid(field string, rule Rule, detail string) *Error {
+	e := New(ValidationFailed, detail)
+	e.Fields = []FieldError{{Field: field, Rule: rule}}
+	return e
+}
+
+// Unprocessable reports a well-formed request field that does not fit the
+// stored data, such as an unknown center or a closed one: FIELDS_REJECTED
+// (422).
+func Unprocessable(field string, rule Rule, detail string) *Error {
+	e := New(FieldsRejected, detail)
+	e.Fields = []FieldError{{Field: field, Rule: rule}}
+	return e
+}
+
+// From turns a repository error into an API error: a known sentinel, such
+// as repository.ErrCollectionNotFound, becomes its code; anything else is
+// an internal error with detail. An *Error is returned as it is.
+func From(err error, detail string) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if code, ok := sentinelCode(err); ok {
+		e := New(code, "")
+		e.cause = err
+		return e
+	}
+	return Internal(err, detail)
+}
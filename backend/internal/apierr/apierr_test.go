@@ -0,0 +1,161 @@
+package apierr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agri-sync-backend/internal/repository"
+)
+
+func TestFrom(t *testing.T) {
+	passed := New(AlreadyExists, "Unit already exists")
+	tests := []struct {
+		name       string
+		err        error
+		want       Code
+		wantDetail string
+	}{
+		{"sentinel", repository.ErrFarmNotFound, FarmNotFound, ""},
+		{"wrapped sentinel", fmt.Errorf("load plot: %w", repository.ErrPlotNotFound), PlotNotFound, ""},
+		{"conflict", repository.ErrConflict, VersionConflict, ""},
+		{"api error", passed, AlreadyExists, "Unit already exists"},
+		{"wrapped api error", fmt.Errorf("in tx: %w", passed), AlreadyExists, "Unit already exists"},
+		{"anything else", errors.New("disk full"), InternalError, "Failed to load farm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err, "Failed to load farm")
+			if got.Code != tt.want || got.Detail != tt.wantDetail {
+				t.Errorf("From(%v) = %s %q, want %s %q", tt.err, got.Code, got.Detail, tt.want, tt.wantDetail)
+			}
+			if got.Code != AlreadyExists && !errors.Is(got, tt.err) {
+				t.Errorf("From(%v) lost its cause: %v", tt.err, got)
+			}
+		})
+	}
+	if From(passed, "") != passed {
+		t.Error("From did not return an *Error as it is")
+	}
+}
+
+// TestSentinels checks that every repository sentinel maps to a code the
+// API can send, with a title in each language.
+func TestSentinels(t *testing.T) {
+	for _, s := range sentinels {
+		t.Run(s.err.Error(), func(t *testing.T) {
+			code, ok := sentinelCode(fmt.Errorf("wrapped: %w", s.err))
+			if !ok || code != s.code {
+				t.Fatalf("sentinelCode = %s, %v; want %s", code, ok, s.code)
+			}
+			if _, ok := codes[code]; !ok {
+				t.Errorf("%s has no status or title", code)
+			}
+			if status := code.Status(); status < 400 || status == http.StatusInternalServerError {
+				t.Errorf("%s is sent as %d, want a client error", code, status)
+			}
+		})
+	}
+	if code, ok := sentinelCode(errors.New("other")); ok {
+		t.Errorf("sentinelCode(other) = %s, want none", code)
+	}
+}
+
+func TestCodes(t *testing.T) {
+	for code, info := range codes {
+		if info.status < 400 || info.status > 599 {
+			t.Errorf("%s: status %d is not an error", code, info.status)
+		}
+		for _, lang := range []Lang{English, Swahili} {
+			if info.title[lang] == "" {
+				t.Errorf("%s: no %s title", code, lang)
+			}
+		}
+		if info.title[English] == info.title[Swahili] {
+			t.Errorf("%s: Swahili title is the English one", code)
+		}
+	}
+	if got := Code("NO_SUCH_CODE").Status(); got != http.StatusInternalServerError {
+		t.Errorf("unknown code status = %d, want 500", got)
+	}
+	if got := Code("NO_SUCH_CODE").Title(Swahili); got != "" {
+		t.Errorf("unknown code title = %q, want none", got)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Lang
+	}{
+		{"", English},
+		{"en-US", English},
+		{"sw", Swahili},
+		{"sw-KE", Swahili},
+		{"fr", English},
+		{"fr, sw;q=0.5", Swahili},
+		{"en;q=0.2, sw;q=0.9", Swahili},
+		{"sw;q=0.2, en;q=0.9", English},
+		{";;;", English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.accept)
+			if got := Language(r); got != tt.want {
+				t.Errorf("Language(%q) = %s, want %s", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		field FieldError
+		lang  Lang
+		want  string
+	}{
+		{"no param", FieldError{Rule: RuleRequired}, English, "is required"},
+		{"no param in Swahili", FieldError{Rule: RuleRequired}, Swahili, "inahitajika"},
+		{"with param", FieldError{Rule: RuleMinLength, Param: "8"}, English, "must be at least 8 characters"},
+		{"with param in Swahili", FieldError{Rule: RuleMinLength, Param: "8"}, Swahili, "lazima iwe na herufi 8 au zaidi"},
+		{"missing param", FieldError{Rule: RuleGT}, English, "is not valid"},
+		{"validator tag without a message", FieldError{Rule: "uuid"}, Swahili, "si sahihi"},
+		{"unsupported language", FieldError{Rule: RuleUnknown}, Lang("fr"), "does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.field.message(tt.lang); got != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	for rule, msgs := range rules {
+		if msgs[English] == "" || msgs[Swahili] == "" {
+			t.Errorf("rule %s is missing a language", rule)
+		}
+		if strings.Count(msgs[English], "%s") != strings.Count(msgs[Swahili], "%s") {
+			t.Errorf("rule %s: the languages take different params", rule)
+		}
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want string
+	}{
+		{New(Forbidden, ""), "FORBIDDEN"},
+		{New(Forbidden, "Only admins can add clerks"), "FORBIDDEN: Only admins can add clerks"},
+		{Internal(errors.New("disk full"), "Failed to save"), "INTERNAL_ERROR: Failed to save: disk full"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package apierr
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Code identifies what went wrong. Codes are part of the API: once
+// published, one is never renamed or reused for something else.
+type Code string
+
+// Request problems. VALIDATION_FAILED is for fields that are malformed in
+// themselves; FIELDS_REJECTED for well-formed ones the stored data rules
+// out, such as a reference to an unknown center.
+const (
+	MalformedRequest     Code = "MALFORMED_REQUEST"
+	ValidationFailed     Code = "VALIDATION_FAILED"
+	FieldsRejected       Code = "FIELDS_REJECTED"
+	PayloadTooLarge      Code = "PAYLOAD_TOO_LARGE"
+	UnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+)
+
+// Authentication and authorization
+const (
+	Unauthenticated    Code = "UNAUTHENTICATED"
+	InvalidCredentials Code = "INVALID_CREDENTIALS"
+	InvalidSignature   Code = "INVALID_SIGNATURE"
+	Forbidden          Code = "FORBIDDEN"
+)
+
+// Missing records. NotFound is for anything without a code of its own,
+// such as an unknown path.
+const (
+	NotFound             Code = "NOT_FOUND"
+	FarmerNotFound       Code = "FARMER_NOT_FOUND"
+	CollectorNotFound    Code = "COLLECTOR_NOT_FOUND"
+	CollectionNotFound   Code = "COLLECTION_NOT_FOUND"
+	FarmNotFound         Code = "FARM_NOT_FOUND"
+	PlotNotFound         Code = "PLOT_NOT_FOUND"
+	LotNotFound          Code = "LOT_NOT_FOUND"
+	TransferNotFound     Code = "TRANSFER_NOT_FOUND"
+	ScaleNotFound        Code = "SCALE_NOT_FOUND"
+	ReadingNotFound      Code = "SCALE_READING_NOT_FOUND"
+	RouteNotFound        Code = "ROUTE_NOT_FOUND"
+	PriceNotFound        Code = "PRICE_NOT_FOUND"
+	NotificationNotFound Code = "NOTIFICATION_NOT_FOUND"
+	GradeNotFound        Code = "GRADE_NOT_FOUND"
+	CropTypeNotFound     Code = "CROP_TYPE_NOT_FOUND"
+	CenterNotFound       Code = "CENTER_NOT_FOUND"
+	UnitNotFound         Code = "UNIT_NOT_FOUND"
+	DisputeNotFound      Code = "DISPUTE_NOT_FOUND"
+	AttachmentNotFound   Code = "ATTACHMENT_NOT_FOUND"
+	UploadNotFound       Code = "UPLOAD_NOT_FOUND"
+	BackupNotFound       Code = "BACKUP_NOT_FOUND"
+)
+
+// Conflicts with the stored state
+const (
+	// VersionConflict: the client's version is stale. The problem carries
+	// the stored record as "current", for the app's merge screen.
+	VersionConflict      Code = "VERSION_CONFLICT"
+	AlreadyExists        Code = "ALREADY_EXISTS"
+	LotDispatched        Code = "LOT_DISPATCHED"
+	LotInTransit         Code = "LOT_IN_TRANSIT"
+	AlreadyInLot         Code = "ALREADY_IN_LOT"
+	TransferReceived     Code = "TRANSFER_RECEIVED"
+	DisputeOpen          Code = "DISPUTE_OPEN"
+	DisputeResolved      Code = "DISPUTE_RESOLVED"
+	ReadingUsed          Code = "SCALE_READING_USED"
+	StaleSequence        Code = "STALE_SEQUENCE"
+	UploadOffsetMismatch Code = "UPLOAD_OFFSET_MISMATCH"
+	NoUnitConversion     Code = "NO_UNIT_CONVERSION"
+)
+
+// InternalError is a failure on the server's side.
+const InternalError Code = "INTERNAL_ERROR"
+
+type codeInfo struct {
+	status int
+	title  map[Lang]string
+}
+
+var codes = map[Code]codeInfo{
+	MalformedRequest:     {http.StatusBadRequest, texts("The request could not be read", "Ombi halikuweza kusomwa")},
+	ValidationFailed:     {http.StatusBadRequest, texts("Some fields are not valid", "Baadhi ya sehemu si sahihi")},
+	FieldsRejected:       {http.StatusUnprocessableEntity, texts("Some fields cannot be accepted", "Baadhi ya sehemu haziwezi kukubaliwa")},
+	PayloadTooLarge:      {http.StatusRequestEntityTooLarge, texts("The request is too large", "Ombi ni kubwa mno")},
+	UnsupportedMediaType: {http.StatusUnsupportedMediaType, texts("This file type is not allowed", "Aina hii ya faili hairuhusiwi")},
+
+	Unauthenticated:    {http.StatusUnauthorized, texts("Please sign in", "Tafadhali ingia")},
+	InvalidCredentials: {http.StatusUnauthorized, texts("Invalid phone or password", "Namba ya simu au nenosiri si sahihi")},
+	InvalidSignature:   {http.StatusUnauthorized, texts("The signature is not valid", "Sahihi si halali")},
+	Forbidden:          {http.StatusForbidden, texts("You are not allowed to do this", "Huruhusiwi kufanya hili")},
+
+	NotFound:             {http.StatusNotFound, texts("Not found", "Haikupatikana")},
+	FarmerNotFound:       {http.StatusNotFound, texts("Farmer not found", "Mkulima hakupatikana")},
+	CollectorNotFound:    {http.StatusNotFound, texts("Collector not found", "Mkusanyaji hakupatikana")},
+	CollectionNotFound:   {http.StatusNotFound, texts("Collection not found", "Mkusanyiko haukupatikana")},
+	FarmNotFound:         {http.StatusNotFound, texts("Farm not found", "Shamba halikupatikana")},
+	PlotNotFound:         {http.StatusNotFound, texts("Plot not found", "Kipande cha shamba hakikupatikana")},
+	LotNotFound:          {http.StatusNotFound, texts("Lot not found", "Shehena haikupatikana")},
+	TransferNotFound:     {http.StatusNotFound, texts("Transfer not found", "Uhamisho haukupatikana")},
+	ScaleNotFound:        {http.StatusNotFound, texts("Scale not found", "Mizani haikupatikana")},
+	ReadingNotFound:      {http.StatusNotFound, texts("Scale reading not found", "Kipimo cha mizani hakikupatikana")},
+	RouteNotFound:        {http.StatusNotFound, texts("Route not found", "Njia haikupatikana")},
+	PriceNotFound:        {http.StatusNotFound, texts("Price not found", "Bei haikupatikana")},
+	NotificationNotFound: {http.StatusNotFound, texts("Notification not found", "Arifa haikupatikana")},
+	GradeNotFound:        {http.StatusNotFound, texts("Grade not found", "Daraja halikupatikana")},
+	CropTypeNotFound:     {http.StatusNotFound, texts("Crop type not found", "Aina ya zao haikupatikana")},
+	CenterNotFound:       {http.StatusNotFound, texts("Collection center not found", "Kituo cha ukusanyaji hakikupatikana")},
+	UnitNotFound:         {http.StatusNotFound, texts("Unit not found", "Kipimo hakikupatikana")},
+	DisputeNotFound:      {http.StatusNotFound, texts("Dispute not found", "Malalamiko hayakupatikana")},
+	AttachmentNotFound:   {http.StatusNotFound, texts("Attachment not found", "Kiambatisho hakikupatikana")},
+	UploadNotFound:       {http.StatusNotFound, texts("Upload not found", "Upakiaji haukupatikana")},
+	BackupNotFound:       {http.StatusNotFound, texts("Backup not found", "Nakala rudufu haikupatikana")},
+
+	VersionConflict:      {http.StatusConflict, texts("This record was changed by someone else", "Rekodi hii imebadilishwa na mtu mwingine")},
+	AlreadyExists:        {http.StatusConflict, texts("This already exists", "Hii tayari ipo")},
+	LotDispatched:        {http.StatusConflict, texts("The lot has already been dispatched", "Shehena tayari imesafirishwa")},
+	LotInTransit:         {http.StatusConflict, texts("The lot is in transit", "Shehena iko safarini")},
+	AlreadyInLot:         {http.StatusConflict, texts("The collection is already in a lot", "Mkusanyiko tayari uko kwenye shehena")},
+	TransferReceived:     {http.StatusConflict, texts("The transfer has already been received", "Uhamisho tayari umepokelewa")},
+	DisputeOpen:          {http.StatusConflict, texts("This collection already has an open dispute", "Mkusanyiko huu tayari una malalamiko yaliyo wazi")},
+	DisputeResolved:      {http.StatusConflict, texts("The dispute has already been resolved", "Malalamiko tayari yameshughulikiwa")},
+	ReadingUsed:          {http.StatusConflict, texts("The scale reading is already used", "Kipimo cha mizani tayari kimetumika")},
+	StaleSequence:        {http.StatusConflict, texts("The reading sequence was already used", "Mfuatano wa kipimo tayari umetumika")},
+	UploadOffsetMismatch: {http.StatusConflict, texts("The upload is out of step", "Upakiaji haulingani na seva")},
+	NoUnitConversion:     {http.StatusUnprocessableEntity, texts("This unit cannot be converted to kilograms for this crop", "Kipimo hiki hakiwezi kubadilishwa kuwa kilo kwa zao hili")},
+
+	InternalError: {http.StatusInternalServerError, texts("Something went wrong, please try again", "Hitilafu imetokea, tafadhali jaribu tena")},
+}
+
+// Status is the HTTP status the code is sent with.
+func (c Code) Status() int {
+	if info, ok := codes[c]; ok {
+		return info.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Title is the code's summary in lang, for showing to users.
+func (c Code) Title(lang Lang) string {
+	return localize(codes[c].title, lang)
+}
+
+// Rule is the check a request field failed. Besides the rules here, a
+// field may fail any validator tag used in a binding, such as "uuid".
+type Rule string
+
+const (
+	RuleRequired  Rule = "required"
+	RuleMin       Rule = "min"
+	RuleMax       Rule = "max"
+	RuleMinLength Rule = "min_length"
+	RuleMaxLength Rule = "max_length"
+	RuleGT        Rule = "gt"
+	RuleGTE       Rule = "gte"
+	RuleLT        Rule = "lt"
+	RuleLTE       Rule = "lte"
+	RuleOneOf     Rule = "oneof"
+	RuleType      Rule = "type"
+	RuleFormat    Rule = "format"
+	// RuleUnknown: the field names a record that does not exist.
+	RuleUnknown Rule = "unknown"
+	// RuleInactive: the record exists but is closed or retired.
+	RuleInactive Rule = "inactive"
+	// RuleMismatch: the field disagrees with other fields or stored data.
+	RuleMismatch Rule = "mismatch"
+	RuleRange    Rule = "range"
+	RuleInvalid  Rule = "invalid"
+)
+
+// rules are the field messages; %s is the rule's param.
+var rules = map[Rule]map[Lang]string{
+	RuleRequired:  texts("is required", "inahitajika"),
+	RuleMin:       texts("must be at least %s", "lazima iwe angalau %s"),
+	RuleMax:       texts("must be at most %s", "isizidi %s"),
+	RuleMinLength: texts("must be at least %s characters", "lazima iwe na herufi %s au zaidi"),
+	RuleMaxLength: texts("must be at most %s characters", "isizidi herufi %s"),
+	RuleGT:        texts("must be more than %s", "lazima iwe zaidi ya %s"),
+	RuleGTE:       texts("must be %s or more", "lazima iwe %s au zaidi"),
+	RuleLT:        texts("must be less than %s", "lazima iwe chini ya %s"),
+	RuleLTE:       texts("must be %s or less", "lazima iwe %s au chini"),
+	RuleOneOf:     texts("must be one of: %s", "lazima iwe mojawapo ya: %s"),
+	RuleType:      texts("has the wrong type", "ina aina isiyo sahihi"),
+	RuleFormat:    texts("is not in the expected format", "haiko katika muundo unaotarajiwa"),
+	RuleUnknown:   texts("does not exist", "haipo"),
+	RuleInactive:  texts("is no longer in use", "haitumiki tena"),
+	RuleMismatch:  texts("does not match", "hailingani"),
+	RuleRange:     texts("is outside the allowed range", "iko nje ya kiwango kinachoruhusiwa"),
+	RuleInvalid:   texts("is not valid", "si sahihi"),
+}
+
+// message is a field error's message in lang.
+func (f FieldError) message(lang Lang) string {
+	msg := localize(rules[f.Rule], lang)
+	if msg == "" || strings.Contains(msg, "%s") && f.Param == "" {
+		return localize(rules[RuleInvalid], lang)
+	}
+	if strings.Contains(msg, "%s") {
+		return fmt.Sprintf(msg, f.Param)
+	}
+	return msg
+}
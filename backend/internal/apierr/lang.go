@@ -0,0 +1,42 @@
+package apierr
+
+import (
+	"net/http"
+
+	"golang.org/x/text/language"
+)
+
+// Lang is a language problems are written in.
+type Lang string
+
+// Supported languages. English is the fallback.
+const (
+	English Lang = "en"
+	Swahili Lang = "sw"
+)
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Swahili})
+
+// Language picks the language for a request from its Accept-Language.
+func Language(r *http.Request) Lang {
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return English
+	}
+	_, i, _ := matcher.Match(tags...)
+	if i == 1 {
+		return Swahili
+	}
+	return English
+}
+
+func texts(en, sw string) map[Lang]string {
+	return map[Lang]string{English: en, Swahili: sw}
+}
+
+func localize(msgs map[Lang]string, lang Lang) string {
+	if msg, ok := msgs[lang]; ok {
+		return msg
+	}
+	return msgs[English]
+}
@@ -0,0 +1,148 @@
+package apierr
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContentType is the media type of problems (RFC 7807).
+const ContentType = "application/problem+json"
+
+// TypeURI is the problem type of code: the code under a URN, as the
+// codes are not documented at any URL of their own.
+func TypeURI(code Code) string {
+	return "urn:agrisync:problem:" + string(code)
+}
+
+// Abort stops the request with err as a problem in the client's language.
+// An error that is not an *Error is sent as an internal error. The error,
+// with its cause, is added to the request's errors for the request log;
+// the client gets neither the cause nor, for a 5xx, anything but the
+// detail the handler gave.
+func Abort(c *gin.Context, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = Internal(err, "")
+	}
+	c.Error(e)
+
+	lang := Language(c.Request)
+	body := gin.H{}
+	for k, v := range e.Extra {
+		body[k] = v
+	}
+	body["type"] = TypeURI(e.Code)
+	body["title"] = e.Code.Title(lang)
+	body["status"] = e.Code.Status()
+	body["code"] = e.Code
+	body["instance"] = c.Request.URL.Path
+	if e.Detail != "" {
+		body["detail"] = e.Detail
+	}
+	if id := c.GetString("requestId"); id != "" {
+		body["request_id"] = id
+	}
+	if len(e.Fields) > 0 {
+		fields := make([]FieldError, len(e.Fields))
+		for i, f := range e.Fields {
+			f.Message = f.message(lang)
+			fields[i] = f
+		}
+		body["errors"] = fields
+	}
+
+	c.Header("Content-Language", string(lang))
+	c.Header("Vary", "Accept-Language")
+	c.Abort()
+	c.Render(e.Code.Status(), problemRender{body})
+}
+
+// problemRender is gin's JSON render with the problem media type.
+type problemRender struct {
+	body gin.H
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.body)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+}
+
+// BadRequest turns an error from binding a request (c.ShouldBindJSON,
+// c.ShouldBindQuery, ...) into a 400: VALIDATION_FAILED listing each
+// field that failed its binding tags, PAYLOAD_TOO_LARGE for a body over
+// the limit, or MALFORMED_REQUEST for a body that is not JSON at all.
+func BadRequest(err error) *Error {
+	var invalid validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &invalid):
+		e := &Error{Code: ValidationFailed, cause: err}
+		for _, fe := range invalid {
+			e.Fields = append(e.Fields, fieldError(fe))
+		}
+		return e
+	case errors.As(err, &typeErr):
+		e := Invalid(typeErr.Field, RuleType, typeErr.Field+" must be "+typeErr.Type.String())
+		e.cause = err
+		return e
+	case errors.As(err, &tooLarge):
+		return New(PayloadTooLarge, "").With("max_bytes", tooLarge.Limit)
+	case errors.Is(err, io.EOF):
+		return New(MalformedRequest, "Request body is empty")
+	}
+	e := New(MalformedRequest, "Request body is not valid JSON")
+	e.cause = err
+	return e
+}
+
+// fieldError describes a failed binding tag. min, max and len count
+// characters on strings, so those get rules of their own.
+func fieldError(fe validator.FieldError) FieldError {
+	rule := Rule(fe.Tag())
+	if fe.Kind() == reflect.String {
+		switch rule {
+		case RuleMin:
+			rule = RuleMinLength
+		case RuleMax:
+			rule = RuleMaxLength
+		}
+	}
+	field := fe.Namespace()
+	// Drop the request struct's name: "CreateFarmerRequest.phone" -> "phone"
+	if i := strings.IndexByte(field, '.'); i >= 0 {
+		field = field[i+1:]
+	}
+	return FieldError{Field: field, Rule: rule, Param: fe.Param()}
+}
+
+// Binding errors name fields as clients send them, by their json or form
+// tag rather than the Go field name.
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form", "uri"} {
+				name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package apierr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type testPlot struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type testRequest struct {
+	Phone    string     `json:"phone" binding:"required"`
+	Password string     `json:"password" binding:"omitempty,min=8"`
+	Role     string     `json:"role" binding:"omitempty,oneof=farmer collector"`
+	WeightKg float64    `json:"weight_kg" binding:"gte=0"`
+	Plots    []testPlot `json:"plots" binding:"dive"`
+}
+
+// bind binds body as a handler would, with the body capped at limit bytes.
+func bind(t *testing.T, body string, limit int64) error {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Body = http.MaxBytesReader(w, c.Request.Body, limit)
+	var req testRequest
+	return c.ShouldBindJSON(&req)
+}
+
+func TestBadRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		limit  int64
+		code   Code
+		status int
+		fields []FieldError // Message is not compared
+	}{
+		{"missing field", `{}`, 1024, ValidationFailed, 400,
+			[]FieldError{{Field: "phone", Rule: RuleRequired}}},
+		{"string too short", `{"phone":"0711","password":"short"}`, 1024, ValidationFailed, 400,
+			[]FieldError{{Field: "password", Rule: RuleMinLength, Param: "8"}}},
+		{"not one of", `{"phone":"0711","role":"admin"}`, 1024, ValidationFailed, 400,
+			[]FieldError{{Field: "role", Rule: RuleOneOf, Param: "farmer collector"}}},
+		{"number out of range", `{"phone":"0711","weight_kg":-1}`, 1024, ValidationFailed, 400,
+			[]FieldError{{Field: "weight_kg", Rule: RuleGTE, Param: "0"}}},
+		{"nested field", `{"phone":"0711","plots":[{"name":"a"},{}]}`, 1024, ValidationFailed, 400,
+			[]FieldError{{Field: "plots[1].name", Rule: RuleRequired}}},
+		{"every failing field", `{"password":"x","role":"x"}`, 1024, ValidationFailed, 400,
+			[]FieldError{{Field: "phone", Rule: RuleRequired}, {Field: "password", Rule: RuleMinLength, Param: "8"}, {Field: "role", Rule: RuleOneOf, Param: "farmer collector"}}},
+		{"wrong type", `{"phone":"0711","weight_kg":"heavy"}`, 1024, ValidationFailed, 400,
+			[]FieldError{{Field: "weight_kg", Rule: RuleType}}},
+		{"too large", `{"phone":"` + strings.Repeat("7", 64) + `"}`, 16, PayloadTooLarge, 413, nil},
+		{"empty body", ``, 1024, MalformedRequest, 400, nil},
+		{"not JSON", `phone=0711`, 1024, MalformedRequest, 400, nil},
+		{"truncated JSON", `{"phone":`, 1024, MalformedRequest, 400, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bind(t, tt.body, tt.limit)
+			if err == nil {
+				t.Fatal("binding succeeded")
+			}
+			got := BadRequest(err)
+			if got.Code != tt.code || got.Code.Status() != tt.status {
+				t.Fatalf("BadRequest(%v) = %s (%d), want %s (%d)", err, got.Code, got.Code.Status(), tt.code, tt.status)
+			}
+			if len(got.Fields) != len(tt.fields) {
+				t.Fatalf("fields = %+v, want %+v", got.Fields, tt.fields)
+			}
+			for i, f := range got.Fields {
+				if f != tt.fields[i] {
+					t.Errorf("field %d = %+v, want %+v", i, f, tt.fields[i])
+				}
+			}
+		})
+	}
+
+	if got := BadRequest(bind(t, `{"phone":"`+strings.Repeat("7", 64)+`"}`, 16)); got.Extra["max_bytes"] != int64(16) {
+		t.Errorf("too large: max_bytes = %v, want 16", got.Extra["max_bytes"])
+	}
+}
+
+func TestAbort(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		accept string
+		status int
+		want   map[string]any // members of the problem; nil means absent
+	}{
+		{
+			name: "fields in Swahili", accept: "sw",
+			err:    Invalid("phone", RuleRequired, "phone is required"),
+			status: 400,
+			want: map[string]any{
+				"code": "VALIDATION_FAILED", "title": "Baadhi ya sehemu si sahihi", "detail": "phone is required",
+				"type": "urn:agrisync:problem:VALIDATION_FAILED", "instance": "/farmers",
+				"errors": []any{map[string]any{"field": "phone", "rule": "required", "message": "inahitajika"}},
+			},
+		},
+		{
+			name: "too large", accept: "en",
+			err:    New(PayloadTooLarge, "").With("max_bytes", 16),
+			status: 413,
+			want:   map[string]any{"code": "PAYLOAD_TOO_LARGE", "title": "The request is too large", "max_bytes": 16.0, "detail": nil},
+		},
+		{
+			name: "internal error hides its cause", accept: "en",
+			err:    Internal(http.ErrHandlerTimeout, "Failed to save"),
+			status: 500,
+			want:   map[string]any{"code": "INTERNAL_ERROR", "detail": "Failed to save", "errors": nil},
+		},
+		{
+			name: "plain error", accept: "sw",
+			err:    http.ErrHandlerTimeout,
+			status: 500,
+			want:   map[string]any{"code": "INTERNAL_ERROR", "title": "Hitilafu imetokea, tafadhali jaribu tena", "detail": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/farmers", nil)
+			c.Request.Header.Set("Accept-Language", tt.accept)
+			Abort(c, tt.err)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Content-Type"); got != ContentType {
+				t.Errorf("Content-Type = %q, want %q", got, ContentType)
+			}
+			if got := w.Header().Get("Content-Language"); got != tt.accept {
+				t.Errorf("Content-Language = %q, want %q", got, tt.accept)
+			}
+			if !c.IsAborted() || len(c.Errors) != 1 {
+				t.Errorf("aborted %v with %d errors, want aborted with the error kept for the log", c.IsAborted(), len(c.Errors))
+			}
+			if strings.Contains(w.Body.String(), http.ErrHandlerTimeout.Error()) {
+				t.Errorf("body leaks the cause: %s", w.Body)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s: %v", w.Body, err)
+			}
+			if body["status"] != float64(tt.status) {
+				t.Errorf("status member = %v, want %d", body["status"], tt.status)
+			}
+			for key, want := range tt.want {
+				got, ok := body[key]
+				if want == nil {
+					if ok {
+						t.Errorf("%s = %v, want none", key, got)
+					}
+					continue
+				}
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(want)
+				if string(gotJSON) != string(wantJSON) {
+					t.Errorf("%s = %s, want %s", key, gotJSON, wantJSON)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package apierr
+
+import (
+	"errors"
+
+	"agri-sync-backend/internal/backup"
+	"agri-sync-backend/internal/blob"
+	"agri-sync-backend/internal/repository"
+)
+
+// sentinels are the codes for the errors repositories and stores return.
+var sentinels = []struct {
+	err  error
+	code Code
+}{
+	{repository.ErrFarmerNotFound, FarmerNotFound},
+	{repository.ErrCollectorNotFound, CollectorNotFound},
+	{repository.ErrCollectionNotFound, CollectionNotFound},
+	{repository.ErrFarmNotFound, FarmNotFound},
+	{repository.ErrPlotNotFound, PlotNotFound},
+	{repository.ErrLotNotFound, LotNotFound},
+	{repository.ErrTransferNotFound, TransferNotFound},
+	{repository.ErrScaleNotFound, ScaleNotFound},
+	{repository.ErrReadingNotFound, ReadingNotFound},
+	{repository.ErrRouteNotFound, RouteNotFound},
+	{repository.ErrPriceNotFound, PriceNotFound},
+	{repository.ErrNotificationNotFound, NotificationNotFound},
+	{repository.ErrGradeNotFound, GradeNotFound},
+	{repository.ErrCropTypeNotFound, CropTypeNotFound},
+	{repository.ErrCenterNotFound, CenterNotFound},
+	{repository.ErrUnitNotFound, UnitNotFound},
+	{repository.ErrDisputeNotFound, DisputeNotFound},
+	{repository.ErrAttachmentNotFound, AttachmentNotFound},
+	{repository.ErrUploadNotFound, UploadNotFound},
+	{blob.ErrNotFound, AttachmentNotFound},
+	{backup.ErrNoManifest, BackupNotFound},
+
+	{repository.ErrConflict, VersionConflict},
+	{repository.ErrLotSealed, LotDispatched},
+	{repository.ErrLotInTransit, LotInTransit},
+	{repository.ErrAlreadyInLot, AlreadyInLot},
+	{repository.ErrAlreadyReceived, TransferReceived},
+	{repository.ErrDisputeOpen, DisputeOpen},
+	{repository.ErrDisputeClosed, DisputeResolved},
+	{repository.ErrStaleSequence, StaleSequence},
+	{repository.ErrReadingUsed, ReadingUsed},
+	{blob.ErrOffsetMismatch, UploadOffsetMismatch},
+	{repository.ErrNoConversion, NoUnitConversion},
+}
+
+func sentinelCode(err error) (Code, bool) {
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			return s.code, true
+		}
+	}
+	return "", false
+}
@@ -1,27 +1,27 @@
 package auth
 
 import (
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 var (
-	// Default is for LOCAL DEV ONLY; override via AGRISYNC_JWT_SECRET in real deployments.
-	jwtSecret = []byte(getJWTSecret())
+	// Set from jwt_secret and token_ttl by Configure
+	jwtSecret []byte
+	tokenTTL  = 24 * time.Hour
 )
 
-func getJWTSecret() string {
-	if v := os.Getenv("AGRISYNC_JWT_SECRET"); v != "" {
-		return v
-	}
-	return "super-secret-key-change-this-immediately-2026"
+// Configure sets the secret tokens are signed and checked with, and how
+// long new tokens last. It must run before tokens are issued or checked.
+func Configure(secret string, ttl time.Duration) {
+	jwtSecret = []byte(secret)
+	tokenTTL = ttl
 }
 
 type Claims struct {
 	UserID string `json:"userId"`
-	Role   string `json:"role"` // "farmer", "collector", "admin"
+	Role   string `json:"role"` // "farmer", "collector", "admin", "clerk"
 	jwt.RegisteredClaims
 }
 
@@ -30,7 +30,7 @@ func GenerateJWT(userID, role string) (string, error) {
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -1,24 +1,43 @@
 package auth
 
 import (
-	"net/http"
 	"strings"
 
+	"agri-sync-backend/internal/apierr"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+var tracer = otel.Tracer("agri-sync-backend/internal/auth")
+
+// JWTAuthMiddleware admits requests with a valid bearer token and records
+// who made them. The check gets its own span; the handlers after it do not
+// count towards it.
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		_, span := tracer.Start(c.Request.Context(), "JWTAuthMiddleware")
+		fail := func(message string, err error) {
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.SetStatus(codes.Error, message)
+			span.End()
+			apierr.Abort(c, apierr.New(apierr.Unauthenticated, message))
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			fail("Authorization header required", nil)
 			return
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenStr == authHeader {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+			fail("Invalid token format", nil)
 			return
 		}
 
@@ -27,15 +46,17 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			fail("Invalid or expired token", err)
 			return
 		}
 
 		claims, ok := token.Claims.(*Claims)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid claims"})
+			fail("Invalid claims", nil)
 			return
 		}
+		span.SetAttributes(attribute.String("enduser.id", claims.UserID), attribute.String("enduser.role", claims.Role))
+		span.End()
 
 		c.Set("userId", claims.UserID)
 		c.Set("role", claims.Role)
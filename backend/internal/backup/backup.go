@@ -0,0 +1,344 @@
+// Package backup takes online snapshots of the SQLite database, checks
+// them, rotates old ones and restores them.
+//
+// A backup is a VACUUM INTO copy of the database, optionally gzipped and
+// then encrypted, next to a JSON manifest (<backup>.json) with its
+// checksums and schema version.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/database"
+)
+
+// Options say where backups go and how they are written.
+type Options struct {
+	Dir      string
+	Compress bool
+	Key      []byte // encrypts when set; see LoadKey
+	Policy   Policy // applied after each backup
+}
+
+// OptionsFrom builds Options from the AGRISYNC_BACKUP_* settings, reading
+// the key file if one is set.
+func OptionsFrom(cfg *config.Config) (Options, error) {
+	opts := Options{
+		Dir:      cfg.BackupDir,
+		Compress: cfg.BackupCompress,
+		Policy:   Policy{Last: cfg.BackupKeepLast, Daily: cfg.BackupKeepDaily, Weekly: cfg.BackupKeepWeekly},
+	}
+	if cfg.BackupKeyFile != "" {
+		key, err := LoadKey(cfg.BackupKeyFile)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Key = key
+	}
+	return opts, nil
+}
+
+// Manifest describes a backup file. It is stored next to it as
+// <file>.json.
+type Manifest struct {
+	File          string    `json:"file"`
+	CreatedAt     time.Time `json:"created_at"`
+	SchemaVersion uint      `json:"schema_version"`
+	Size          int64     `json:"size"`
+	SHA256        string    `json:"sha256"`    // of the backup file
+	DBSHA256      string    `json:"db_sha256"` // of the database inside it
+	Compressed    bool      `json:"compressed"`
+	Encrypted     bool      `json:"encrypted"`
+}
+
+const (
+	filePrefix   = "agrisync-"
+	nameLayout   = "20060102T150405.000Z"
+	manifestExt  = ".json"
+	tempPrefix   = ".tmp-"
+	integrityOK  = "ok"
+	versionTable = "schema_migrations"
+)
+
+var ErrNoManifest = errors.New("backup has no manifest")
+
+// createMu lets one backup run at a time in this process.
+var createMu sync.Mutex
+
+// Create snapshots db into opts.Dir, checks the snapshot, writes the
+// backup and its manifest, and prunes old backups under opts.Policy. The
+// database stays online throughout.
+func Create(ctx context.Context, db *database.DB, opts Options) (*Manifest, error) {
+	createMu.Lock()
+	defer createMu.Unlock()
+
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create backup dir: %w", err)
+	}
+
+	now := time.Now().UTC()
+	snapshot := filepath.Join(opts.Dir, tempPrefix+now.Format(nameLayout)+".db")
+	defer os.Remove(snapshot)
+	if err := db.VacuumInto(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("snapshot failed: %w", err)
+	}
+	version, err := checkDatabase(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot failed its check: %w", err)
+	}
+
+	name := filePrefix + now.Format(nameLayout) + ".db"
+	if opts.Compress {
+		name += ".gz"
+	}
+	if opts.Key != nil {
+		name += ".enc"
+	}
+	m := &Manifest{
+		File:          name,
+		CreatedAt:     now,
+		SchemaVersion: version,
+		Compressed:    opts.Compress,
+		Encrypted:     opts.Key != nil,
+	}
+	if err := m.write(opts.Dir, snapshot, opts.Key); err != nil {
+		return nil, err
+	}
+
+	if _, err := Prune(opts.Dir, opts.Policy, now); err != nil {
+		return m, fmt.Errorf("backup written, but pruning failed: %w", err)
+	}
+	return m, nil
+}
+
+// write encodes snapshot into the backup file and saves the manifest. The
+// backup appears under its name only once complete.
+func (m *Manifest) write(dir, snapshot string, key []byte) error {
+	in, err := os.Open(snapshot)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(dir, tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	fileSum, dbSum := sha256.New(), sha256.New()
+	var w io.Writer = io.MultiWriter(tmp, fileSum)
+	var closers []io.Closer
+	if key != nil {
+		ew, err := newEncryptWriter(w, key)
+		if err != nil {
+			return err
+		}
+		w, closers = ew, append(closers, ew)
+	}
+	if m.Compressed {
+		gz := gzip.NewWriter(w)
+		w, closers = gz, append(closers, gz)
+	}
+	if _, err := io.Copy(io.MultiWriter(w, dbSum), in); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].Close(); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		return err
+	}
+	m.Size = info.Size()
+	m.SHA256 = sum(fileSum)
+	m.DBSHA256 = sum(dbSum)
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, m.File)); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, m.File+manifestExt), raw, 0o600)
+}
+
+// List returns the backups in dir that have a manifest, newest first.
+func List(dir string) ([]*Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*Manifest
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, manifestExt) {
+			continue
+		}
+		m, err := readManifest(filepath.Join(dir, strings.TrimSuffix(name, manifestExt)))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+func readManifest(path string) (*Manifest, error) {
+	raw, err := os.ReadFile(path + manifestExt)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoManifest
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path+manifestExt, err)
+	}
+	return &m, nil
+}
+
+// Verify checks the backup at path against its manifest: file checksum,
+// decryption, decompression, database checksum, SQLite's integrity check
+// and schema version. key is needed for encrypted backups.
+func Verify(path string, key []byte) (*Manifest, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), tempPrefix+"*.db")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	return decode(path, tmp.Name(), key)
+}
+
+// decode checks the backup at path and writes the database in it to dst.
+func decode(path, dst string, key []byte) (*Manifest, error) {
+	m, err := readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if m.Encrypted && key == nil {
+		return nil, errors.New("backup is encrypted; a key is needed")
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	fileSum, dbSum := sha256.New(), sha256.New()
+	var r io.Reader = io.TeeReader(in, fileSum)
+	if m.Encrypted {
+		if r, err = newDecryptReader(r, key); err != nil {
+			return nil, err
+		}
+	}
+	if m.Compressed {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("backup does not decompress: %w", err)
+		}
+		r = gz
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	if _, err := io.Copy(io.MultiWriter(out, dbSum), r); err != nil {
+		return nil, fmt.Errorf("backup does not decode: %w", err)
+	}
+	// Drain whatever the decoders did not read, so the file checksum
+	// covers every byte.
+	if _, err := io.Copy(io.Discard, io.TeeReader(in, fileSum)); err != nil {
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+
+	if got := sum(fileSum); got != m.SHA256 {
+		return nil, fmt.Errorf("backup checksum is %s, manifest says %s", got, m.SHA256)
+	}
+	if got := sum(dbSum); got != m.DBSHA256 {
+		return nil, fmt.Errorf("database checksum is %s, manifest says %s", got, m.DBSHA256)
+	}
+	version, err := checkDatabase(dst)
+	if err != nil {
+		return nil, err
+	}
+	if version != m.SchemaVersion {
+		return nil, fmt.Errorf("database is at schema version %d, manifest says %d", version, m.SchemaVersion)
+	}
+	return m, nil
+}
+
+// checkDatabase runs SQLite's integrity check on the database file at path
+// and returns its schema version. A dirty schema is an error.
+func checkDatabase(path string) (uint, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return 0, fmt.Errorf("integrity check failed: %w", err)
+	}
+	if result != integrityOK {
+		return 0, fmt.Errorf("integrity check failed: %s", result)
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+	err = db.QueryRow("SELECT version, dirty FROM "+versionTable).Scan(&version, &dirty)
+	if err != nil {
+		return 0, fmt.Errorf("no schema version: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("schema is dirty at version %d", version)
+	}
+	if version < 0 {
+		return 0, errors.New("no migrations have run")
+	}
+	return uint(version), nil
+}
+
+func sum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
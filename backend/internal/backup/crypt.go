@@ -0,0 +1,210 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Encrypted backups are AES-256-GCM in chunks, so a backup of any size is
+// sealed and checked without holding it in memory:
+//
+//	magic "AGSBAK01" | 12-byte nonce prefix | chunks
+//	chunk: 4-byte big-endian length | sealed bytes
+//
+// Chunk i is sealed with the nonce prefix XOR i and, as additional data, a
+// flag marking the last chunk, so reordered, dropped or truncated chunks
+// fail to open.
+const (
+	cryptMagic = "AGSBAK01"
+	chunkSize  = 64 << 10
+)
+
+var ErrBadKey = errors.New("backup key must be 32 bytes, raw or as 64 hex characters")
+
+// LoadKey reads a 32-byte key from path, raw or hex encoded.
+// Make one with: head -c 32 /dev/urandom > backup.key
+func LoadKey(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup key: %w", err)
+	}
+	if len(raw) == 32 {
+		return raw, nil
+	}
+	if key, err := hex.DecodeString(strings.TrimSpace(string(raw))); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, ErrBadKey
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, ErrBadKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, i uint64) []byte {
+	nonce := append([]byte(nil), prefix...)
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], i)
+	for j := range ctr {
+		nonce[len(nonce)-8+j] ^= ctr[j]
+	}
+	return nonce
+}
+
+func lastFlag(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// encryptWriter seals what is written to it onto w. Close seals the last
+// chunk; without it the output does not open.
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	n      uint64
+	buf    []byte
+}
+
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, cryptMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, prefix: prefix, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full buffer is only sealed once more data arrives, so that the
+		// last chunk is always sealed by Close with the last flag set.
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):chunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.prefix, e.n), e.buf, lastFlag(last))
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(sealed)))
+	if _, err := e.w.Write(size[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.n++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// decryptReader opens what an encryptWriter sealed. It returns an error,
+// never io.EOF, if the input ends before the last chunk.
+type decryptReader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	n      uint64
+	plain  []byte
+	done   bool
+}
+
+func newDecryptReader(r io.Reader, key []byte) (*decryptReader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(r)
+	header := make([]byte, len(cryptMagic)+aead.NonceSize())
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(cryptMagic)]) != cryptMagic {
+		return nil, errors.New("not an encrypted backup")
+	}
+	return &decryptReader{r: br, aead: aead, prefix: header[len(cryptMagic):]}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+func (d *decryptReader) open() error {
+	var size [4]byte
+	if _, err := io.ReadFull(d.r, size[:]); err != nil {
+		return errors.New("encrypted backup is truncated")
+	}
+	// The length is checked before allocating: a corrupt file could ask
+	// for up to 4 GiB
+	n := binary.BigEndian.Uint32(size[:])
+	if n > uint32(chunkSize+d.aead.Overhead()) {
+		return errors.New("encrypted backup is corrupt")
+	}
+	sealed := make([]byte, n)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return errors.New("encrypted backup is truncated")
+	}
+
+	nonce := chunkNonce(d.prefix, d.n)
+	plain, err := d.aead.Open(nil, nonce, sealed, lastFlag(false))
+	if err != nil {
+		if plain, err = d.aead.Open(nil, nonce, sealed, lastFlag(true)); err != nil {
+			return errors.New("encrypted backup does not open: wrong key or corrupt file")
+		}
+		if _, err := d.r.Peek(1); err != io.EOF {
+			return errors.New("encrypted backup has data after its last chunk")
+		}
+		d.done = true
+	}
+	d.n++
+	d.plain = plain
+	return nil
+}
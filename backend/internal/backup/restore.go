@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/database"
+)
+
+// Restored reports what Restore did.
+type Restored struct {
+	Manifest *Manifest
+	// Previous is where the database that was replaced now is, or "" if
+	// there was none.
+	Previous string
+	// Pending is how many migrations the server will apply to the
+	// restored database when it starts.
+	Pending uint
+}
+
+// Restore replaces the SQLite database at dbPath with the backup at path.
+// The backup is verified and its schema version checked against the
+// migrations in this binary before anything is touched. The replaced
+// database, with its WAL, is kept next to it as <dbPath>.pre-restore-<time>.
+//
+// The server must be stopped: connections left open on the old file would
+// keep using it.
+func Restore(path, dbPath string, key []byte) (*Restored, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(dbPath), filepath.Base(dbPath)+tempPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	m, err := decode(path, tmp.Name(), key)
+	if err != nil {
+		return nil, fmt.Errorf("backup failed verification: %w", err)
+	}
+	latest, err := database.LatestVersion(config.DriverSQLite)
+	if err != nil {
+		return nil, err
+	}
+	if m.SchemaVersion > latest {
+		return nil, fmt.Errorf("backup is at schema version %d, newer than this build's %d; restore it with a newer build",
+			m.SchemaVersion, latest)
+	}
+
+	restored := &Restored{Manifest: m, Pending: latest - m.SchemaVersion}
+	// moved lists the files moved aside so far, to put back on failure
+	var moved []string
+	putBack := func() {
+		for _, suffix := range moved {
+			os.Rename(restored.Previous+suffix, dbPath+suffix)
+		}
+	}
+	if _, err := os.Stat(dbPath); err == nil {
+		restored.Previous = dbPath + ".pre-restore-" + time.Now().UTC().Format(nameLayout)
+		// -wal and -shm travel with the database, so the pair stays readable.
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			err := os.Rename(dbPath+suffix, restored.Previous+suffix)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				putBack()
+				return nil, fmt.Errorf("failed to move the current database aside: %w", err)
+			}
+			moved = append(moved, suffix)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), dbPath); err != nil {
+		putBack()
+		return nil, fmt.Errorf("failed to put the restored database in place: %w", err)
+	}
+	return restored, nil
+}
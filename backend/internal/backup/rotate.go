@@ -0,0 +1,72 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Policy says which backups Prune keeps: the Last newest, plus the newest
+// of each of the Daily most recent days and of the Weekly most recent ISO
+// weeks that have a backup. Days and weeks are in UTC. The zero Policy
+// keeps everything.
+type Policy struct {
+	Last   int
+	Daily  int
+	Weekly int
+}
+
+func (p Policy) keepsAll() bool {
+	return p.Last <= 0 && p.Daily <= 0 && p.Weekly <= 0
+}
+
+// Prune deletes the backups in dir that p does not keep, with their
+// manifests, and returns the deleted file names. Backups newer than now
+// are always kept.
+func Prune(dir string, p Policy, now time.Time) ([]string, error) {
+	if p.keepsAll() {
+		return nil, nil
+	}
+	list, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool)
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	for i, m := range list { // newest first
+		if i < p.Last || m.CreatedAt.After(now) {
+			keep[m.File] = true
+		}
+		day := m.CreatedAt.UTC().Format(time.DateOnly)
+		if !days[day] && len(days) < p.Daily {
+			days[day] = true
+			keep[m.File] = true
+		}
+		year, week := m.CreatedAt.UTC().ISOWeek()
+		wk := fmt.Sprintf("%d-W%02d", year, week)
+		if !weeks[wk] && len(weeks) < p.Weekly {
+			weeks[wk] = true
+			keep[m.File] = true
+		}
+	}
+
+	var deleted []string
+	for _, m := range list {
+		if keep[m.File] {
+			continue
+		}
+		path := filepath.Join(dir, m.File)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return deleted, err
+		}
+		if err := os.Remove(path + manifestExt); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return deleted, err
+		}
+		deleted = append(deleted, m.File)
+	}
+	return deleted, nil
+}
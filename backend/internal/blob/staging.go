@@ -0,0 +1,148 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var ErrOffsetMismatch = errors.New("upload offset mismatch")
+
+// Staging holds partially received uploads on local disk until they are
+// complete, so a client on a bad connection can resume where it stopped.
+type Staging struct {
+	dir string
+
+	mu    sync.Mutex
+	locks map[string]*uploadLock
+}
+
+// uploadLock serializes the appends to one upload.
+type uploadLock struct {
+	sync.Mutex
+	refs int
+}
+
+func NewStaging(dir string) (*Staging, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create upload dir: %w", err)
+	}
+	return &Staging{dir: dir, locks: make(map[string]*uploadLock)}, nil
+}
+
+// lock takes the upload's lock and returns the func that releases it.
+func (s *Staging) lock(id string) func() {
+	s.mu.Lock()
+	l := s.locks[id]
+	if l == nil {
+		l = &uploadLock{}
+		s.locks[id] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.locks, id)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Staging) path(id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("invalid upload id %q", id)
+	}
+	return filepath.Join(s.dir, id), nil
+}
+
+// Create starts an empty upload.
+func (s *Staging) Create(id string) error {
+	p, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Offset returns how many bytes of the upload have been received.
+func (s *Staging) Offset(id string) (int64, error) {
+	p, err := s.path(id)
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// Append writes at most limit bytes from r to the end of the upload, which
+// must currently be offset bytes long. It returns the new offset. A chunk
+// cut off by a dropped connection is kept, so the client resumes from
+// whatever arrived. Concurrent appends to one upload run one at a time, so
+// of two sent at the same offset only the first is written.
+func (s *Staging) Append(id string, offset int64, r io.Reader, limit int64) (int64, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	current, err := s.Offset(id)
+	if err != nil {
+		return 0, err
+	}
+	if current != offset {
+		return current, ErrOffsetMismatch
+	}
+
+	p, _ := s.path(id)
+	f, err := os.OpenFile(p, os.O_WRONLY, 0o644)
+	if err != nil {
+		return current, err
+	}
+	// Written at the offset checked, not wherever the file ends by now
+	n, copyErr := io.Copy(io.NewOffsetWriter(f, offset), io.LimitReader(r, limit))
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	return current + n, copyErr
+}
+
+// Open returns the staged bytes for reading.
+func (s *Staging) Open(id string) (*os.File, error) {
+	p, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *Staging) Remove(id string) error {
+	p, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// Package buildinfo describes the running build. Release builds set it
+// with the linker, as the Makefile's build target does:
+//
+//	go build -ldflags "-X agri-sync-backend/internal/buildinfo.Version=v1.4.0
+//	  -X agri-sync-backend/internal/buildinfo.Commit=$(git rev-parse HEAD)
+//	  -X agri-sync-backend/internal/buildinfo.BuildTime=$(date -u +%FT%TZ)"
+//
+// Without them, Commit comes from the version control stamp that go build
+// records, when there is one.
+package buildinfo
+
+import "runtime/debug"
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+func init() {
+	if Commit != "" {
+		return
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision != "" && modified == "true" {
+		revision += "-dirty"
+	}
+	Commit = revision
+}
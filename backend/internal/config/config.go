@@ -1,23 +1,222 @@
+// Package config holds the server's settings. Each one is read, in order
+// of precedence, from:
+//
+//  1. the environment, as AGRISYNC_<KEY> (for example AGRISYNC_DB_PATH)
+//  2. a YAML or TOML file: AGRISYNC_CONFIG, or else ./agrisync.yaml,
+//     ./agrisync.yml or ./agrisync.toml if present, with flat <key>: value
+//     entries (for example db_path: ./data/agrisync.db)
+//  3. the defaults below
+//
+// Secrets can also be read from a file named by <key>_file, or
+// AGRISYNC_<KEY>_FILE. The loaded settings are validated as a whole and
+// every problem is reported at once.
 package config
 
 import (
 	"log"
-	"os"
+	"runtime"
+	"time"
 )
 
+// Database drivers selectable with AGRISYNC_DB_DRIVER
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Trace exporters selectable with AGRISYNC_TRACE_EXPORTER
+const (
+	TraceExporterNone   = "none"
+	TraceExporterStdout = "stdout"
+	TraceExporterOTLP   = "otlp"
+)
+
+// DevJWTSecret is the signing secret used when jwt_secret is not set and
+// dev_mode is on. It is public, so for LOCAL DEV ONLY.
+const DevJWTSecret = "super-secret-key-change-this-immediately-2026"
+
+// Config fields are tagged with their key. Keys tagged secret can be read
+// from a file and are hidden by config print --redacted.
 type Config struct {
-	DBPath string
+	// HTTP: the address the API listens on and the browser origins
+	// allowed to call it with credentials.
+	ListenAddr  string   `key:"listen_addr"`
+	CORSOrigins []string `key:"cors_origins"`
+
+	// HTTP server limits. A zero timeout means none.
+	ReadHeaderTimeout time.Duration `key:"read_header_timeout"`
+	ReadTimeout       time.Duration `key:"read_timeout"`
+	WriteTimeout      time.Duration `key:"write_timeout"`
+	IdleTimeout       time.Duration `key:"idle_timeout"`
+	MaxHeaderBytes    int           `key:"max_header_bytes"`
+	// MaxBodyBytes caps request bodies, except file uploads, which are
+	// capped by MaxUploadBytes.
+	MaxBodyBytes int64 `key:"max_body_bytes"`
+
+	// TLS is served when both files are set.
+	TLSCertFile string `key:"tls_cert_file"`
+	TLSKeyFile  string `key:"tls_key_file"`
+
+	// Shutdown: how long /readyz reports 503 before the server stops
+	// accepting connections, then how long in-flight requests and
+	// background workers get to finish.
+	ShutdownDelay   time.Duration `key:"shutdown_delay"`
+	ShutdownTimeout time.Duration `key:"shutdown_timeout"`
+
+	// Health checks: how long one check may take before it counts as
+	// failed, and the free space the SQLite file's disk must keep.
+	HealthCheckTimeout time.Duration `key:"health_check_timeout"`
+	HealthMinFreeBytes int64         `key:"health_min_free_bytes"`
+
+	// Logging: "text" or "json", and the lowest level written ("debug",
+	// "info", "warn" or "error").
+	LogFormat string `key:"log_format"`
+	LogLevel  string `key:"log_level"`
+
+	// MetricsToken, when set, is the bearer token /metrics requires.
+	MetricsToken string `key:"metrics_token" secret:"true"`
+
+	// Tracing: where spans go ("none", "stdout" or "otlp"), the OTLP/HTTP
+	// collector URL (empty: the standard OTEL_EXPORTER_OTLP_* variables,
+	// else http://localhost:4318), and the share of new traces kept.
+	TraceExporter     string  `key:"trace_exporter"`
+	TraceOTLPEndpoint string  `key:"trace_otlp_endpoint"`
+	TraceSampleRatio  float64 `key:"trace_sample_ratio"`
+
+	// DevMode allows what is unsafe outside local development: running
+	// without jwt_secret, on DevJWTSecret.
+	DevMode bool `key:"dev_mode"`
+
+	// Auth: the secret tokens are signed with and how long they last.
+	JWTSecret string        `key:"jwt_secret" secret:"true"`
+	TokenTTL  time.Duration `key:"token_ttl"`
+
+	// Currency is the ISO 4217 code that amounts are reported in.
+	Currency string `key:"currency"`
+
+	// DBDriver is "sqlite" (the default, a file at DBPath) or "postgres"
+	// (a server at DatabaseURL).
+	DBDriver    string `key:"db_driver"`
+	DBPath      string `key:"db_path"`
+	DatabaseURL string `key:"database_url" secret:"true"`
+
+	// SQLite tuning: PRAGMA synchronous (OFF, NORMAL, FULL or EXTRA), how
+	// long a connection waits on a lock, and the size of the read pool.
+	SQLiteSynchronous string        `key:"sqlite_synchronous"`
+	SQLiteBusyTimeout time.Duration `key:"sqlite_busy_timeout"`
+	SQLiteReadConns   int           `key:"sqlite_read_conns"`
+
+	// DBQueryTimeout bounds each repository call; 0 leaves only the
+	// request's own deadline.
+	DBQueryTimeout time.Duration `key:"db_query_timeout"`
+
+	// PriceTolerance is the fraction a manually entered price_per_kg may
+	// deviate from the catalog price before the collection is rejected.
+	PriceTolerance float64 `key:"price_tolerance"`
+
+	// GeofenceRadiusM is how far, in metres, a geotagged delivery may be from
+	// the farm or center it is recorded against before it is flagged.
+	GeofenceRadiusM float64 `key:"geofence_radius_m"`
+
+	// GeofenceMaxAccuracyM is the worst reported GPS accuracy, in metres,
+	// that widens the geofence. A fix reported as less accurate is flagged,
+	// and only this much is added to the radius.
+	GeofenceMaxAccuracyM float64 `key:"geofence_max_accuracy_m"`
+
+	// ScaleToleranceKg is how far a collection's weight may differ from the
+	// scale reading it references before it is flagged.
+	ScaleToleranceKg float64 `key:"scale_tolerance_kg"`
+
+	// Attachments: where blobs and in-progress uploads are kept, and the
+	// largest file accepted.
+	BlobDir        string `key:"blob_dir"`
+	UploadDir      string `key:"upload_dir"`
+	MaxUploadBytes int64  `key:"max_upload_bytes"`
+
+	// Backups: how often the server takes one (0: never), where they are
+	// written, whether they are gzipped, the optional encryption key file,
+	// and how many are kept (the newest BackupKeepLast, plus one a day for
+	// BackupKeepDaily days and one a week for BackupKeepWeekly weeks).
+	BackupInterval   time.Duration `key:"backup_interval"`
+	BackupDir        string        `key:"backup_dir"`
+	BackupCompress   bool          `key:"backup_compress"`
+	BackupKeyFile    string        `key:"backup_key_file"`
+	BackupKeepLast   int           `key:"backup_keep_last"`
+	BackupKeepDaily  int           `key:"backup_keep_daily"`
+	BackupKeepWeekly int           `key:"backup_keep_weekly"`
+
+	// sources says where each key's value came from.
+	sources map[string]string
 }
 
-// LoadConfig reads environment variables or sets defaults
+// Defaults returns the settings used when nothing overrides them.
+func Defaults() *Config {
+	return &Config{
+		ListenAddr:  ":8080",
+		CORSOrigins: []string{"http://localhost:5173"},
+
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       2 * time.Minute,
+		WriteTimeout:      2 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    1 << 20,
+		MaxBodyBytes:      1 << 20,
+		ShutdownDelay:     5 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+
+		HealthCheckTimeout: 2 * time.Second,
+		HealthMinFreeBytes: 256 << 20,
+
+		LogFormat: "text",
+		LogLevel:  "info",
+
+		TraceExporter:    TraceExporterNone,
+		TraceSampleRatio: 1,
+
+		TokenTTL: 24 * time.Hour,
+
+		Currency: "USD",
+
+		DBDriver: DriverSQLite,
+		DBPath:   "./data/agrisync.db",
+
+		SQLiteSynchronous: "NORMAL",
+		SQLiteBusyTimeout: 5 * time.Second,
+		SQLiteReadConns:   max(runtime.NumCPU(), 4),
+		DBQueryTimeout:    5 * time.Second,
+
+		PriceTolerance:       0.10,
+		GeofenceRadiusM:      500,
+		GeofenceMaxAccuracyM: 100,
+		ScaleToleranceKg:     0.1,
+
+		BlobDir:        "./data/blobs",
+		UploadDir:      "./data/uploads",
+		MaxUploadBytes: 10 << 20,
+
+		BackupDir:        "./data/backups",
+		BackupCompress:   true,
+		BackupKeepLast:   7,
+		BackupKeepDaily:  7,
+		BackupKeepWeekly: 4,
+	}
+}
+
+// LoadConfig loads the settings and exits, listing every problem, if they
+// are invalid.
 func LoadConfig() *Config {
-	dbPath := os.Getenv("AGRISYNC_DB_PATH")
-	if dbPath == "" {
-		log.Println("⚠️ AGRISYNC_DB_PATH not set, using default ./data/agrisync.db")
-		dbPath = "./data/agrisync.db"
+	cfg, err := Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
+	return cfg
+}
 
-	return &Config{
-		DBPath: dbPath,
+// Source says where the value of key came from: "default", the config
+// file's path, or an environment variable.
+func (c *Config) Source(key string) string {
+	if src, ok := c.sources[key]; ok {
+		return src
 	}
+	return "default"
 }
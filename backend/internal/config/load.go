@@ -0,0 +1,296 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	envPrefix  = "AGRISYNC_"
+	fileSuffix = "_file"
+	// ConfigFileEnv names the config file to load.
+	ConfigFileEnv = envPrefix + "CONFIG"
+)
+
+// defaultFiles are tried, in order, when ConfigFileEnv is unset.
+var defaultFiles = []string{"agrisync.yaml", "agrisync.yml", "agrisync.toml"}
+
+// field is one setting of Config.
+type field struct {
+	key    string
+	secret bool
+	value  reflect.Value
+}
+
+func (c *Config) fields() []field {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("key")
+		if key == "" {
+			continue
+		}
+		fields = append(fields, field{
+			key:    key,
+			secret: t.Field(i).Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+	return fields
+}
+
+// Load reads the settings from the defaults, the config file and the
+// environment, and validates them. On error the returned Config still
+// holds what was read, for config print.
+func Load() (*Config, error) {
+	cfg := Defaults()
+	cfg.sources = make(map[string]string)
+
+	var problems []error
+	path, err := configFile()
+	if err != nil {
+		problems = append(problems, err)
+	}
+	if path != "" {
+		problems = append(problems, cfg.loadFile(path)...)
+	}
+	problems = append(problems, cfg.loadEnv()...)
+	if cfg.JWTSecret == "" && cfg.DevMode {
+		cfg.JWTSecret = DevJWTSecret
+		cfg.sources["jwt_secret"] = "dev_mode"
+	}
+	problems = append(problems, cfg.validate()...)
+	return cfg, errors.Join(problems...)
+}
+
+// configFile returns the config file to load, or "" if there is none.
+func configFile() (string, error) {
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("%s: %w", ConfigFileEnv, err)
+		}
+		return path, nil
+	}
+	for _, name := range defaultFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *Config) loadFile(path string) []error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return []error{err}
+	}
+	values := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(raw, &values)
+	case ".toml":
+		err = toml.Unmarshal(raw, &values)
+	default:
+		err = errors.New("config file must end in .yaml, .yml or .toml")
+	}
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", path, err)}
+	}
+
+	var problems []error
+	known := make(map[string]bool)
+	for _, f := range c.fields() {
+		known[f.key] = true
+		if f.secret {
+			known[f.key+fileSuffix] = true
+		}
+		raw, hasValue := values[f.key]
+		file, hasFile := values[f.key+fileSuffix]
+		if hasValue && hasFile && f.secret {
+			problems = append(problems, fmt.Errorf("%s: set %s or %s, not both", path, f.key, f.key+fileSuffix))
+			continue
+		}
+		if hasFile && f.secret {
+			name, err := scalar(file)
+			if err == nil {
+				raw, err = readSecret(name)
+			}
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s: %s: %w", path, f.key+fileSuffix, err))
+				continue
+			}
+			hasValue = true
+		}
+		if !hasValue {
+			continue
+		}
+		if err := set(f.value, raw); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %s: %w", path, f.key, err))
+			continue
+		}
+		c.sources[f.key] = path
+	}
+	for key := range values {
+		if !known[key] {
+			problems = append(problems, fmt.Errorf("%s: unknown setting %q", path, key))
+		}
+	}
+	return problems
+}
+
+func (c *Config) loadEnv() []error {
+	var problems []error
+	for _, f := range c.fields() {
+		name := EnvName(f.key)
+		fileName := name + strings.ToUpper(fileSuffix)
+		source := name
+		v := os.Getenv(name)
+		if file := os.Getenv(fileName); file != "" && f.secret {
+			if v != "" {
+				problems = append(problems, fmt.Errorf("set %s or %s, not both", name, fileName))
+				continue
+			}
+			secret, err := readSecret(file)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", fileName, err))
+				continue
+			}
+			v, source = secret, fileName
+		}
+		if v == "" {
+			continue
+		}
+		if err := set(f.value, v); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		c.sources[f.key] = source
+	}
+	return problems
+}
+
+// EnvName is the environment variable that sets key.
+func EnvName(key string) string {
+	return envPrefix + strings.ToUpper(key)
+}
+
+// readSecret reads a secret from a file, without its trailing newline.
+func readSecret(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimRight(string(raw), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return secret, nil
+}
+
+// set parses raw, a string from the environment or a value from the
+// config file, into v.
+func set(v reflect.Value, raw any) error {
+	if list, ok := v.Addr().Interface().(*[]string); ok {
+		items, err := stringList(raw)
+		if err != nil {
+			return err
+		}
+		*list = items
+		return nil
+	}
+
+	s, err := scalar(raw)
+	if err != nil {
+		return err
+	}
+	switch p := v.Addr().Interface().(type) {
+	case *string:
+		*p = s
+	case *bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", s)
+		}
+		*p = b
+	case *int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", s)
+		}
+		*p = n
+	case *int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", s)
+		}
+		*p = n
+	case *float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		*p = f
+	case *time.Duration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 30s or 24h", s)
+		}
+		*p = d
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// scalar turns a single value from the config file into a string.
+func scalar(raw any) (string, error) {
+	switch v := raw.(type) {
+	case string:
+		return strings.TrimSpace(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int64, uint64:
+		return fmt.Sprint(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("must be a single value, got %v", raw)
+	}
+}
+
+// stringList turns a list from the config file, or a comma separated
+// string, into a list of strings.
+func stringList(raw any) ([]string, error) {
+	var items []string
+	switch v := raw.(type) {
+	case string:
+		items = strings.Split(v, ",")
+	case []any:
+		for _, item := range v {
+			s, err := scalar(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, s)
+		}
+	default:
+		return nil, fmt.Errorf("must be a list, got %v", raw)
+	}
+	list := []string{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
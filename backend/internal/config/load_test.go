@@ -0,0 +1,226 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// isolate runs Load from an empty directory with no AGRISYNC_ variables,
+// so neither the developer's environment nor ./agrisync.yaml leak in.
+func isolate(t *testing.T) string {
+	t.Helper()
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, envPrefix) {
+			t.Setenv(name, "")
+		}
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	return dir
+}
+
+// write creates a file in dir and returns its path.
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string // name of the config file; "" for none
+		content    string
+		env        map[string]string
+		want       string // listen_addr
+		wantSource string // "file" stands for the config file's path
+	}{
+		{
+			name: "default",
+			env:  map[string]string{"AGRISYNC_JWT_SECRET": testSecret},
+			want: ":8080", wantSource: "default",
+		},
+		{
+			name: "yaml file over default", file: "agrisync.yaml",
+			content: "listen_addr: :9000\njwt_secret: " + testSecret + "\n",
+			want:    ":9000", wantSource: "file",
+		},
+		{
+			name: "toml file over default", file: "agrisync.toml",
+			content: "listen_addr = \":9001\"\njwt_secret = \"" + testSecret + "\"\n",
+			want:    ":9001", wantSource: "file",
+		},
+		{
+			name: "environment over file", file: "agrisync.yaml",
+			content: "listen_addr: :9000\njwt_secret: " + testSecret + "\n",
+			env:     map[string]string{"AGRISYNC_LISTEN_ADDR": ":9100"},
+			want:    ":9100", wantSource: "AGRISYNC_LISTEN_ADDR",
+		},
+		{
+			name: "empty environment variable leaves the file", file: "agrisync.yaml",
+			content: "listen_addr: :9000\njwt_secret: " + testSecret + "\n",
+			env:     map[string]string{"AGRISYNC_LISTEN_ADDR": ""},
+			want:    ":9000", wantSource: "file",
+		},
+		{
+			name: "AGRISYNC_CONFIG over the default file names", file: "other.yml",
+			content: "listen_addr: :9200\njwt_secret: " + testSecret + "\n",
+			env:     map[string]string{ConfigFileEnv: "other.yml"},
+			want:    ":9200", wantSource: "file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			if tt.file != "" {
+				write(t, dir, tt.file, tt.content)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.ListenAddr != tt.want {
+				t.Errorf("listen_addr = %q, want %q", cfg.ListenAddr, tt.want)
+			}
+			wantSource := tt.wantSource
+			if wantSource == "file" {
+				wantSource = tt.file
+			}
+			if got := cfg.Source("listen_addr"); got != wantSource {
+				t.Errorf("source = %q, want %q", got, wantSource)
+			}
+		})
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	dir := isolate(t)
+	fromFile := strings.Repeat("f", minJWTSecret)
+	fromEnv := strings.Repeat("e", minJWTSecret)
+	write(t, dir, "file-secret", fromFile+"\n")
+	write(t, dir, "env-secret", fromEnv+"\r\n")
+	write(t, dir, "agrisync.yaml", "jwt_secret_file: file-secret\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.JWTSecret != fromFile || cfg.Source("jwt_secret") != "agrisync.yaml" {
+		t.Errorf("jwt_secret = %q from %s, want the file's secret without its newline", cfg.JWTSecret, cfg.Source("jwt_secret"))
+	}
+
+	t.Setenv("AGRISYNC_JWT_SECRET_FILE", "env-secret")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.JWTSecret != fromEnv || cfg.Source("jwt_secret") != "AGRISYNC_JWT_SECRET_FILE" {
+		t.Errorf("jwt_secret = %q from %s, want the environment's secret file", cfg.JWTSecret, cfg.Source("jwt_secret"))
+	}
+}
+
+func TestLoadTypes(t *testing.T) {
+	dir := isolate(t)
+	write(t, dir, "agrisync.yaml", `
+jwt_secret: `+testSecret+`
+cors_origins: [https://a.example.com, " https://b.example.com "]
+token_ttl: 2h
+geofence_radius_m: 250
+sqlite_read_conns: 6
+`)
+	t.Setenv("AGRISYNC_BACKUP_COMPRESS", "false")
+	t.Setenv("AGRISYNC_CURRENCY", "kes")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := strings.Join(cfg.CORSOrigins, " "); got != "https://a.example.com https://b.example.com" {
+		t.Errorf("cors_origins = %q", got)
+	}
+	if cfg.TokenTTL != 2*time.Hour || cfg.GeofenceRadiusM != 250 || cfg.SQLiteReadConns != 6 {
+		t.Errorf("token_ttl %s, geofence_radius_m %g, sqlite_read_conns %d", cfg.TokenTTL, cfg.GeofenceRadiusM, cfg.SQLiteReadConns)
+	}
+	if cfg.BackupCompress || cfg.Currency != "KES" {
+		t.Errorf("backup_compress %v, currency %q; want false and KES", cfg.BackupCompress, cfg.Currency)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	base := "jwt_secret: " + testSecret + "\n"
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    string // in the error
+	}{
+		{"no secret", "agrisync.yaml", "listen_addr: :8080\n", nil, "jwt_secret (from default): is required"},
+		{"short secret", "agrisync.yaml", "jwt_secret: short\n", nil, "must be at least 32 bytes"},
+		{"dev secret without dev mode", "", "", map[string]string{"AGRISYNC_JWT_SECRET": DevJWTSecret}, "needs dev_mode"},
+		{"unknown key", "agrisync.yaml", base + "listen_adr: :9000\n", nil, `unknown setting "listen_adr"`},
+		{"secret and secret file in the file", "agrisync.yaml", base + "jwt_secret_file: s\n", nil, "set jwt_secret or jwt_secret_file, not both"},
+		{"secret and secret file in the environment", "", "", map[string]string{"AGRISYNC_JWT_SECRET": testSecret, "AGRISYNC_JWT_SECRET_FILE": "s"}, "set AGRISYNC_JWT_SECRET or AGRISYNC_JWT_SECRET_FILE, not both"},
+		{"missing secret file", "agrisync.yaml", "jwt_secret_file: missing\n", nil, "jwt_secret_file"},
+		{"empty secret file", "agrisync.yaml", "jwt_secret_file: agrisync.yaml.empty\n", nil, "is empty"},
+		{"_file on a setting that is not secret", "agrisync.yaml", base + "listen_addr_file: x\n", nil, `unknown setting "listen_addr_file"`},
+		{"bad duration in the file", "agrisync.yaml", base + "token_ttl: 1 day\n", nil, "agrisync.yaml: token_ttl: \"1 day\" is not a duration"},
+		{"list for a single value", "agrisync.yaml", base + "listen_addr: [a, b]\n", nil, "must be a single value"},
+		{"bad bool in the environment", "agrisync.yaml", base, map[string]string{"AGRISYNC_DEV_MODE": "yes please"}, "AGRISYNC_DEV_MODE: \"yes please\" is not true or false"},
+		{"bad number in the environment", "agrisync.yaml", base, map[string]string{"AGRISYNC_MAX_BODY_BYTES": "1MB"}, "is not a whole number"},
+		{"invalid value names its source", "agrisync.yaml", base + "log_level: info\n", map[string]string{"AGRISYNC_LOG_LEVEL": "loud"}, "log_level (from AGRISYNC_LOG_LEVEL)"},
+		{"negative accuracy cap", "agrisync.yaml", base + "geofence_max_accuracy_m: -1\n", nil, "geofence_max_accuracy_m (from agrisync.yaml): must not be negative"},
+		{"missing AGRISYNC_CONFIG", "", "", map[string]string{ConfigFileEnv: "nowhere.yaml", "AGRISYNC_JWT_SECRET": testSecret}, ConfigFileEnv + ": "},
+		{"config file of another type", "agrisync.json", "{}", map[string]string{ConfigFileEnv: "agrisync.json", "AGRISYNC_JWT_SECRET": testSecret}, "must end in .yaml, .yml or .toml"},
+		{"malformed yaml", "agrisync.yaml", "listen_addr: [\n", nil, "agrisync.yaml: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			write(t, dir, "agrisync.yaml.empty", "\n")
+			if tt.file != "" {
+				write(t, dir, tt.file, tt.content)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := Load()
+			if err == nil {
+				t.Fatalf("Load succeeded, want an error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load: %v\nwant an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadReportsEverything checks that one Load lists every problem, not
+// just the first.
+func TestLoadReportsEverything(t *testing.T) {
+	dir := isolate(t)
+	write(t, dir, "agrisync.yaml", "jwt_secret: short\nlog_format: xml\nnot_a_key: 1\n")
+	t.Setenv("AGRISYNC_DB_DRIVER", "mysql")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load succeeded")
+	}
+	for _, want := range []string{"jwt_secret", "log_format", `"not_a_key"`, "db_driver (from AGRISYNC_DB_DRIVER)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s:\n%v", want, err)
+		}
+	}
+}
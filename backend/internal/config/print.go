@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redactedValue = "REDACTED"
+
+// Print writes the settings as a YAML config file, each line noting where
+// its value came from. With redact, secrets are hidden; a URL keeps all
+// but its password.
+func (c *Config) Print(w io.Writer, redact bool) error {
+	for _, f := range c.fields() {
+		value := f.value.Interface()
+		if redact && f.secret {
+			value = redacted(f.value.String())
+		}
+		line := fmt.Sprintf("%s: %s", f.key, yamlValue(value))
+		if _, err := fmt.Fprintf(w, "%-60s # %s\n", line, c.Source(f.key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func redacted(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if u, err := url.Parse(secret); err == nil && u.Scheme != "" && u.Host != "" {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return u.Redacted()
+		}
+		return secret
+	}
+	return redactedValue
+}
+
+func yamlValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case time.Duration:
+		return strconv.Quote(v.String())
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = strconv.Quote(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// minJWTSecret is the shortest signing secret accepted, in bytes.
+const minJWTSecret = 32
+
+// validate checks the settings together and returns every problem found.
+// It also normalizes case where the value is case-insensitive.
+func (c *Config) validate() []error {
+	var problems []error
+	bad := func(key, format string, args ...any) {
+		problems = append(problems, fmt.Errorf("%s (from %s): %s", key, c.Source(key), fmt.Sprintf(format, args...)))
+	}
+
+	if _, port, err := net.SplitHostPort(c.ListenAddr); err != nil || port == "" {
+		bad("listen_addr", "%q is not a host:port address like :8080", c.ListenAddr)
+	}
+	if len(c.CORSOrigins) == 0 {
+		bad("cors_origins", "at least one origin is needed")
+	}
+	for _, origin := range c.CORSOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.TrimSuffix(u.Path, "/") != "" {
+			bad("cors_origins", "%q is not an origin like https://app.example.com", origin)
+		}
+	}
+
+	timeouts := []struct {
+		key string
+		d   time.Duration
+	}{
+		{"read_header_timeout", c.ReadHeaderTimeout},
+		{"read_timeout", c.ReadTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"idle_timeout", c.IdleTimeout},
+		{"shutdown_delay", c.ShutdownDelay},
+	}
+	for _, t := range timeouts {
+		if t.d < 0 {
+			bad(t.key, "must not be negative (0 means none)")
+		}
+	}
+	if c.ShutdownTimeout <= 0 {
+		bad("shutdown_timeout", "must be positive, got %s", c.ShutdownTimeout)
+	}
+	if c.MaxHeaderBytes <= 0 {
+		bad("max_header_bytes", "must be positive, got %d", c.MaxHeaderBytes)
+	}
+	if c.MaxBodyBytes <= 0 {
+		bad("max_body_bytes", "must be positive, got %d", c.MaxBodyBytes)
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		bad("tls_cert_file", "tls_cert_file and tls_key_file must be set together")
+	} else if c.TLSCertFile != "" {
+		if _, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile); err != nil {
+			bad("tls_cert_file", "%v", err)
+		}
+	}
+
+	if c.HealthCheckTimeout <= 0 {
+		bad("health_check_timeout", "must be positive, got %s", c.HealthCheckTimeout)
+	}
+	if c.HealthMinFreeBytes < 0 {
+		bad("health_min_free_bytes", "must not be negative")
+	}
+
+	c.LogFormat = strings.ToLower(c.LogFormat)
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		bad("log_format", "must be text or json, got %q", c.LogFormat)
+	}
+	c.LogLevel = strings.ToLower(c.LogLevel)
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		bad("log_level", "must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+
+	c.TraceExporter = strings.ToLower(c.TraceExporter)
+	switch c.TraceExporter {
+	case TraceExporterNone, TraceExporterStdout, TraceExporterOTLP:
+	default:
+		bad("trace_exporter", "must be %s, %s or %s, got %q", TraceExporterNone, TraceExporterStdout, TraceExporterOTLP, c.TraceExporter)
+	}
+	if c.TraceOTLPEndpoint != "" {
+		u, err := url.Parse(c.TraceOTLPEndpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			bad("trace_otlp_endpoint", "%q is not a URL like http://localhost:4318", c.TraceOTLPEndpoint)
+		}
+	}
+	if c.TraceSampleRatio < 0 || c.TraceSampleRatio > 1 {
+		bad("trace_sample_ratio", "must be between 0 and 1, got %g", c.TraceSampleRatio)
+	}
+
+	switch {
+	case c.JWTSecret == "":
+		bad("jwt_secret", "is required; set AGRISYNC_JWT_SECRET, or dev_mode for local development")
+	case c.JWTSecret == DevJWTSecret && !c.DevMode:
+		bad("jwt_secret", "is the public development secret, which needs dev_mode")
+	case len(c.JWTSecret) < minJWTSecret:
+		bad("jwt_secret", "must be at least %d bytes", minJWTSecret)
+	}
+	if c.TokenTTL <= 0 {
+		bad("token_ttl", "must be positive, got %s", c.TokenTTL)
+	}
+
+	c.Currency = strings.ToUpper(c.Currency)
+	if !currencyCode.MatchString(c.Currency) {
+		bad("currency", "%q is not a three-letter ISO 4217 code like KES", c.Currency)
+	}
+
+	switch c.DBDriver {
+	case DriverSQLite:
+		if c.DBPath == "" {
+			bad("db_path", "is needed with the sqlite driver")
+		}
+	case DriverPostgres:
+		if c.DatabaseURL == "" {
+			bad("database_url", "is needed with the postgres driver")
+		}
+	default:
+		bad("db_driver", "must be %s or %s, got %q", DriverSQLite, DriverPostgres, c.DBDriver)
+	}
+
+	c.SQLiteSynchronous = strings.ToUpper(c.SQLiteSynchronous)
+	switch c.SQLiteSynchronous {
+	case "OFF", "NORMAL", "FULL", "EXTRA":
+	default:
+		bad("sqlite_synchronous", "must be OFF, NORMAL, FULL or EXTRA, got %q", c.SQLiteSynchronous)
+	}
+	if c.SQLiteBusyTimeout < 0 {
+		bad("sqlite_busy_timeout", "must not be negative")
+	}
+	if c.SQLiteReadConns <= 0 {
+		bad("sqlite_read_conns", "must be positive, got %d", c.SQLiteReadConns)
+	}
+	if c.DBQueryTimeout < 0 {
+		bad("db_query_timeout", "must not be negative (0 means no limit)")
+	}
+
+	if c.PriceTolerance < 0 {
+		bad("price_tolerance", "must not be negative")
+	}
+	if c.GeofenceRadiusM <= 0 {
+		bad("geofence_radius_m", "must be positive")
+	}
+	if c.GeofenceMaxAccuracyM < 0 {
+		bad("geofence_max_accuracy_m", "must not be negative")
+	}
+	if c.ScaleToleranceKg < 0 {
+		bad("scale_tolerance_kg", "must not be negative")
+	}
+
+	if c.BlobDir == "" {
+		bad("blob_dir", "must not be empty")
+	}
+	if c.UploadDir == "" {
+		bad("upload_dir", "must not be empty")
+	}
+	if c.MaxUploadBytes <= 0 {
+		bad("max_upload_bytes", "must be positive, got %d", c.MaxUploadBytes)
+	}
+
+	if c.BackupInterval < 0 {
+		bad("backup_interval", "must not be negative (0 turns scheduled backups off)")
+	} else if c.BackupInterval > 0 && c.DBDriver != DriverSQLite {
+		bad("backup_interval", "scheduled backups need the sqlite driver; use pg_dump for %s", c.DBDriver)
+	}
+	if c.BackupDir == "" {
+		bad("backup_dir", "must not be empty")
+	}
+	keep := []struct {
+		key string
+		n   int
+	}{
+		{"backup_keep_last", c.BackupKeepLast},
+		{"backup_keep_daily", c.BackupKeepDaily},
+		{"backup_keep_weekly", c.BackupKeepWeekly},
+	}
+	for _, k := range keep {
+		if k.n < 0 {
+			bad(k.key, "must not be negative (0 turns the rule off)")
+		}
+	}
+	return problems
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"agri-sync-backend/internal/config"
+)
+
+// DB is an open database. On PostgreSQL it is a single pool. On SQLite the
+// embedded *sql.DB is the write pool, with one connection, and SELECTs
+// passed to QueryContext or QueryRowContext go to a pool of read-only
+// connections instead. Anything that needs a plain *sql.DB, such as the
+// migrations, gets the write pool.
+type DB struct {
+	*sql.DB
+	reader *sql.DB
+	driver string
+}
+
+// Driver is config.DriverSQLite or config.DriverPostgres.
+func (db *DB) Driver() string {
+	return db.driver
+}
+
+// VacuumInto writes a consistent copy of a SQLite database to path, which
+// must not exist yet. It runs on the read pool, so writes carry on while
+// the copy is taken.
+func (db *DB) VacuumInto(ctx context.Context, path string) error {
+	if db.driver != config.DriverSQLite {
+		return fmt.Errorf("VACUUM INTO needs SQLite; back up %s with its own tools", db.driver)
+	}
+	_, err := db.reader.ExecContext(ctx, "VACUUM INTO ?", path)
+	return err
+}
+
+// QueryContext runs a SELECT on the read pool and anything else, such as
+// INSERT ... RETURNING, on the write pool. Inside a transaction (see
+// WithinTx) it runs in the transaction instead.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if tx := TxFrom(ctx, db); tx != nil {
+		return tx.QueryContext(ctx, query, args...)
+	}
+	return db.pool(query).QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext is QueryContext for a single row.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if tx := TxFrom(ctx, db); tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	return db.pool(query).QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext runs a statement on the write pool, or in the transaction
+// ctx is inside.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if tx := TxFrom(ctx, db); tx != nil {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+// ErrNestedTx is returned by BeginTx for a transaction begun inside another
+// on SQLite. Join the one in ctx instead, as repository.WithTx does.
+var ErrNestedTx = errors.New("database: transaction begun inside another")
+
+// BeginTx starts a transaction on the write pool. Transactions do not nest:
+// on SQLite the one write connection is already taken by the transaction
+// ctx is inside, so beginning another there fails with ErrNestedTx rather
+// than waiting for it until the query timeout.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if db.driver == config.DriverSQLite && TxFrom(ctx, db) != nil {
+		return nil, ErrNestedTx
+	}
+	return db.DB.BeginTx(ctx, opts)
+}
+
+func (db *DB) pool(query string) *sql.DB {
+	if db.reader != nil && isSelect(query) {
+		return db.reader
+	}
+	return db.DB
+}
+
+// txKey marks a context as being inside a transaction.
+type txKey struct{}
+
+type ambientTx struct {
+	db any
+	tx *sql.Tx
+}
+
+// WithinTx marks ctx as being inside tx, a transaction on db. Statements
+// run on db with such a context go to tx, so code handed the context takes
+// part in the transaction without being given tx itself.
+// repository.WithTx passes such a context to its callback.
+func WithinTx(ctx context.Context, db any, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, ambientTx{db: db, tx: tx})
+}
+
+// TxFrom returns the transaction on db that ctx is inside, or nil.
+func TxFrom(ctx context.Context, db any) *sql.Tx {
+	if a, ok := ctx.Value(txKey{}).(ambientTx); ok && a.db == db {
+		return a.tx
+	}
+	return nil
+}
+
+// isSelect reports whether query starts with SELECT. WITH is left to the
+// write pool, since a CTE can wrap an INSERT or UPDATE.
+func isSelect(query string) bool {
+	query = strings.TrimLeft(query, " \t\r\n")
+	return len(query) >= 6 && strings.EqualFold(query[:6], "SELECT")
+}
+
+// PingContext checks that both pools can reach the database.
+func (db *DB) PingContext(ctx context.Context) error {
+	if db.reader != nil {
+		if err := db.reader.PingContext(ctx); err != nil {
+			return fmt.Errorf("read pool: %w", err)
+		}
+	}
+	return db.DB.PingContext(ctx)
+}
+
+// Close closes both pools.
+func (db *DB) Close() error {
+	var err error
+	if db.reader != nil {
+		err = db.reader.Close()
+	}
+	return errors.Join(err, db.DB.Close())
+}
+
+// Connect opens the database selected by cfg.DBDriver.
+func Connect(cfg *config.Config) (*DB, error) {
+	switch cfg.DBDriver {
+	case config.DriverSQLite:
+		return ConnectSQLite(cfg.DBPath, SQLiteOptions{
+			Synchronous: cfg.SQLiteSynchronous,
+			BusyTimeout: cfg.SQLiteBusyTimeout,
+			ReadConns:   cfg.SQLiteReadConns,
+		})
+	case config.DriverPostgres:
+		return ConnectPostgres(cfg.DatabaseURL)
+	}
+	return nil, fmt.Errorf("unsupported database driver %q (want %q or %q)",
+		cfg.DBDriver, config.DriverSQLite, config.DriverPostgres)
+}
+
+// Describe names the database for startup logs without exposing the
+// PostgreSQL credentials.
+func Describe(cfg *config.Config) string {
+	if cfg.DBDriver != config.DriverPostgres {
+		return cfg.DBPath
+	}
+	u, err := url.Parse(cfg.DatabaseURL)
+	if err != nil || u.Host == "" {
+		return "postgres"
+	}
+	return "postgres://" + u.Host + u.Path
+}
@@ -0,0 +1,304 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"agri-sync-backend/internal/config"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// migrationsFS holds one folder of migrations per driver ("sqlite",
+// "postgres"), numbered alike so that a version means the same schema in
+// both. They are compiled into every binary, so none of them depends on the
+// working directory.
+//
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
+var migrationsFS embed.FS
+
+// NoVersion is the version of a database no migration has run on, as
+// accepted by ForceMigrationVersion and PendingMigrations.
+const NoVersion = migratedb.NilVersion
+
+// DirtyError means a migration failed partway through. The schema is
+// somewhere between two versions and must be repaired by hand.
+type DirtyError struct {
+	Version uint
+}
+
+func (e *DirtyError) Error() string {
+	return fmt.Sprintf("schema is dirty at version %d: a migration failed partway; "+
+		"repair the database, then record the version it is at with `migrate force <version>`", e.Version)
+}
+
+// Migration is one step of a migration plan.
+type Migration struct {
+	Version uint
+	Name    string // file name, e.g. 000014_normalize_timestamps.up.sql
+	SQL     string
+}
+
+func migrationSource(driver string) (source.Driver, error) {
+	if driver != config.DriverSQLite && driver != config.DriverPostgres {
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	return iofs.New(migrationsFS, "migrations/"+driver)
+}
+
+// newMigrate prepares the embedded migrations for the given driver.
+func newMigrate(db *sql.DB, driver string) (*migrate.Migrate, error) {
+	var (
+		instance migratedb.Driver
+		name     string
+		err      error
+	)
+	switch driver {
+	case config.DriverSQLite:
+		instance, err = sqlite3.WithInstance(db, &sqlite3.Config{})
+		name = "sqlite3"
+	case config.DriverPostgres:
+		instance, err = postgres.WithInstance(db, &postgres.Config{})
+		name = "postgres"
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate driver: %w", err)
+	}
+
+	src, err := migrationSource(driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, name, instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// RunMigrations applies all pending migrations for the driver. It returns a
+// *DirtyError, without touching the schema, if an earlier migration failed
+// partway.
+func RunMigrations(db *DB, driver string) error {
+	m, err := newMigrate(db.DB, driver)
+	if err != nil {
+		return err
+	}
+	if err := checkClean(m); err != nil {
+		return err
+	}
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	slog.Info("Migrations applied")
+	return nil
+}
+
+// RunMigrationsDown rolls back the most recent migration.
+func RunMigrationsDown(db *DB, driver string) error {
+	m, err := newMigrate(db.DB, driver)
+	if err != nil {
+		return err
+	}
+	if err := checkClean(m); err != nil {
+		return err
+	}
+
+	if err := m.Steps(-1); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("migration down failed: %w", err)
+	}
+
+	return nil
+}
+
+// MigrateTo migrates up or down to version, which must be one of the
+// migrations.
+func MigrateTo(db *DB, driver string, version uint) error {
+	m, err := newMigrate(db.DB, driver)
+	if err != nil {
+		return err
+	}
+	if err := checkClean(m); err != nil {
+		return err
+	}
+
+	if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("migration to version %d failed: %w", version, err)
+	}
+	return nil
+}
+
+// ForceMigrationVersion records version (or NoVersion) as the schema's
+// version and clears the dirty flag, without running any SQL. It is for
+// after a dirty schema has been repaired by hand.
+func ForceMigrationVersion(db *DB, driver string, version int) error {
+	m, err := newMigrate(db.DB, driver)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("force version %d failed: %w", version, err)
+	}
+	return nil
+}
+
+func MigrationStatus(db *DB, driver string) (version uint, dirty bool, err error) {
+	m, err := newMigrate(db.DB, driver)
+	if err != nil {
+		return 0, false, err
+	}
+
+	version, dirty, err = m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+func checkClean(m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return fmt.Errorf("failed to get migration version: %w", err)
+	}
+	if dirty {
+		return &DirtyError{Version: version}
+	}
+	return nil
+}
+
+// LatestVersion is the newest migration for the driver.
+func LatestVersion(driver string) (uint, error) {
+	src, err := migrationSource(driver)
+	if err != nil {
+		return 0, err
+	}
+	version, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// PreviousVersion is the migration before version, or NoVersion if version
+// is the first.
+func PreviousVersion(driver string, version uint) (int, error) {
+	src, err := migrationSource(driver)
+	if err != nil {
+		return 0, err
+	}
+	prev, err := src.Prev(version)
+	if errors.Is(err, os.ErrNotExist) {
+		return NoVersion, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(prev), nil
+}
+
+// PendingMigrations returns, in order, the migrations that going from the
+// database's current version to target (a migration version or NoVersion)
+// would run, without running them.
+func PendingMigrations(db *DB, driver string, target int) ([]*Migration, error) {
+	m, err := newMigrate(db.DB, driver)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkClean(m); err != nil {
+		return nil, err
+	}
+	current := NoVersion
+	if version, _, err := m.Version(); err == nil {
+		current = int(version)
+	} else if err != migrate.ErrNilVersion {
+		return nil, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	src, err := migrationSource(driver)
+	if err != nil {
+		return nil, err
+	}
+	if target != NoVersion {
+		r, _, err := src.ReadUp(uint(target))
+		if err != nil {
+			return nil, fmt.Errorf("no migration with version %d", target)
+		}
+		r.Close()
+	}
+
+	var plan []*Migration
+	switch {
+	case target > current:
+		var version uint
+		if current == NoVersion {
+			version, err = src.First()
+		} else {
+			version, err = src.Next(uint(current))
+		}
+		for err == nil && int(version) <= target {
+			var step *Migration
+			if step, err = readMigration(version, "up", src.ReadUp); err != nil {
+				return nil, err
+			}
+			plan = append(plan, step)
+			version, err = src.Next(version)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	case target < current:
+		for version := current; version > target; {
+			step, err := readMigration(uint(version), "down", src.ReadDown)
+			if err != nil {
+				return nil, err
+			}
+			plan = append(plan, step)
+			if version, err = PreviousVersion(driver, uint(version)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return plan, nil
+}
+
+func readMigration(version uint, direction string, read func(uint) (io.ReadCloser, string, error)) (*Migration, error) {
+	r, identifier, err := read(version)
+	if err != nil {
+		return nil, fmt.Errorf("no %s migration for version %d: %w", direction, version, err)
+	}
+	defer r.Close()
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Migration{
+		Version: version,
+		Name:    fmt.Sprintf("%06d_%s.%s.sql", version, identifier, direction),
+		SQL:     string(body),
+	}, nil
+}
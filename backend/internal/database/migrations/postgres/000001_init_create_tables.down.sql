@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS collectors;
+DROP TABLE IF EXISTS farmers;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS prices (
+    id TEXT PRIMARY KEY,
+
+    crop_type TEXT NOT NULL,
+    grade TEXT NOT NULL DEFAULT '',
+    region TEXT NOT NULL DEFAULT '',
+
+    price_per_kg DOUBLE PRECISION NOT NULL,
+
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to TIMESTAMPTZ,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_prices_lookup ON prices(crop_type, grade, region, effective_from);
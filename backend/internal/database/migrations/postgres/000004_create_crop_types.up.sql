@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS crop_types (
+    code TEXT PRIMARY KEY,
+
+    name_en TEXT NOT NULL,
+    name_sw TEXT NOT NULL DEFAULT '',
+    unit TEXT NOT NULL DEFAULT 'kg',          -- kg, litres
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    allowed_grades JSONB NOT NULL DEFAULT '[]', -- array of grade codes
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_crop_types_updated_at ON crop_types(updated_at);
+
+INSERT INTO crop_types (code, name_en, name_sw, unit, created_at, updated_at) VALUES
+    ('tea',    'Tea',    'Chai',    'kg',     date_trunc('second', now()), date_trunc('second', now())),
+    ('coffee', 'Coffee', 'Kahawa',  'kg',     date_trunc('second', now()), date_trunc('second', now())),
+    ('milk',   'Milk',   'Maziwa',  'litres', date_trunc('second', now()), date_trunc('second', now()))
+ON CONFLICT DO NOTHING;
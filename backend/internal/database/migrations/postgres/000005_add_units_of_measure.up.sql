@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS units (
+    code TEXT PRIMARY KEY,
+
+    name_en TEXT NOT NULL,
+    name_sw TEXT NOT NULL DEFAULT '',
+    dimension TEXT NOT NULL,     -- mass, volume, count
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO units (code, name_en, name_sw, dimension, created_at, updated_at) VALUES
+    ('kg',     'Kilogram', 'Kilo',   'mass',   date_trunc('second', now()), date_trunc('second', now())),
+    ('g',      'Gram',     'Gramu',  'mass',   date_trunc('second', now()), date_trunc('second', now())),
+    ('litres', 'Litre',    'Lita',   'volume', date_trunc('second', now()), date_trunc('second', now())),
+    ('bag',    'Bag',      'Gunia',  'count',  date_trunc('second', now()), date_trunc('second', now()))
+ON CONFLICT DO NOTHING;
+
+-- Factor to the base unit (kg). crop_type '' applies to every crop; a
+-- crop-specific row wins (e.g. milk density, coffee cherry bag weight).
+CREATE TABLE IF NOT EXISTS unit_conversions (
+    crop_type TEXT NOT NULL DEFAULT '',
+    unit TEXT NOT NULL REFERENCES units(code),
+    to_kg DOUBLE PRECISION NOT NULL,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (crop_type, unit)
+);
+
+INSERT INTO unit_conversions (crop_type, unit, to_kg, created_at, updated_at) VALUES
+    ('',     'kg',     1,     date_trunc('second', now()), date_trunc('second', now())),
+    ('',     'g',      0.001, date_trunc('second', now()), date_trunc('second', now())),
+    ('milk', 'litres', 1.03,  date_trunc('second', now()), date_trunc('second', now()))
+ON CONFLICT DO NOTHING;
+
+-- Collections carry what was measured; weight_kg and price_per_kg stay as
+-- the normalized base-unit values for reports and legacy clients.
+ALTER TABLE collections ADD COLUMN quantity DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE collections ADD COLUMN unit TEXT NOT NULL DEFAULT 'kg';
+ALTER TABLE collections ADD COLUMN price_per_unit DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+UPDATE collections SET quantity = weight_kg, price_per_unit = price_per_kg;
+
+-- Catalog prices are quoted per unit.
+ALTER TABLE prices RENAME COLUMN price_per_kg TO price_per_unit;
+ALTER TABLE prices ADD COLUMN unit TEXT NOT NULL DEFAULT 'kg';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_collections_center_id;
+ALTER TABLE collections DROP COLUMN center_id;
+
+DROP TABLE IF EXISTS route_stop_farmers;
+DROP TABLE IF EXISTS route_stops;
+DROP TABLE IF EXISTS routes;
+DROP TABLE IF EXISTS collection_centers;
@@ -0,0 +1,56 @@
+CREATE TABLE IF NOT EXISTS collection_centers (
+    id TEXT PRIMARY KEY,
+
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    region TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS routes (
+    id TEXT PRIMARY KEY,
+
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    collector_id TEXT REFERENCES collectors(id), -- assigned collector, if any
+    days TEXT NOT NULL DEFAULT '', -- comma separated weekdays (mon,tue,...); empty = daily
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_routes_collector_id ON routes(collector_id);
+
+-- Ordered stops: each stop is a center, and optionally names the farmers
+-- expected to deliver there.
+CREATE TABLE IF NOT EXISTS route_stops (
+    id TEXT PRIMARY KEY,
+
+    route_id TEXT NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    center_id TEXT NOT NULL REFERENCES collection_centers(id),
+    position INTEGER NOT NULL,
+    planned_time TEXT NOT NULL DEFAULT '', -- HH:MM local
+
+    UNIQUE (route_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS route_stop_farmers (
+    stop_id TEXT NOT NULL REFERENCES route_stops(id) ON DELETE CASCADE,
+    farmer_id TEXT NOT NULL REFERENCES farmers(id),
+
+    PRIMARY KEY (stop_id, farmer_id)
+);
+
+ALTER TABLE collections ADD COLUMN center_id TEXT REFERENCES collection_centers(id);
+
+CREATE INDEX IF NOT EXISTS idx_collections_center_id ON collections(center_id);
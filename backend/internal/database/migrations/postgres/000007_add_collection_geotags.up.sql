@@ -0,0 +1,13 @@
+-- Registered farm location, used as a geofence for the farmer's deliveries
+ALTER TABLE farmers ADD COLUMN latitude DOUBLE PRECISION;
+ALTER TABLE farmers ADD COLUMN longitude DOUBLE PRECISION;
+
+ALTER TABLE collections ADD COLUMN latitude DOUBLE PRECISION;
+ALTER TABLE collections ADD COLUMN longitude DOUBLE PRECISION;
+ALTER TABLE collections ADD COLUMN location_accuracy_m DOUBLE PRECISION;
+ALTER TABLE collections ADD COLUMN location_captured_at TIMESTAMPTZ;
+
+-- Review flags, e.g. ["outside_geofence"]
+ALTER TABLE collections ADD COLUMN flags JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_collections_location ON collections(latitude, longitude);
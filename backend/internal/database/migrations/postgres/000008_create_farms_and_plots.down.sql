@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_collections_plot_id;
+ALTER TABLE collections DROP COLUMN plot_id;
+
+DROP TABLE IF EXISTS plots;
+DROP TABLE IF EXISTS farms;
@@ -0,0 +1,42 @@
+-- Farms belong to a farmer; plots are the parts of a farm under one crop.
+-- Rows are soft-deleted (deleted_at) so syncing devices learn about removals.
+CREATE TABLE IF NOT EXISTS farms (
+    id TEXT PRIMARY KEY,
+
+    farmer_id TEXT NOT NULL REFERENCES farmers(id),
+    name TEXT NOT NULL,
+    area_acres DOUBLE PRECISION NOT NULL DEFAULT 0,
+    boundary JSONB,               -- GeoJSON Polygon
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_farms_farmer_id ON farms(farmer_id);
+CREATE INDEX IF NOT EXISTS idx_farms_updated_at ON farms(updated_at);
+
+CREATE TABLE IF NOT EXISTS plots (
+    id TEXT PRIMARY KEY,
+
+    farm_id TEXT NOT NULL REFERENCES farms(id),
+    name TEXT NOT NULL,
+    crop_type TEXT NOT NULL,
+    area_acres DOUBLE PRECISION NOT NULL DEFAULT 0,
+    boundary JSONB,               -- GeoJSON Polygon
+    stock_count INTEGER NOT NULL DEFAULT 0,  -- bushes, trees or cows
+    stock_unit TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_plots_farm_id ON plots(farm_id);
+
+-- Which plot a delivery was harvested from, when known
+ALTER TABLE collections ADD COLUMN plot_id TEXT REFERENCES plots(id);
+
+CREATE INDEX IF NOT EXISTS idx_collections_plot_id ON collections(plot_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS lot_transfers;
+DROP INDEX IF EXISTS idx_lot_collections_lot_id;
+DROP TABLE IF EXISTS lot_collections;
+DROP TABLE IF EXISTS lots;
@@ -0,0 +1,46 @@
+-- A lot aggregates collections of one crop at a center and is then moved,
+-- as a unit, from the center to a factory and on to a buyer.
+CREATE TABLE IF NOT EXISTS lots (
+    id TEXT PRIMARY KEY,
+
+    code TEXT NOT NULL UNIQUE,
+    center_id TEXT REFERENCES collection_centers(id),
+    crop_type TEXT NOT NULL,
+    weight_kg DOUBLE PRECISION NOT NULL DEFAULT 0,   -- sum of contributing collections
+    status TEXT NOT NULL DEFAULT 'open', -- open, dispatched
+    created_by TEXT NOT NULL,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- A collection goes into at most one lot
+CREATE TABLE IF NOT EXISTS lot_collections (
+    collection_id TEXT PRIMARY KEY REFERENCES collections(id),
+    lot_id TEXT NOT NULL REFERENCES lots(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lot_collections_lot_id ON lot_collections(lot_id);
+
+-- Each leg of the lot's journey, with the weight sent and the weight that
+-- arrived. Shrinkage is the difference.
+CREATE TABLE IF NOT EXISTS lot_transfers (
+    id TEXT PRIMARY KEY,
+
+    lot_id TEXT NOT NULL REFERENCES lots(id),
+    position INTEGER NOT NULL,
+    from_name TEXT NOT NULL,
+    to_kind TEXT NOT NULL,  -- center, factory, buyer
+    to_name TEXT NOT NULL,
+    weight_out_kg DOUBLE PRECISION NOT NULL,
+    weight_in_kg DOUBLE PRECISION,      -- NULL while in transit
+    dispatched_by TEXT NOT NULL,
+    received_by TEXT,
+
+    dispatched_at TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ,
+
+    UNIQUE (lot_id, position)
+);
@@ -0,0 +1,39 @@
+-- Registered digital scales. Each has a shared secret used to sign readings.
+CREATE TABLE IF NOT EXISTS scales (
+    id TEXT PRIMARY KEY,
+
+    serial TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    center_id TEXT REFERENCES collection_centers(id),
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_sequence BIGINT NOT NULL DEFAULT 0, -- highest accepted reading sequence
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS scale_readings (
+    id TEXT PRIMARY KEY,
+
+    scale_id TEXT NOT NULL REFERENCES scales(id),
+    sequence BIGINT NOT NULL,
+    gross_kg DOUBLE PRECISION NOT NULL,
+    tare_kg DOUBLE PRECISION NOT NULL,
+    net_kg DOUBLE PRECISION NOT NULL,
+    measured_at TIMESTAMPTZ NOT NULL,
+    signature TEXT NOT NULL,
+    submitted_by TEXT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL,
+
+    UNIQUE (scale_id, sequence)
+);
+
+-- A reading backs at most one collection
+ALTER TABLE collections ADD COLUMN scale_reading_id TEXT REFERENCES scale_readings(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_scale_reading_id
+    ON collections(scale_reading_id) WHERE scale_reading_id IS NOT NULL;
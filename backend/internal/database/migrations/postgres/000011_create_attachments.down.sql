@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS upload_sessions;
+DROP INDEX IF EXISTS idx_attachments_owner;
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,60 @@
+-- A farmer's challenge to the weight or price recorded on a collection.
+-- Upheld disputes are settled with an adjustment; the collection row is
+-- never rewritten.
+CREATE TABLE IF NOT EXISTS disputes (
+    id TEXT PRIMARY KEY,
+    rowid BIGSERIAL NOT NULL,    -- insertion order, as SQLite's implicit rowid
+
+    collection_id TEXT NOT NULL REFERENCES collections(id),
+    farmer_id TEXT NOT NULL,
+    collector_id TEXT NOT NULL,  -- copied from the collection for per-collector stats
+
+    reason TEXT NOT NULL,
+    proposed_weight_kg DOUBLE PRECISION,     -- the farmer's correction; either or both
+    proposed_price_per_kg DOUBLE PRECISION,
+
+    status TEXT NOT NULL DEFAULT 'open', -- open, upheld, rejected
+    resolution TEXT NOT NULL DEFAULT '', -- reviewer's note to both parties
+    adjustment_id TEXT REFERENCES collection_adjustments(id), -- set when upheld
+    resolved_by TEXT,
+    resolved_at TIMESTAMPTZ,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_disputes_status ON disputes(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_disputes_collector_id ON disputes(collector_id);
+
+-- One open dispute per collection at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_disputes_open_collection
+    ON disputes(collection_id) WHERE status = 'open';
+
+-- Dispute settlements can correct the weight as well as the price, so
+-- adjustments now record both. Existing regrades kept the captured weight.
+ALTER TABLE collection_adjustments ADD COLUMN previous_weight_kg DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE collection_adjustments ADD COLUMN new_weight_kg DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+UPDATE collection_adjustments a
+SET previous_weight_kg = c.weight_kg, new_weight_kg = c.weight_kg
+FROM collections c
+WHERE c.id = a.collection_id;
+
+-- In-app notifications, read by the recipient's device
+CREATE TABLE IF NOT EXISTS notifications (
+    id TEXT PRIMARY KEY,
+    rowid BIGSERIAL NOT NULL,    -- insertion order, as SQLite's implicit rowid
+
+    user_id TEXT NOT NULL,
+    kind TEXT NOT NULL,          -- dispute_opened, dispute_resolved
+    subject_type TEXT NOT NULL,  -- dispute
+    subject_id TEXT NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at);
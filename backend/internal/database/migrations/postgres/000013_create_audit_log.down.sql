@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_audit_log_at;
+DROP INDEX IF EXISTS idx_audit_log_actor_id;
+DROP INDEX IF EXISTS idx_audit_log_entity;
+DROP TABLE IF EXISTS audit_log;
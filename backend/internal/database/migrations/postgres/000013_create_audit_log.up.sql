@@ -0,0 +1,26 @@
+-- Append-only record of every change. Each row's hash covers its contents
+-- and the previous row's hash, so edits and deletions are detectable.
+CREATE TABLE IF NOT EXISTS audit_log (
+    seq BIGINT PRIMARY KEY,      -- assigned by the application, gapless
+    at TIMESTAMPTZ NOT NULL,
+
+    actor_id TEXT NOT NULL DEFAULT '',
+    actor_role TEXT NOT NULL DEFAULT '',
+    device_id TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+
+    action TEXT NOT NULL,        -- create, update, delete, login, login_failed
+    entity_type TEXT NOT NULL,   -- collection, farmer, price, ...
+    entity_id TEXT NOT NULL DEFAULT '',
+    -- JSON kept as TEXT, not JSONB: the hash covers the exact bytes
+    before TEXT,                 -- null on create
+    after TEXT,                  -- null on delete
+
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_at ON audit_log(at);
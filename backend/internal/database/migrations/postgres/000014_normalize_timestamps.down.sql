@@ -0,0 +1,2 @@
+-- The dropped sub-millisecond precision cannot be restored.
+SELECT 1;
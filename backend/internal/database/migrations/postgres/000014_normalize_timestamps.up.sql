@@ -0,0 +1,71 @@
+-- TIMESTAMPTZ already sorts correctly; drop anything finer than the
+-- millisecond precision the repositories keep, so values read back equal
+-- what was written.
+UPDATE farmers SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE collectors SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE collections SET
+    location_captured_at = date_trunc('milliseconds', location_captured_at),
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE prices SET
+    effective_from = date_trunc('milliseconds', effective_from),
+    effective_to = date_trunc('milliseconds', effective_to),
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE grades SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE collection_adjustments SET
+    created_at = date_trunc('milliseconds', created_at);
+UPDATE crop_types SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE units SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE unit_conversions SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE collection_centers SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE routes SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE farms SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at),
+    deleted_at = date_trunc('milliseconds', deleted_at);
+UPDATE plots SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at),
+    deleted_at = date_trunc('milliseconds', deleted_at);
+UPDATE lots SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE lot_transfers SET
+    dispatched_at = date_trunc('milliseconds', dispatched_at),
+    received_at = date_trunc('milliseconds', received_at);
+UPDATE scales SET
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE scale_readings SET
+    measured_at = date_trunc('milliseconds', measured_at),
+    created_at = date_trunc('milliseconds', created_at);
+UPDATE attachments SET
+    created_at = date_trunc('milliseconds', created_at);
+UPDATE upload_sessions SET
+    created_at = date_trunc('milliseconds', created_at);
+UPDATE disputes SET
+    resolved_at = date_trunc('milliseconds', resolved_at),
+    created_at = date_trunc('milliseconds', created_at),
+    updated_at = date_trunc('milliseconds', updated_at);
+UPDATE notifications SET
+    read_at = date_trunc('milliseconds', read_at),
+    created_at = date_trunc('milliseconds', created_at);
+UPDATE audit_log SET
+    at = date_trunc('milliseconds', at);
@@ -0,0 +1,12 @@
+-- The newest audit_log entry. Every append updates this one row first, so
+-- appends queue on its lock and each entry links to the one before it.
+CREATE TABLE IF NOT EXISTS audit_head (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    seq BIGINT NOT NULL,
+    hash TEXT NOT NULL
+);
+
+INSERT INTO audit_head (id, seq, hash)
+SELECT 1, COALESCE(MAX(seq), 0), COALESCE((SELECT hash FROM audit_log ORDER BY seq DESC LIMIT 1), '')
+FROM audit_log
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS prices (
+    id TEXT PRIMARY KEY,
+
+    crop_type TEXT NOT NULL,
+    grade TEXT NOT NULL DEFAULT '',
+    region TEXT NOT NULL DEFAULT '',
+
+    price_per_kg REAL NOT NULL,
+
+    effective_from TEXT NOT NULL,
+    effective_to TEXT,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_prices_lookup ON prices(crop_type, grade, region, effective_from);
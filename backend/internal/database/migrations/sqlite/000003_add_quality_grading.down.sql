@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS collection_adjustments;
+
+ALTER TABLE collections DROP COLUMN rejection_reason;
+ALTER TABLE collections DROP COLUMN quality_readings;
+ALTER TABLE collections DROP COLUMN grade;
+ALTER TABLE collections DROP COLUMN status;
+
+ALTER TABLE prices DROP COLUMN multiplier;
+
+DROP TABLE IF EXISTS grades;
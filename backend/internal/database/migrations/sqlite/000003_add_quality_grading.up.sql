@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS grades (
+    id TEXT PRIMARY KEY,
+
+    crop_type TEXT NOT NULL,
+    kind TEXT NOT NULL,          -- grade, reading, rejection
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+
+    unit TEXT NOT NULL DEFAULT '',   -- readings only, e.g. "%", "g/ml"
+    min_value REAL,
+    max_value REAL,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+
+    UNIQUE (crop_type, kind, code)
+);
+
+-- Grade rows in the price catalog may carry a multiplier on the base price
+-- instead of an absolute price_per_kg.
+ALTER TABLE prices ADD COLUMN multiplier REAL;
+
+ALTER TABLE collections ADD COLUMN status TEXT NOT NULL DEFAULT 'pending';
+ALTER TABLE collections ADD COLUMN grade TEXT NOT NULL DEFAULT '';
+ALTER TABLE collections ADD COLUMN quality_readings TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE collections ADD COLUMN rejection_reason TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS collection_adjustments (
+    id TEXT PRIMARY KEY,
+
+    collection_id TEXT NOT NULL,
+    kind TEXT NOT NULL,          -- regrade
+
+    previous_grade TEXT NOT NULL DEFAULT '',
+    new_grade TEXT NOT NULL DEFAULT '',
+    previous_price_per_kg REAL NOT NULL,
+    new_price_per_kg REAL NOT NULL,
+    amount REAL NOT NULL,        -- value delta credited to the farmer
+
+    reason TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL,
+
+    created_at TEXT NOT NULL,
+
+    FOREIGN KEY (collection_id) REFERENCES collections(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_adjustments_collection_id ON collection_adjustments(collection_id);
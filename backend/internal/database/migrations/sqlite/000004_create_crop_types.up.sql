@@ -0,0 +1,51 @@
+CREATE TABLE IF NOT EXISTS crop_types (
+    code TEXT PRIMARY KEY,
+
+    name_en TEXT NOT NULL,
+    name_sw TEXT NOT NULL DEFAULT '',
+    unit TEXT NOT NULL DEFAULT 'kg',          -- kg, litres
+    active INTEGER NOT NULL DEFAULT 1,
+    allowed_grades TEXT NOT NULL DEFAULT '[]', -- JSON array of grade codes
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_crop_types_updated_at ON crop_types(updated_at);
+
+INSERT OR IGNORE INTO crop_types (code, name_en, name_sw, unit, created_at, updated_at) VALUES
+    ('tea',    'Tea',    'Chai',    'kg',     strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    ('coffee', 'Coffee', 'Kahawa',  'kg',     strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    ('milk',   'Milk',   'Maziwa',  'litres', strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+
+-- Map existing free-text values ("Tea", " TEA", "chai") onto registry codes.
+UPDATE collections SET crop_type = CASE lower(trim(crop_type))
+    WHEN 'chai' THEN 'tea'
+    WHEN 'kahawa' THEN 'coffee'
+    WHEN 'maziwa' THEN 'milk'
+    ELSE lower(trim(crop_type))
+END;
+UPDATE prices SET crop_type = CASE lower(trim(crop_type))
+    WHEN 'chai' THEN 'tea'
+    WHEN 'kahawa' THEN 'coffee'
+    WHEN 'maziwa' THEN 'milk'
+    ELSE lower(trim(crop_type))
+END;
+UPDATE OR IGNORE grades SET crop_type = CASE lower(trim(crop_type))
+    WHEN 'chai' THEN 'tea'
+    WHEN 'kahawa' THEN 'coffee'
+    WHEN 'maziwa' THEN 'milk'
+    ELSE lower(trim(crop_type))
+END;
+
+-- Anything else already in use becomes an active entry so old rows stay valid.
+INSERT OR IGNORE INTO crop_types (code, name_en, created_at, updated_at)
+SELECT DISTINCT crop_type, upper(substr(crop_type, 1, 1)) || substr(crop_type, 2),
+       strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM (
+    SELECT crop_type FROM collections
+    UNION SELECT crop_type FROM prices
+    UNION SELECT crop_type FROM grades
+);
@@ -0,0 +1,9 @@
+ALTER TABLE prices DROP COLUMN unit;
+ALTER TABLE prices RENAME COLUMN price_per_unit TO price_per_kg;
+
+ALTER TABLE collections DROP COLUMN price_per_unit;
+ALTER TABLE collections DROP COLUMN unit;
+ALTER TABLE collections DROP COLUMN quantity;
+
+DROP TABLE IF EXISTS unit_conversions;
+DROP TABLE IF EXISTS units;
@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS collection_centers (
+    id TEXT PRIMARY KEY,
+
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    latitude REAL,
+    longitude REAL,
+    region TEXT NOT NULL DEFAULT '',
+    active INTEGER NOT NULL DEFAULT 1,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS routes (
+    id TEXT PRIMARY KEY,
+
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    collector_id TEXT,           -- assigned collector, if any
+    days TEXT NOT NULL DEFAULT '', -- comma separated weekdays (mon,tue,...); empty = daily
+    active INTEGER NOT NULL DEFAULT 1,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+
+    FOREIGN KEY (collector_id) REFERENCES collectors(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_routes_collector_id ON routes(collector_id);
+
+-- Ordered stops: each stop is a center, and optionally names the farmers
+-- expected to deliver there.
+CREATE TABLE IF NOT EXISTS route_stops (
+    id TEXT PRIMARY KEY,
+
+    route_id TEXT NOT NULL,
+    center_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    planned_time TEXT NOT NULL DEFAULT '', -- HH:MM local
+
+    FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE CASCADE,
+    FOREIGN KEY (center_id) REFERENCES collection_centers(id),
+    UNIQUE (route_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS route_stop_farmers (
+    stop_id TEXT NOT NULL,
+    farmer_id TEXT NOT NULL,
+
+    PRIMARY KEY (stop_id, farmer_id),
+    FOREIGN KEY (stop_id) REFERENCES route_stops(id) ON DELETE CASCADE,
+    FOREIGN KEY (farmer_id) REFERENCES farmers(id)
+);
+
+ALTER TABLE collections ADD COLUMN center_id TEXT REFERENCES collection_centers(id);
+
+CREATE INDEX IF NOT EXISTS idx_collections_center_id ON collections(center_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_collections_location;
+
+ALTER TABLE collections DROP COLUMN flags;
+ALTER TABLE collections DROP COLUMN location_captured_at;
+ALTER TABLE collections DROP COLUMN location_accuracy_m;
+ALTER TABLE collections DROP COLUMN longitude;
+ALTER TABLE collections DROP COLUMN latitude;
+
+ALTER TABLE farmers DROP COLUMN longitude;
+ALTER TABLE farmers DROP COLUMN latitude;
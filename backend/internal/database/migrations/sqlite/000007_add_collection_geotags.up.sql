@@ -0,0 +1,13 @@
+-- Registered farm location, used as a geofence for the farmer's deliveries
+ALTER TABLE farmers ADD COLUMN latitude REAL;
+ALTER TABLE farmers ADD COLUMN longitude REAL;
+
+ALTER TABLE collections ADD COLUMN latitude REAL;
+ALTER TABLE collections ADD COLUMN longitude REAL;
+ALTER TABLE collections ADD COLUMN location_accuracy_m REAL;
+ALTER TABLE collections ADD COLUMN location_captured_at TEXT;
+
+-- JSON array of review flags, e.g. ["outside_geofence"]
+ALTER TABLE collections ADD COLUMN flags TEXT NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_collections_location ON collections(latitude, longitude);
@@ -0,0 +1,52 @@
+-- A lot aggregates collections of one crop at a center and is then moved,
+-- as a unit, from the center to a factory and on to a buyer.
+CREATE TABLE IF NOT EXISTS lots (
+    id TEXT PRIMARY KEY,
+
+    code TEXT NOT NULL UNIQUE,
+    center_id TEXT,
+    crop_type TEXT NOT NULL,
+    weight_kg REAL NOT NULL DEFAULT 0,   -- sum of contributing collections
+    status TEXT NOT NULL DEFAULT 'open', -- open, dispatched
+    created_by TEXT NOT NULL,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+
+    FOREIGN KEY (center_id) REFERENCES collection_centers(id)
+);
+
+-- A collection goes into at most one lot
+CREATE TABLE IF NOT EXISTS lot_collections (
+    collection_id TEXT PRIMARY KEY,
+    lot_id TEXT NOT NULL,
+
+    FOREIGN KEY (collection_id) REFERENCES collections(id),
+    FOREIGN KEY (lot_id) REFERENCES lots(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lot_collections_lot_id ON lot_collections(lot_id);
+
+-- Each leg of the lot's journey, with the weight sent and the weight that
+-- arrived. Shrinkage is the difference.
+CREATE TABLE IF NOT EXISTS lot_transfers (
+    id TEXT PRIMARY KEY,
+
+    lot_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    from_name TEXT NOT NULL,
+    to_kind TEXT NOT NULL,  -- center, factory, buyer
+    to_name TEXT NOT NULL,
+    weight_out_kg REAL NOT NULL,
+    weight_in_kg REAL,      -- NULL while in transit
+    dispatched_by TEXT NOT NULL,
+    received_by TEXT,
+
+    dispatched_at TEXT NOT NULL,
+    received_at TEXT,
+
+    FOREIGN KEY (lot_id) REFERENCES lots(id),
+    UNIQUE (lot_id, position)
+);
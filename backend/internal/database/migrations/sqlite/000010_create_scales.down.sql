@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_collections_scale_reading_id;
+ALTER TABLE collections DROP COLUMN scale_reading_id;
+
+DROP TABLE IF EXISTS scale_readings;
+DROP TABLE IF EXISTS scales;
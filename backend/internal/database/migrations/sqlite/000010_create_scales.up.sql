@@ -0,0 +1,42 @@
+-- Registered digital scales. Each has a shared secret used to sign readings.
+CREATE TABLE IF NOT EXISTS scales (
+    id TEXT PRIMARY KEY,
+
+    serial TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    center_id TEXT,
+    secret TEXT NOT NULL,
+    active INTEGER NOT NULL DEFAULT 1,
+    last_sequence INTEGER NOT NULL DEFAULT 0, -- highest accepted reading sequence
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+
+    FOREIGN KEY (center_id) REFERENCES collection_centers(id)
+);
+
+CREATE TABLE IF NOT EXISTS scale_readings (
+    id TEXT PRIMARY KEY,
+
+    scale_id TEXT NOT NULL,
+    sequence INTEGER NOT NULL,
+    gross_kg REAL NOT NULL,
+    tare_kg REAL NOT NULL,
+    net_kg REAL NOT NULL,
+    measured_at TEXT NOT NULL,
+    signature TEXT NOT NULL,
+    submitted_by TEXT NOT NULL,
+
+    created_at TEXT NOT NULL,
+
+    FOREIGN KEY (scale_id) REFERENCES scales(id),
+    UNIQUE (scale_id, sequence)
+);
+
+-- A reading backs at most one collection
+ALTER TABLE collections ADD COLUMN scale_reading_id TEXT REFERENCES scale_readings(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_scale_reading_id
+    ON collections(scale_reading_id) WHERE scale_reading_id IS NOT NULL;
@@ -0,0 +1,33 @@
+-- Photos and documents attached to a collection or dispute. Content lives in
+-- the blob store under its sha256, so identical files are stored once.
+CREATE TABLE IF NOT EXISTS attachments (
+    id TEXT PRIMARY KEY,
+
+    owner_type TEXT NOT NULL, -- collection, dispute
+    owner_id TEXT NOT NULL,
+    sha256 TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    mime_type TEXT NOT NULL,
+    filename TEXT NOT NULL DEFAULT '',
+    uploaded_by TEXT NOT NULL,
+
+    created_at TEXT NOT NULL,
+
+    UNIQUE (owner_type, owner_id, sha256)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_owner ON attachments(owner_type, owner_id);
+
+-- Resumable uploads in progress. The bytes received so far are staged on
+-- disk; the offset is the staged file's size.
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id TEXT PRIMARY KEY,
+
+    owner_type TEXT NOT NULL,
+    owner_id TEXT NOT NULL,
+    filename TEXT NOT NULL DEFAULT '',
+    size_bytes INTEGER NOT NULL, -- declared total size
+    created_by TEXT NOT NULL,
+
+    created_at TEXT NOT NULL
+);
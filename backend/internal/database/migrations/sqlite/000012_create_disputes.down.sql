@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_notifications_user_id;
+DROP TABLE IF EXISTS notifications;
+
+ALTER TABLE collection_adjustments DROP COLUMN new_weight_kg;
+ALTER TABLE collection_adjustments DROP COLUMN previous_weight_kg;
+
+DROP INDEX IF EXISTS idx_disputes_open_collection;
+DROP INDEX IF EXISTS idx_disputes_collector_id;
+DROP INDEX IF EXISTS idx_disputes_status;
+DROP TABLE IF EXISTS disputes;
@@ -0,0 +1,69 @@
+-- Back to RFC3339 to the second.
+UPDATE farmers SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE collectors SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE collections SET
+    location_captured_at = strftime('%Y-%m-%dT%H:%M:%SZ', location_captured_at),
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE prices SET
+    effective_from = strftime('%Y-%m-%dT%H:%M:%SZ', effective_from),
+    effective_to = strftime('%Y-%m-%dT%H:%M:%SZ', effective_to),
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE grades SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE collection_adjustments SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at);
+UPDATE crop_types SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE units SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE unit_conversions SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE collection_centers SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE routes SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE farms SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at),
+    deleted_at = strftime('%Y-%m-%dT%H:%M:%SZ', deleted_at);
+UPDATE plots SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at),
+    deleted_at = strftime('%Y-%m-%dT%H:%M:%SZ', deleted_at);
+UPDATE lots SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE lot_transfers SET
+    dispatched_at = strftime('%Y-%m-%dT%H:%M:%SZ', dispatched_at),
+    received_at = strftime('%Y-%m-%dT%H:%M:%SZ', received_at);
+UPDATE scales SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE scale_readings SET
+    measured_at = strftime('%Y-%m-%dT%H:%M:%SZ', measured_at),
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at);
+UPDATE attachments SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at);
+UPDATE upload_sessions SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at);
+UPDATE disputes SET
+    resolved_at = strftime('%Y-%m-%dT%H:%M:%SZ', resolved_at),
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at);
+UPDATE notifications SET
+    read_at = strftime('%Y-%m-%dT%H:%M:%SZ', read_at),
+    created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at);
+UPDATE audit_log SET
+    at = strftime('%Y-%m-%dT%H:%M:%SZ', at);
@@ -0,0 +1,74 @@
+-- Rewrite every timestamp in the one layout the repositories use: UTC,
+-- millisecond precision, fixed width (2026-02-14T08:30:00.123Z), so text
+-- comparison and ORDER BY agree with time order. Earlier rows mixed RFC3339
+-- to the second with the sqlite3 driver's own format for collections.
+-- A value SQLite cannot read becomes NULL and fails the NOT NULL columns
+-- rather than being silently dropped.
+UPDATE farmers SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE collectors SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE collections SET
+    location_captured_at = strftime('%Y-%m-%dT%H:%M:%fZ', location_captured_at),
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE prices SET
+    effective_from = strftime('%Y-%m-%dT%H:%M:%fZ', effective_from),
+    effective_to = strftime('%Y-%m-%dT%H:%M:%fZ', effective_to),
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE grades SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE collection_adjustments SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at);
+UPDATE crop_types SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE units SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE unit_conversions SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE collection_centers SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE routes SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE farms SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at),
+    deleted_at = strftime('%Y-%m-%dT%H:%M:%fZ', deleted_at);
+UPDATE plots SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at),
+    deleted_at = strftime('%Y-%m-%dT%H:%M:%fZ', deleted_at);
+UPDATE lots SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE lot_transfers SET
+    dispatched_at = strftime('%Y-%m-%dT%H:%M:%fZ', dispatched_at),
+    received_at = strftime('%Y-%m-%dT%H:%M:%fZ', received_at);
+UPDATE scales SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE scale_readings SET
+    measured_at = strftime('%Y-%m-%dT%H:%M:%fZ', measured_at),
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at);
+UPDATE attachments SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at);
+UPDATE upload_sessions SET
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at);
+UPDATE disputes SET
+    resolved_at = strftime('%Y-%m-%dT%H:%M:%fZ', resolved_at),
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at),
+    updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', updated_at);
+UPDATE notifications SET
+    read_at = strftime('%Y-%m-%dT%H:%M:%fZ', read_at),
+    created_at = strftime('%Y-%m-%dT%H:%M:%fZ', created_at);
+UPDATE audit_log SET
+    at = strftime('%Y-%m-%dT%H:%M:%fZ', at);
@@ -0,0 +1,8 @@
+-- A single row the readiness check rewrites, to prove the database takes
+-- writes.
+CREATE TABLE IF NOT EXISTS health_probe (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    probed_at TEXT NOT NULL
+);
+
+INSERT OR IGNORE INTO health_probe (id, probed_at) VALUES (1, '1970-01-01T00:00:00.000Z');
@@ -0,0 +1,12 @@
+-- Clerks review disputes. They are added by admins, not by signup.
+CREATE TABLE IF NOT EXISTS clerks (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    phone TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"agri-sync-backend/internal/config"
+
+	"github.com/lib/pq"
+)
+
+// ConnectPostgres opens a PostgreSQL database. Sessions run in UTC, and
+// SQLite-style "?" placeholders are rewritten to PostgreSQL's "$1" form, so
+// the repositories that are not dialect specific run unchanged.
+func ConnectPostgres(dsn string) (*DB, error) {
+	if dsn == "" {
+		return nil, errors.New("AGRISYNC_DATABASE_URL is required for the postgres driver")
+	}
+
+	connector, err := pq.NewConnector(withUTC(dsn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL DSN: %w", err)
+	}
+
+	db := sql.OpenDB(rebindConnector{connector})
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	return &DB{DB: db, driver: config.DriverPostgres}, nil
+}
+
+// withUTC sets the session time zone unless the DSN already does.
+// Both URL and key=value DSNs are accepted.
+func withUTC(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn // let pq report it
+		}
+		q := u.Query()
+		if q.Get("timezone") == "" {
+			q.Set("timezone", "UTC")
+		}
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	if strings.Contains(dsn, "timezone=") {
+		return dsn
+	}
+	return strings.TrimSpace(dsn + " timezone=UTC")
+}
+
+// rebind rewrites SQLite placeholders as PostgreSQL's: ?NNN becomes $NNN
+// and, as in SQLite, a bare ? takes the number after the largest used so
+// far. Quoted strings, quoted identifiers and -- comments are copied as
+// they are. PostgreSQL's own ? operators (jsonb) cannot be used as a result.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	highest := 0
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"':
+			end := strings.IndexByte(query[i+1:], ch)
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+		case ch == '?':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			n := highest + 1
+			if j > i+1 {
+				n, _ = strconv.Atoi(query[i+1 : j])
+			}
+			if n > highest {
+				highest = n
+			}
+			b.WriteString("$" + strconv.Itoa(n))
+			i = j - 1
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// pqConn is what database/sql uses of a lib/pq connection.
+type pqConn interface {
+	driver.Conn
+	driver.ConnPrepareContext
+	driver.ConnBeginTx
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+// rebindConnector hands out connections that rebind every statement.
+type rebindConnector struct {
+	driver.Connector
+}
+
+func (c rebindConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pc, ok := conn.(pqConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected PostgreSQL connection type %T", conn)
+	}
+	return rebindConn{pc}, nil
+}
+
+type rebindConn struct {
+	pqConn
+}
+
+func (c rebindConn) Prepare(query string) (driver.Stmt, error) {
+	return c.pqConn.Prepare(rebind(query))
+}
+
+func (c rebindConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.pqConn.PrepareContext(ctx, rebind(query))
+}
+
+func (c rebindConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.pqConn.ExecContext(ctx, rebind(query), args)
+}
+
+func (c rebindConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.pqConn.QueryContext(ctx, rebind(query), args)
+}
@@ -3,89 +3,83 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
-	"path/filepath"
+	"maps"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"agri-sync-backend/internal/config"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/sqlite3"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// ConnectSQLite opens a SQLite database and returns the *sql.DB instance.
-func ConnectSQLite(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open SQLite DB: %w", err)
-	}
-
-	// Enable foreign key enforcement
-	if _, err := db.Exec("PRAGMA foreign_keys = ON;"); err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
-	}
+// SQLiteOptions tune the connections ConnectSQLite opens.
+type SQLiteOptions struct {
+	// Synchronous is PRAGMA synchronous: OFF, NORMAL, FULL or EXTRA. In WAL
+	// mode NORMAL only risks the last commits on power loss, never
+	// corruption.
+	Synchronous string
+	// BusyTimeout is how long a connection waits for a lock held by another
+	// connection or process before failing with "database is locked".
+	BusyTimeout time.Duration
+	// ReadConns caps the read-only pool.
+	ReadConns int
+}
 
-	return db, nil
+// DefaultSQLiteOptions match the defaults of the AGRISYNC_SQLITE_*
+// settings, for tools that do not load the config.
+var DefaultSQLiteOptions = SQLiteOptions{
+	Synchronous: "NORMAL",
+	BusyTimeout: 5 * time.Second,
+	ReadConns:   4,
 }
 
-// RunMigrations runs SQL migrations from the given folder
-func RunMigrations(db *sql.DB, migrationsDir string) error {
-	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+// ConnectSQLite opens a SQLite database in WAL mode as two pools: a single
+// writer, so concurrent writes queue instead of failing with "database is
+// locked", and ReadConns read-only connections, which WAL lets read while
+// the writer writes. The pragmas are set in the DSN, so the driver applies
+// them to every connection the pools open.
+func ConnectSQLite(dbPath string, opts SQLiteOptions) (*DB, error) {
+	pragmas := url.Values{}
+	pragmas.Set("_foreign_keys", "on")
+	pragmas.Set("_busy_timeout", strconv.FormatInt(opts.BusyTimeout.Milliseconds(), 10))
+
+	writerPragmas := maps.Clone(pragmas)
+	writerPragmas.Set("_journal_mode", "WAL")
+	writerPragmas.Set("_synchronous", opts.Synchronous)
+	// Transactions take the write lock up front (BEGIN IMMEDIATE) rather
+	// than at their first write, so they cannot hit a lock
+	// conflict halfway through.
+	writerPragmas.Set("_txlock", "immediate")
+
+	writer, err := sql.Open("sqlite3", sqliteDSN(dbPath, writerPragmas))
 	if err != nil {
-		return fmt.Errorf("failed to create migrate driver: %w", err)
+		return nil, fmt.Errorf("failed to open SQLite DB: %w", err)
 	}
-
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", filepath.ToSlash(migrationsDir)),
-		"sqlite3", driver)
-	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+	writer.SetMaxOpenConns(1)
+	// The first connection creates the file and switches it to WAL, which
+	// the read-only connections cannot do themselves.
+	if err := writer.Ping(); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open SQLite DB: %w", err)
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("migration failed: %w", err)
+	pragmas.Set("mode", "ro")
+	reader, err := sql.Open("sqlite3", sqliteDSN(dbPath, pragmas))
+	if err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open SQLite DB: %w", err)
 	}
+	reader.SetMaxOpenConns(opts.ReadConns)
+	reader.SetMaxIdleConns(opts.ReadConns)
 
-	log.Println("✅ Migrations applied successfully")
-	return nil
+	return &DB{DB: writer, reader: reader, driver: config.DriverSQLite}, nil
 }
 
-func RunMigrationsDown(db *sql.DB, migrationsDir string) error {
-    driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
-    if err != nil {
-        return fmt.Errorf("failed to create migrate driver: %w", err)
-    }
-
-    m, err := migrate.NewWithDatabaseInstance(
-        fmt.Sprintf("file://%s", filepath.ToSlash(migrationsDir)),
-        "sqlite3", driver)
-    if err != nil {
-        return fmt.Errorf("failed to create migrate instance: %w", err)
-    }
-
-    if err := m.Steps(-1); err != nil && err != migrate.ErrNoChange {
-        return fmt.Errorf("migration down failed: %w", err)
-    }
-
-    return nil
+// sqliteDSN builds a file: URI, escaping the characters that would end the
+// path early.
+func sqliteDSN(path string, params url.Values) string {
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(path)
+	return "file:" + escaped + "?" + params.Encode()
 }
-
-func MigrationStatus(db *sql.DB, migrationsDir string) (version uint, dirty bool, err error) {
-    driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
-    if err != nil {
-        return 0, false, fmt.Errorf("failed to create migrate driver: %w", err)
-    }
-
-    m, err := migrate.NewWithDatabaseInstance(
-        fmt.Sprintf("file://%s", filepath.ToSlash(migrationsDir)),
-        "sqlite3", driver)
-    if err != nil {
-        return 0, false, fmt.Errorf("failed to create migrate instance: %w", err)
-    }
-
-    version, dirty, err = m.Version()
-    if err != nil && err != migrate.ErrNilVersion {
-        return 0, false, fmt.Errorf("failed to get migration version: %w", err)
-    }
-
-    return version, dirty, nil
-}
\ No newline at end of file
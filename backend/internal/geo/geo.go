@@ -0,0 +1,166 @@
+// Package geo holds the small amount of geometry the API needs for
+// geotagged collections and farm boundaries.
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const earthRadiusM = 6371000.0
+
+// Point is a WGS84 coordinate in decimal degrees.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// DistanceM returns the great-circle distance between two points in metres.
+func DistanceM(a, b Point) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusM * math.Asin(math.Sqrt(h))
+}
+
+// WithinAny reports whether p lies within radiusM of at least one of the
+// reference points.
+func WithinAny(p Point, refs []Point, radiusM float64) bool {
+	for _, ref := range refs {
+		if DistanceM(p, ref) <= radiusM {
+			return true
+		}
+	}
+	return false
+}
+
+// BoundingBox is an axis-aligned lat/lng rectangle.
+type BoundingBox struct {
+	MinLat, MinLng, MaxLat, MaxLng float64
+}
+
+// Polygon is a GeoJSON polygon: an outer ring followed by optional holes.
+// Rings are closed (first position repeated last).
+type Polygon [][]Point
+
+// ParsePolygon decodes and validates a GeoJSON Polygon geometry.
+func ParsePolygon(raw []byte) (Polygon, error) {
+	var g struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	if g.Type != "Polygon" {
+		return nil, fmt.Errorf("boundary must be a GeoJSON Polygon, got %q", g.Type)
+	}
+	var rings [][][2]float64
+	if err := json.Unmarshal(g.Coordinates, &rings); err != nil {
+		return nil, fmt.Errorf("invalid polygon coordinates: %w", err)
+	}
+	if len(rings) == 0 {
+		return nil, errors.New("polygon has no rings")
+	}
+
+	poly := make(Polygon, 0, len(rings))
+	for _, ring := range rings {
+		if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+			return nil, errors.New("polygon rings must be closed and have at least 4 positions")
+		}
+		points := make([]Point, len(ring))
+		for i, pos := range ring {
+			// GeoJSON positions are [longitude, latitude]
+			if pos[0] < -180 || pos[0] > 180 || pos[1] < -90 || pos[1] > 90 {
+				return nil, errors.New("polygon position out of range")
+			}
+			points[i] = Point{Lat: pos[1], Lng: pos[0]}
+		}
+		poly = append(poly, points)
+	}
+	return poly, nil
+}
+
+// Contains reports whether p lies inside the outer ring and outside every hole.
+func (poly Polygon) Contains(p Point) bool {
+	if len(poly) == 0 || !ringContains(poly[0], p) {
+		return false
+	}
+	for _, hole := range poly[1:] {
+		if ringContains(hole, p) {
+			return false
+		}
+	}
+	return true
+}
+
+func ringContains(ring []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+const sqMetresPerAcre = 4046.8564224
+
+// AreaAcres approximates the polygon's area. Farms are small enough that an
+// equirectangular projection around the ring is accurate to well under 1%.
+func (poly Polygon) AreaAcres() float64 {
+	area := 0.0
+	for i, ring := range poly {
+		a := ringAreaM2(ring)
+		if i == 0 {
+			area += a
+		} else {
+			area -= a
+		}
+	}
+	return area / sqMetresPerAcre
+}
+
+func ringAreaM2(ring []Point) float64 {
+	lat0 := ring[0].Lat * math.Pi / 180
+	sum := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		x1 := ring[i].Lng * math.Pi / 180 * math.Cos(lat0) * earthRadiusM
+		y1 := ring[i].Lat * math.Pi / 180 * earthRadiusM
+		x2 := ring[i+1].Lng * math.Pi / 180 * math.Cos(lat0) * earthRadiusM
+		y2 := ring[i+1].Lat * math.Pi / 180 * earthRadiusM
+		sum += x1*y2 - x2*y1
+	}
+	return math.Abs(sum) / 2
+}
+
+// Fence is the set of places a delivery may legitimately be recorded from:
+// points (farms, centers) with a radius, and farm or plot boundaries.
+type Fence struct {
+	Points   []Point
+	Polygons []Polygon
+}
+
+// Empty reports whether there is nothing to check against.
+func (f *Fence) Empty() bool {
+	return len(f.Points) == 0 && len(f.Polygons) == 0
+}
+
+// Allows reports whether p is inside any boundary or within radiusM of any
+// point.
+func (f *Fence) Allows(p Point, radiusM float64) bool {
+	for _, poly := range f.Polygons {
+		if poly.Contains(p) {
+			return true
+		}
+	}
+	return WithinAny(p, f.Points, radiusM)
+}
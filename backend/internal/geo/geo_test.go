@@ -0,0 +1,175 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// square is a closed GeoJSON ring around (lat, lng) with the given half
+// side in degrees.
+func square(lat, lng, half float64) []Point {
+	return []Point{
+		{Lat: lat - half, Lng: lng - half},
+		{Lat: lat - half, Lng: lng + half},
+		{Lat: lat + half, Lng: lng + half},
+		{Lat: lat + half, Lng: lng - half},
+		{Lat: lat - half, Lng: lng - half},
+	}
+}
+
+func TestDistanceM(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64 // metres, to within 0.5%
+	}{
+		{"same point", Point{-0.5, 37}, Point{-0.5, 37}, 0},
+		{"1 degree of latitude", Point{0, 37}, Point{1, 37}, 111195},
+		{"1 degree of longitude at the equator", Point{0, 36}, Point{0, 37}, 111195},
+		{"1 degree of longitude at 60°", Point{60, 36}, Point{60, 37}, 55597},
+		{"across the antimeridian", Point{0, 179.5}, Point{0, -179.5}, 111195},
+		{"Nairobi to Nyeri", Point{-1.2864, 36.8172}, Point{-0.4167, 36.9500}, 97900},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DistanceM(tt.a, tt.b)
+			if math.Abs(got-tt.want) > tt.want*0.005+0.001 {
+				t.Errorf("DistanceM(%v, %v) = %.0f m, want %.0f m", tt.a, tt.b, got, tt.want)
+			}
+			if back := DistanceM(tt.b, tt.a); math.Abs(back-got) > 1e-6 {
+				t.Errorf("DistanceM is not symmetric: %.3f and %.3f", got, back)
+			}
+		})
+	}
+}
+
+func TestWithinAny(t *testing.T) {
+	refs := []Point{{Lat: 0, Lng: 37}, {Lat: 1, Lng: 37}}
+	tests := []struct {
+		name    string
+		p       Point
+		radiusM float64
+		refs    []Point
+		want    bool
+	}{
+		{"at a reference", Point{0, 37}, 1, refs, true},
+		{"inside the radius of the second", Point{1.004, 37}, 500, refs, true},
+		{"just outside every radius", Point{0.5, 37}, 500, refs, false},
+		{"on the edge", Point{0, 37}, DistanceM(Point{0, 37}, Point{1, 37}), refs[1:], true},
+		{"no references", Point{0, 37}, 1e7, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithinAny(tt.p, tt.refs, tt.radiusM); got != tt.want {
+				t.Errorf("WithinAny(%v, %v, %v) = %v, want %v", tt.p, tt.refs, tt.radiusM, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePolygon(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		rings   int
+		wantErr bool
+	}{
+		{"square", `{"type":"Polygon","coordinates":[[[37,0],[37.01,0],[37.01,0.01],[37,0.01],[37,0]]]}`, 1, false},
+		{"with a hole", `{"type":"Polygon","coordinates":[
+			[[37,0],[37.01,0],[37.01,0.01],[37,0.01],[37,0]],
+			[[37.004,0.004],[37.006,0.004],[37.006,0.006],[37.004,0.006],[37.004,0.004]]]}`, 2, false},
+		{"not JSON", `{`, 0, true},
+		{"a point", `{"type":"Point","coordinates":[37,0]}`, 0, true},
+		{"no rings", `{"type":"Polygon","coordinates":[]}`, 0, true},
+		{"open ring", `{"type":"Polygon","coordinates":[[[37,0],[37.01,0],[37.01,0.01],[37,0.01]]]}`, 0, true},
+		{"too few positions", `{"type":"Polygon","coordinates":[[[37,0],[37.01,0],[37,0]]]}`, 0, true},
+		{"latitude out of range", `{"type":"Polygon","coordinates":[[[37,0],[37.01,0],[37.01,91],[37,0]]]}`, 0, true},
+		{"longitude out of range", `{"type":"Polygon","coordinates":[[[37,0],[181,0],[37.01,0.01],[37,0]]]}`, 0, true},
+		{"bad coordinates", `{"type":"Polygon","coordinates":"here"}`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poly, err := ParsePolygon([]byte(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePolygon: err = %v, want error %v", err, tt.wantErr)
+			}
+			if len(poly) != tt.rings {
+				t.Errorf("ParsePolygon: %d rings, want %d", len(poly), tt.rings)
+			}
+		})
+	}
+
+	// GeoJSON positions are [longitude, latitude]
+	poly, err := ParsePolygon([]byte(`{"type":"Polygon","coordinates":[[[37,0],[37.01,0],[37.01,0.01],[37,0.01],[37,0]]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := poly[0][1]; got != (Point{Lat: 0, Lng: 37.01}) {
+		t.Errorf("second position = %v, want lat 0 lng 37.01", got)
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	withHole := Polygon{square(0, 37, 0.01), square(0, 37, 0.002)}
+	tests := []struct {
+		name string
+		poly Polygon
+		p    Point
+		want bool
+	}{
+		{"inside", Polygon{square(0, 37, 0.01)}, Point{0.005, 37.005}, true},
+		{"outside", Polygon{square(0, 37, 0.01)}, Point{0.02, 37}, false},
+		{"outside to the west", Polygon{square(0, 37, 0.01)}, Point{0, 36.98}, false},
+		{"inside the ring, in the hole", withHole, Point{0, 37}, false},
+		{"inside the ring, beside the hole", withHole, Point{0.005, 37}, true},
+		{"empty polygon", Polygon{}, Point{0, 37}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.poly.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygonAreaAcres(t *testing.T) {
+	// About 100 m a side at the equator: 0.0009° of latitude is 100 m.
+	side := 100 / 111195.0
+	poly := Polygon{square(0, 37, side/2)}
+	if got, want := poly.AreaAcres(), 10000/sqMetresPerAcre; math.Abs(got-want) > want*0.01 {
+		t.Errorf("AreaAcres = %.4f, want %.4f", got, want)
+	}
+	withHole := Polygon{square(0, 37, side/2), square(0, 37, side/4)}
+	if got, want := withHole.AreaAcres(), 7500/sqMetresPerAcre; math.Abs(got-want) > want*0.01 {
+		t.Errorf("AreaAcres with a hole = %.4f, want %.4f", got, want)
+	}
+}
+
+func TestFenceAllows(t *testing.T) {
+	farm := Point{Lat: 0, Lng: 37}
+	fence := Fence{Points: []Point{farm}, Polygons: []Polygon{{square(1, 37, 0.01)}}}
+	tests := []struct {
+		name    string
+		fence   Fence
+		p       Point
+		radiusM float64
+		want    bool
+	}{
+		{"near the point", fence, Point{0.001, 37}, 500, true},
+		{"inside the boundary, far from the point", fence, Point{1.005, 37.005}, 500, true},
+		{"outside both", fence, Point{0.5, 37}, 500, false},
+		{"outside both, but a wider radius", fence, Point{0.005, 37}, 600, true},
+		{"empty fence", Fence{}, Point{0, 37}, 500, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fence.Allows(tt.p, tt.radiusM); got != tt.want {
+				t.Errorf("Allows(%v, %v) = %v, want %v", tt.p, tt.radiusM, got, tt.want)
+			}
+		})
+	}
+	if !(&Fence{}).Empty() || fence.Empty() {
+		t.Errorf("Empty: got %v for an empty fence and %v for %v", (&Fence{}).Empty(), fence.Empty(), fence)
+	}
+}
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/blob"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// allowedAttachmentTypes are the content types accepted as evidence. The
+// type is sniffed from the bytes, not taken from the client.
+var allowedAttachmentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+	"image/heic",
+	"application/pdf",
+}
+
+var errUnsupportedType = errors.New("unsupported attachment type")
+
+type UploadRequest struct {
+	Filename  string `json:"filename"`
+	SizeBytes int64  `json:"size_bytes" binding:"required,gt=0"`
+}
+
+// authorizeAttachmentOwner applies the owner's visibility rules to the
+// current user: for collections, those of GetCollection; for disputes, those
+// of GetDispute. It returns nil when access is allowed, otherwise the error
+// to respond with.
+func authorizeAttachmentOwner(c *gin.Context, ownerType, ownerID string, collectionRepo repository.CollectionStore, disputeRepo *repository.DisputeRepository) *apierr.Error {
+	roleVal, _ := c.Get("role")
+	userIDVal, _ := c.Get("userId")
+
+	switch ownerType {
+	case models.OwnerCollection:
+		collection, err := collectionRepo.GetByID(c.Request.Context(), ownerID)
+		if err != nil {
+			return apierr.From(err, "Failed to load collection")
+		}
+		if roleVal == "farmer" && collection.FarmerID != userIDVal {
+			return apierr.New(apierr.Forbidden, "Not authorized to access this collection")
+		}
+		return nil
+
+	case models.OwnerDispute:
+		dispute, err := disputeRepo.GetByID(c.Request.Context(), ownerID)
+		if err != nil {
+			return apierr.From(err, "Failed to load dispute")
+		}
+		if !canViewDispute(c, dispute) {
+			return apierr.New(apierr.Forbidden, "Not authorized to access this dispute")
+		}
+		return nil
+	}
+	return apierr.New(apierr.NotFound, "Unknown attachment owner")
+}
+
+// storeStaged turns a fully staged upload into an attachment: it sniffs the
+// type, hashes the content, stores the blob once per hash, and returns an
+// existing attachment if the owner already has the same file. A new
+// attachment is audited. The staged file is removed whatever the outcome.
+func storeStaged(c *gin.Context, repo *repository.AttachmentRepository, auditRepo *repository.AuditRepository, store blob.BlobStore, staging *blob.Staging, stagedID string, a *models.Attachment) (*models.Attachment, bool, error) {
+	defer staging.Remove(stagedID)
+	ctx := c.Request.Context()
+	f, err := staging.Open(stagedID)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	mtype, err := mimetype.DetectReader(f)
+	if err != nil {
+		return nil, false, err
+	}
+	if !slices.Contains(allowedAttachmentTypes, mtype.String()) {
+		return nil, false, errUnsupportedType
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, false, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, false, err
+	}
+	a.SHA256 = hex.EncodeToString(h.Sum(nil))
+	a.SizeBytes = size
+	a.MimeType = mtype.String()
+
+	if existing, err := repo.FindByHash(ctx, a.OwnerType, a.OwnerID, a.SHA256); err == nil {
+		return existing, false, nil
+	} else if err != repository.ErrAttachmentNotFound {
+		return nil, false, err
+	}
+
+	exists, err := store.Exists(a.SHA256)
+	if err != nil {
+		return nil, false, err
+	}
+	if !exists {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, false, err
+		}
+		if err := store.Put(a.SHA256, f); err != nil {
+			return nil, false, err
+		}
+	}
+
+	err = audited(c, auditRepo, models.AuditCreate, "attachment", a.ID, nil, a, func(ctx context.Context) error {
+		return repo.Create(ctx, a)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return a, true, nil
+}
+
+// respondStored writes the result of storeStaged.
+func respondStored(c *gin.Context, a *models.Attachment, created bool, err error) {
+	switch {
+	case err == errUnsupportedType:
+		apierr.Abort(c, apierr.New(apierr.UnsupportedMediaType, "Attachment type not allowed").With("allowed", allowedAttachmentTypes))
+	case err != nil:
+		apierr.Abort(c, apierr.Internal(err, "Failed to store attachment"))
+	case created:
+		c.JSON(http.StatusCreated, a)
+	default:
+		// Same file already attached: hand back the existing record
+		c.JSON(http.StatusOK, a)
+	}
+}
+
+// UploadAttachment accepts a single multipart upload (form field "file").
+// Clients on poor connections should use CreateUpload instead.
+func UploadAttachment(c *gin.Context, ownerType string, repo *repository.AttachmentRepository, collectionRepo repository.CollectionStore, disputeRepo *repository.DisputeRepository, auditRepo *repository.AuditRepository, store blob.BlobStore, staging *blob.Staging, maxBytes int64) {
+	ownerID := c.Param("id")
+	if denied := authorizeAttachmentOwner(c, ownerType, ownerID, collectionRepo, disputeRepo); denied != nil {
+		apierr.Abort(c, denied)
+		return
+	}
+	userIDVal, _ := c.Get("userId")
+
+	// Leave room for the multipart envelope around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+64<<10)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierr.Abort(c, apierr.New(apierr.PayloadTooLarge, "File too large").With("max_bytes", maxBytes))
+			return
+		}
+		apierr.Abort(c, apierr.Invalid("file", apierr.RuleRequired, "file is required"))
+		return
+	}
+	if header.Size > maxBytes {
+		apierr.Abort(c, apierr.New(apierr.PayloadTooLarge, "File too large").With("max_bytes", maxBytes))
+		return
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		apierr.Abort(c, apierr.Invalid("file", apierr.RuleInvalid, "Failed to read file"))
+		return
+	}
+	defer src.Close()
+
+	stagedID := uuid.New().String()
+	if err := staging.Create(stagedID); err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to stage upload"))
+		return
+	}
+	if _, err := staging.Append(stagedID, 0, src, maxBytes); err != nil {
+		staging.Remove(stagedID)
+		apierr.Abort(c, apierr.Internal(err, "Failed to stage upload"))
+		return
+	}
+
+	a, created, err := storeStaged(c, repo, auditRepo, store, staging, stagedID, &models.Attachment{
+		ID:         uuid.New().String(),
+		OwnerType:  ownerType,
+		OwnerID:    ownerID,
+		Filename:   header.Filename,
+		UploadedBy: userIDVal.(string),
+	})
+	respondStored(c, a, created, err)
+}
+
+func ListAttachments(c *gin.Context, ownerType string, repo *repository.AttachmentRepository, collectionRepo repository.CollectionStore, disputeRepo *repository.DisputeRepository) {
+	ownerID := c.Param("id")
+	if denied := authorizeAttachmentOwner(c, ownerType, ownerID, collectionRepo, disputeRepo); denied != nil {
+		apierr.Abort(c, denied)
+		return
+	}
+
+	attachments, err := repo.ListByOwner(c.Request.Context(), ownerType, ownerID)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list attachments"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attachments": attachments,
+		"count":       len(attachments),
+	})
+}
+
+// DownloadAttachment streams an attachment to anyone allowed to see its owner.
+func DownloadAttachment(c *gin.Context, repo *repository.AttachmentRepository, collectionRepo repository.CollectionStore, disputeRepo *repository.DisputeRepository, store blob.BlobStore) {
+	a, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load attachment"))
+		return
+	}
+	if denied := authorizeAttachmentOwner(c, a.OwnerType, a.OwnerID, collectionRepo, disputeRepo); denied != nil {
+		apierr.Abort(c, denied)
+		return
+	}
+
+	rc, err := store.Open(a.SHA256)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Attachment content unavailable"))
+		return
+	}
+	defer rc.Close()
+
+	c.Header("ETag", `"`+a.SHA256+`"`)
+	c.DataFromReader(http.StatusOK, a.SizeBytes, a.MimeType, rc, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}),
+	})
+}
+
+// CreateUpload starts a resumable upload for an owner. The client then
+// sends the file in chunks with PATCH /uploads/:id.
+func CreateUpload(c *gin.Context, ownerType string, repo *repository.AttachmentRepository, collectionRepo repository.CollectionStore, disputeRepo *repository.DisputeRepository, auditRepo *repository.AuditRepository, staging *blob.Staging, maxBytes int64) {
+	ownerID := c.Param("id")
+	if denied := authorizeAttachmentOwner(c, ownerType, ownerID, collectionRepo, disputeRepo); denied != nil {
+		apierr.Abort(c, denied)
+		return
+	}
+	userIDVal, _ := c.Get("userId")
+
+	var req UploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if req.SizeBytes > maxBytes {
+		apierr.Abort(c, apierr.New(apierr.PayloadTooLarge, "File too large").With("max_bytes", maxBytes))
+		return
+	}
+
+	upload := &models.UploadSession{
+		ID:        uuid.New().String(),
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+		Filename:  req.Filename,
+		SizeBytes: req.SizeBytes,
+		CreatedBy: userIDVal.(string),
+	}
+	if err := staging.Create(upload.ID); err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to stage upload"))
+		return
+	}
+	err := audited(c, auditRepo, models.AuditCreate, "upload", upload.ID, nil, upload, func(ctx context.Context) error {
+		return repo.CreateUpload(ctx, upload)
+	})
+	if err != nil {
+		staging.Remove(upload.ID)
+		apierr.Abort(c, apierr.Internal(err, "Failed to create upload"))
+		return
+	}
+
+	c.Header("Location", "/uploads/"+upload.ID)
+	c.JSON(http.StatusCreated, upload)
+}
+
+// loadUpload fetches an upload owned by the current user and fills in its
+// offset. It writes the error response itself and returns nil on failure.
+func loadUpload(c *gin.Context, repo *repository.AttachmentRepository, staging *blob.Staging) *models.UploadSession {
+	upload, err := repo.GetUpload(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == repository.ErrUploadNotFound {
+			apierr.Abort(c, apierr.New(apierr.UploadNotFound, ""))
+			return nil
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load upload"))
+		return nil
+	}
+	userIDVal, _ := c.Get("userId")
+	if upload.CreatedBy != userIDVal {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not your upload"))
+		return nil
+	}
+	if upload.Offset, err = staging.Offset(upload.ID); err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to read upload"))
+		return nil
+	}
+	return upload
+}
+
+// GetUpload reports how much of an upload has arrived, so the client knows
+// where to resume. The offset is also sent as the Upload-Offset header, which
+// makes HEAD requests enough.
+func GetUpload(c *gin.Context, repo *repository.AttachmentRepository, staging *blob.Staging) {
+	upload := loadUpload(c, repo, staging)
+	if upload == nil {
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.SizeBytes, 10))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, upload)
+}
+
+// AppendUpload adds a chunk at the offset given in the Upload-Offset header.
+// When the last byte arrives the attachment is created and returned.
+func AppendUpload(c *gin.Context, repo *repository.AttachmentRepository, auditRepo *repository.AuditRepository, store blob.BlobStore, staging *blob.Staging) {
+	upload := loadUpload(c, repo, staging)
+	if upload == nil {
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		apierr.Abort(c, apierr.Invalid("Upload-Offset", apierr.RuleRequired, "Upload-Offset header is required"))
+		return
+	}
+
+	newOffset, err := staging.Append(upload.ID, offset, c.Request.Body, upload.SizeBytes-offset)
+	c.Header("Upload-Offset", strconv.FormatInt(newOffset, 10))
+	if err == blob.ErrOffsetMismatch {
+		apierr.Abort(c, apierr.New(apierr.UploadOffsetMismatch, "Upload-Offset does not match").With("offset", newOffset))
+		return
+	}
+	if err != nil {
+		// Whatever arrived before the connection dropped is kept
+		apierr.Abort(c, apierr.Internal(err, "Upload interrupted").With("offset", newOffset))
+		return
+	}
+
+	if newOffset < upload.SizeBytes {
+		upload.Offset = newOffset
+		c.JSON(http.StatusOK, upload)
+		return
+	}
+
+	a, created, err := storeStaged(c, repo, auditRepo, store, staging, upload.ID, &models.Attachment{
+		ID:         uuid.New().String(),
+		OwnerType:  upload.OwnerType,
+		OwnerID:    upload.OwnerID,
+		Filename:   upload.Filename,
+		UploadedBy: upload.CreatedBy,
+	})
+	// The staged bytes are gone either way, so a failed upload starts over
+	repo.DeleteUpload(c.Request.Context(), upload.ID)
+	respondStored(c, a, created, err)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// snapshot captures an entity's state as JSON before a handler modifies
+// it, for the audit log's "before".
+func snapshot(v any) json.RawMessage {
+	raw, err := json.Marshal(v)
+	if err != nil || string(raw) == "null" {
+		return nil
+	}
+	return raw
+}
+
+// auditEntry starts an entry for something the current request does.
+func auditEntry(c *gin.Context, action, entityType, entityID string) *models.AuditEntry {
+	return &models.AuditEntry{
+		ActorID:    c.GetString("userId"),
+		ActorRole:  c.GetString("role"),
+		DeviceID:   c.GetHeader("X-Device-ID"),
+		IP:         c.ClientIP(),
+		RequestID:  c.GetString("requestId"),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+}
+
+// audited saves a change the current request makes together with its audit
+// entry. change runs in a transaction and the entry is appended in the same
+// one, so if either fails neither is kept and the error is returned.
+// Repositories change calls with the ctx it is given take part in the
+// transaction. before is captured now and after once change returns, so
+// after can point at what change fills in.
+func audited(c *gin.Context, repo *repository.AuditRepository, action, entityType, entityID string, before, after any, change func(ctx context.Context) error) error {
+	entry := auditEntry(c, action, entityType, entityID)
+	entry.Before = snapshot(before)
+	return repo.Record(c.Request.Context(), func(ctx context.Context) (*models.AuditEntry, error) {
+		if err := change(ctx); err != nil {
+			return nil, err
+		}
+		entry.After = snapshot(after)
+		return entry, nil
+	})
+}
+
+// recordAudit appends an entry for something the current request did that
+// audited does not cover, such as a login, or a second change inside an
+// audited one (pass the ctx change is given). The caller fails the request
+// if it returns an error.
+func recordAudit(ctx context.Context, c *gin.Context, repo *repository.AuditRepository, action, entityType, entityID string, before, after any) error {
+	entry := auditEntry(c, action, entityType, entityID)
+	entry.Before = snapshot(before)
+	entry.After = snapshot(after)
+	return repo.Append(ctx, entry)
+}
+
+// ListAuditLog returns audit entries, newest first (admin only). Filters:
+// ?entity_type=, ?entity_id=, ?actor_id=, ?action=, ?request_id=, ?from=,
+// ?to=; page with ?before_seq= and ?limit=.
+func ListAuditLog(c *gin.Context, repo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can view the audit log"))
+		return
+	}
+
+	filter := repository.AuditFilter{
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		RequestID:  c.Query("request_id"),
+		Limit:      defaultAuditLimit,
+	}
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := c.Query(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				apierr.Abort(c, apierr.Invalid(name, apierr.RuleFormat, name+" must be an RFC3339 timestamp"))
+				return
+			}
+			*dst = parsed
+		}
+	}
+	if v := c.Query("before_seq"); v != "" {
+		seq, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || seq <= 0 {
+			apierr.Abort(c, apierr.Invalid("before_seq", apierr.RuleFormat, "before_seq must be a positive integer"))
+			return
+		}
+		filter.BeforeSeq = seq
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			apierr.Abort(c, apierr.Invalid("limit", apierr.RuleRange, "limit must be between 1 and "+strconv.Itoa(maxAuditLimit)))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := repo.List(c.Request.Context(), filter)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list audit log"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// VerifyAuditLog checks the audit log's hash chain (admin only).
+func VerifyAuditLog(c *gin.Context, repo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can verify the audit log"))
+		return
+	}
+
+	result, err := repo.Verify(c.Request.Context())
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to verify audit log"))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"agri-sync-backend/internal/apierr"
 	"agri-sync-backend/internal/auth"
+	"agri-sync-backend/internal/logging"
+	"agri-sync-backend/internal/metrics"
 	"agri-sync-backend/internal/models"
 	"agri-sync-backend/internal/repository"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -13,13 +17,29 @@ import (
 type LoginRequest struct {
 	Phone    string `json:"phone" binding:"required"`
 	Password string `json:"password" binding:"required"`
-	Role     string `json:"role" binding:"required,oneof=farmer collector admin"`
+	Role     string `json:"role" binding:"required,oneof=farmer collector clerk admin"`
 }
 
-func Login(c *gin.Context, farmerRepo *repository.FarmerRepository, collectorRepo *repository.CollectorRepository) {
+// rejectLogin records a failed login and answers with rejection, or with
+// an internal error if the failure cannot be audited. userID is empty when
+// the phone is unknown. The audit log keeps a digest of the phone, so
+// repeated attempts on one number can be told apart, and the logs keep
+// neither.
+func rejectLogin(c *gin.Context, auditRepo *repository.AuditRepository, req *LoginRequest, userID, reason string, rejection *apierr.Error) {
+	metrics.LoginFailure(req.Role, reason)
+	ctx := c.Request.Context()
+	logging.FromContext(ctx).InfoContext(ctx, "Login failed", "role", req.Role, "reason", reason, "user_id", userID)
+	if err := recordAudit(c.Request.Context(), c, auditRepo, models.AuditLoginFailed, req.Role, userID, nil, gin.H{"phone_digest": auth.PhoneDigest(req.Phone), "reason": reason}); err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to audit login"))
+		return
+	}
+	apierr.Abort(c, rejection)
+}
+
+func Login(c *gin.Context, farmerRepo repository.FarmerStore, collectorRepo repository.CollectorStore, clerkRepo *repository.ClerkRepository, auditRepo *repository.AuditRepository) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Abort(c, apierr.BadRequest(err))
 		return
 	}
 
@@ -28,49 +48,72 @@ func Login(c *gin.Context, farmerRepo *repository.FarmerRepository, collectorRep
 
 	switch req.Role {
 	case "farmer":
-		farmer, lookupErr := farmerRepo.GetByPhone(req.Phone)
+		farmer, lookupErr := farmerRepo.GetByPhone(c.Request.Context(), req.Phone)
+		if lookupErr != nil && !errors.Is(lookupErr, repository.ErrFarmerNotFound) {
+			// Not a failed login, so not audited as one
+			apierr.Abort(c, apierr.Internal(lookupErr, "Failed to look up farmer"))
+			return
+		}
 		if lookupErr != nil {
-			fmt.Printf("Farmer lookup failed for phone %s: %v\n", req.Phone, lookupErr) // debug
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid phone or password"})
+			rejectLogin(c, auditRepo, &req, "", "unknown phone", apierr.New(apierr.InvalidCredentials, ""))
 			return
 		}
 		userID = farmer.ID
 		storedHash = farmer.PasswordHash
 
-		// NOW print — after assignment
-		fmt.Printf("Farmer found - ID: %s, Stored hash (first 20 chars): %s...\n", farmer.ID, storedHash[:min(20, len(storedHash))])
-		fmt.Printf("Password check result: %v\n", auth.CheckPassword(req.Password, storedHash))
-
 	case "collector":
-		collector, lookupErr := collectorRepo.GetByPhone(req.Phone)
+		collector, lookupErr := collectorRepo.GetByPhone(c.Request.Context(), req.Phone)
+		if lookupErr != nil && !errors.Is(lookupErr, repository.ErrCollectorNotFound) {
+			apierr.Abort(c, apierr.Internal(lookupErr, "Failed to look up collector"))
+			return
+		}
 		if lookupErr != nil {
-			fmt.Printf("Collector lookup failed for phone %s: %v\n", req.Phone, lookupErr)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid phone or password"})
+			rejectLogin(c, auditRepo, &req, "", "unknown phone", apierr.New(apierr.InvalidCredentials, ""))
 			return
 		}
 		userID = collector.ID
 		storedHash = collector.PasswordHash
 
-		fmt.Printf("Collector found - ID: %s, Stored hash (first 20 chars): %s...\n", collector.ID, storedHash[:min(20, len(storedHash))])
-		fmt.Printf("Password check result: %v\n", auth.CheckPassword(req.Password, storedHash))
+	case "clerk":
+		clerk, lookupErr := clerkRepo.GetByPhone(c.Request.Context(), req.Phone)
+		if lookupErr != nil && !errors.Is(lookupErr, repository.ErrClerkNotFound) {
+			apierr.Abort(c, apierr.Internal(lookupErr, "Failed to look up clerk"))
+			return
+		}
+		if lookupErr != nil {
+			rejectLogin(c, auditRepo, &req, "", "unknown phone", apierr.New(apierr.InvalidCredentials, ""))
+			return
+		}
+		userID = clerk.ID
+		storedHash = clerk.PasswordHash
 
 	case "admin":
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin login not implemented yet"})
+		// Admins have no accounts; operators issue their tokens with
+		// `agrisync admin-token`
+		rejectLogin(c, auditRepo, &req, "", "admin login not implemented", apierr.New(apierr.Forbidden, "Admins sign in with a token from agrisync admin-token"))
 		return
 
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		apierr.Abort(c, apierr.Invalid("role", apierr.RuleOneOf, "Invalid role"))
 		return
 	}
 
 	if !auth.CheckPassword(req.Password, storedHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid phone or password"})
+		rejectLogin(c, auditRepo, &req, userID, "wrong password", apierr.New(apierr.InvalidCredentials, ""))
 		return
 	}
 
 	token, genErr := auth.GenerateJWT(userID, req.Role)
 	if genErr != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierr.Abort(c, apierr.Internal(genErr, "Failed to generate token"))
+		return
+	}
+
+	// The rest of this request acts as the user who just logged in
+	c.Set("userId", userID)
+	c.Set("role", req.Role)
+	if err := recordAudit(c.Request.Context(), c, auditRepo, models.AuditLogin, req.Role, userID, nil, nil); err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to audit login"))
 		return
 	}
 
@@ -82,13 +125,6 @@ func Login(c *gin.Context, farmerRepo *repository.FarmerRepository, collectorRep
 	})
 }
 
-// Helper for safe substring
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
 // ── Signup Farmer ──
 type CreateFarmerRequest struct {
 	Name     string `json:"name" binding:"required"`
@@ -96,16 +132,16 @@ type CreateFarmerRequest struct {
 	Password string `json:"password" binding:"required,min=8"`
 }
 
-func CreateFarmer(c *gin.Context, repo *repository.FarmerRepository) {
+func CreateFarmer(c *gin.Context, repo repository.FarmerStore, auditRepo *repository.AuditRepository) {
 	var req CreateFarmerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Abort(c, apierr.BadRequest(err))
 		return
 	}
 
 	hash, hashErr := auth.HashPassword(req.Password)
 	if hashErr != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierr.Abort(c, apierr.Internal(hashErr, "Failed to hash password"))
 		return
 	}
 
@@ -116,8 +152,11 @@ func CreateFarmer(c *gin.Context, repo *repository.FarmerRepository) {
 		PasswordHash: hash,
 	}
 
-	if err := repo.Create(farmer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create farmer: " + err.Error()})
+	err := audited(c, auditRepo, models.AuditCreate, "farmer", farmer.ID, nil, farmer, func(ctx context.Context) error {
+		return repo.Create(ctx, farmer)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create farmer"))
 		return
 	}
 
@@ -136,16 +175,16 @@ type CreateCollectorRequest struct {
 	Password string `json:"password" binding:"required,min=8"`
 }
 
-func CreateCollector(c *gin.Context, repo *repository.CollectorRepository) {
+func CreateCollector(c *gin.Context, repo repository.CollectorStore, auditRepo *repository.AuditRepository) {
 	var req CreateCollectorRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Abort(c, apierr.BadRequest(err))
 		return
 	}
 
 	hash, hashErr := auth.HashPassword(req.Password)
 	if hashErr != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierr.Abort(c, apierr.Internal(hashErr, "Failed to hash password"))
 		return
 	}
 
@@ -156,8 +195,11 @@ func CreateCollector(c *gin.Context, repo *repository.CollectorRepository) {
 		PasswordHash: hash,
 	}
 
-	if err := repo.Create(collector); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create collector: " + err.Error()})
+	err := audited(c, auditRepo, models.AuditCreate, "collector", collector.ID, nil, collector, func(ctx context.Context) error {
+		return repo.Create(ctx, collector)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create collector"))
 		return
 	}
 
@@ -167,4 +209,57 @@ func CreateCollector(c *gin.Context, repo *repository.CollectorRepository) {
 		"phone":   collector.Phone,
 		"message": "Collector created successfully",
 	})
+}
+
+// ── Create Clerk ── (admin only; clerks review disputes)
+type CreateClerkRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Phone    string `json:"phone" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+func CreateClerk(c *gin.Context, repo *repository.ClerkRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can add clerks"))
+		return
+	}
+
+	var req CreateClerkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	if _, err := repo.GetByPhone(c.Request.Context(), req.Phone); err == nil {
+		apierr.Abort(c, apierr.New(apierr.AlreadyExists, "A clerk with this phone already exists"))
+		return
+	}
+
+	hash, hashErr := auth.HashPassword(req.Password)
+	if hashErr != nil {
+		apierr.Abort(c, apierr.Internal(hashErr, "Failed to hash password"))
+		return
+	}
+
+	clerk := &models.Clerk{
+		ID:           uuid.New().String(),
+		Name:         req.Name,
+		Phone:        req.Phone,
+		PasswordHash: hash,
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "clerk", clerk.ID, nil, clerk, func(ctx context.Context) error {
+		return repo.Create(ctx, clerk)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create clerk"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":      clerk.ID,
+		"name":    clerk.Name,
+		"phone":   clerk.Phone,
+		"message": "Clerk created successfully",
+	})
 }
\ No newline at end of file
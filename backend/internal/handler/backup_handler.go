@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/backup"
+	"agri-sync-backend/internal/database"
+	"agri-sync-backend/internal/logging"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateBackup takes an online backup of the database (admin only) and
+// prunes old ones under the retention policy.
+func CreateBackup(c *gin.Context, db *database.DB, auditRepo *repository.AuditRepository, opts backup.Options) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can take backups"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	m, err := backup.Create(ctx, db, opts)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Backup failed", "error", err)
+		if m == nil {
+			apierr.Abort(c, apierr.Internal(err, "Backup failed"))
+			return
+		}
+	}
+
+	if err := recordAudit(c.Request.Context(), c, auditRepo, models.AuditCreate, "backup", m.File, nil, m); err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to audit backup"))
+		return
+	}
+	c.JSON(http.StatusCreated, m)
+}
+
+// ListBackups lists the backups on disk, newest first (admin only).
+func ListBackups(c *gin.Context, opts backup.Options) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can view backups"))
+		return
+	}
+
+	list, err := backup.List(opts.Dir)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list backups"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"backups": list,
+		"count":   len(list),
+	})
+}
+
+// VerifyBackup checks a backup file end to end (admin only): checksums,
+// decryption, SQLite's integrity check and schema version.
+func VerifyBackup(c *gin.Context, opts backup.Options) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can verify backups"))
+		return
+	}
+
+	name := c.Param("file")
+	if filepath.Base(name) != name || name == "." || name == ".." {
+		apierr.Abort(c, apierr.Invalid("file", apierr.RuleFormat, "Invalid backup name"))
+		return
+	}
+	m, err := backup.Verify(filepath.Join(opts.Dir, name), opts.Key)
+	if err != nil {
+		if errors.Is(err, backup.ErrNoManifest) || errors.Is(err, os.ErrNotExist) {
+			apierr.Abort(c, apierr.New(apierr.BackupNotFound, ""))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"valid": false, "file": name, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"valid": true, "file": name, "manifest": m})
+}
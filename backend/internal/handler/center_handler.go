@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CenterRequest struct {
+	Code      string   `json:"code" binding:"required"`
+	Name      string   `json:"name" binding:"required"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Region    string   `json:"region"`
+	Active    *bool    `json:"active"`
+}
+
+func ListCenters(c *gin.Context, repo *repository.CenterRepository) {
+	centers, err := repo.List(c.Request.Context())
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list centers"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"centers": centers,
+		"count":   len(centers),
+	})
+}
+
+func CreateCenter(c *gin.Context, repo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage centers"))
+		return
+	}
+
+	var req CenterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	center := &models.CollectionCenter{
+		ID:        uuid.New().String(),
+		Code:      req.Code,
+		Name:      req.Name,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Region:    req.Region,
+		Active:    req.Active == nil || *req.Active,
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "center", center.ID, nil, center, func(ctx context.Context) error {
+		return repo.Create(ctx, center)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create center"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, center)
+}
+
+func UpdateCenter(c *gin.Context, repo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage centers"))
+		return
+	}
+
+	var req CenterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	center, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load center"))
+		return
+	}
+
+	before := snapshot(center)
+	center.Code = req.Code
+	center.Name = req.Name
+	center.Latitude = req.Latitude
+	center.Longitude = req.Longitude
+	center.Region = req.Region
+	if req.Active != nil {
+		center.Active = *req.Active
+	}
+
+	err = audited(c, auditRepo, models.AuditUpdate, "center", center.ID, before, center, func(ctx context.Context) error {
+		return repo.Update(ctx, center)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to update center"))
+		return
+	}
+
+	c.JSON(http.StatusOK, center)
+}
@@ -1,7 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/geo"
+	"agri-sync-backend/internal/metrics"
 	"agri-sync-backend/internal/models"
 	"agri-sync-backend/internal/repository"
 
@@ -12,20 +23,79 @@ import (
 type CreateCollectionRequest struct {
 	FarmerID   string  `json:"farmer_id" binding:"required"`
 	CropType   string  `json:"crop_type" binding:"required"`
-	WeightKg   float64 `json:"weight_kg" binding:"required,gt=0"`
-	PricePerKg float64 `json:"price_per_kg" binding:"required,gt=0"`
+
+	// Quantity in Unit (defaults to the crop's registry unit).
+	// PricePerUnit is optional: looked up from the price catalog when omitted.
+	Quantity     float64 `json:"quantity" binding:"omitempty,gt=0"`
+	Unit         string  `json:"unit"`
+	PricePerUnit float64 `json:"price_per_unit" binding:"omitempty,gt=0"`
+
+	// Legacy clients send kilograms instead of quantity/unit.
+	WeightKg   float64 `json:"weight_kg" binding:"omitempty,gt=0"`
+	PricePerKg float64 `json:"price_per_kg" binding:"omitempty,gt=0"`
+
+	// Optional: where the delivery was made. The center's region is used for
+	// the catalog price unless Region is given.
+	CenterID string `json:"center_id"`
+	// Optional: the farmer's plot the produce came from.
+	PlotID string `json:"plot_id"`
+	// Optional: signed scale reading (POST /scales/readings) for the weight.
+	// Quantity defaults to its net weight in kg.
+	ScaleReadingID string `json:"scale_reading_id"`
+	// Optional: selects a regional catalog price.
+	Region string `json:"region"`
+
+	// Optional quality capture; codes must be defined in /grades for the crop.
+	Grade           string             `json:"grade"`
+	QualityReadings map[string]float64 `json:"quality_readings"`
+	RejectionReason string             `json:"rejection_reason"`
+
+	// Optional device geotag. Latitude and longitude go together.
+	Latitude           *float64   `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude          *float64   `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	LocationAccuracyM  *float64   `json:"location_accuracy_m" binding:"omitempty,gte=0"`
+	LocationCapturedAt *time.Time `json:"location_captured_at"`
+}
+
+// CollectionDeps are what CreateCollection checks a delivery against. The
+// router builds one at startup.
+type CollectionDeps struct {
+	Collections repository.CollectionStore
+	Farmers     repository.FarmerStore
+	Farms       *repository.FarmRepository
+	Centers     *repository.CenterRepository
+	CropTypes   *repository.CropTypeRepository
+	Units       *repository.UnitRepository
+	Prices      *repository.PriceRepository
+	Grades      *repository.GradeRepository
+	Scales      *repository.ScaleRepository
+	Audit       *repository.AuditRepository
+
+	PriceTolerance       float64
+	GeofenceRadiusM      float64
+	GeofenceMaxAccuracyM float64
+	ScaleToleranceKg     float64
 }
 
-func CreateCollection(c *gin.Context, repo *repository.CollectionRepository) {
+// CreateCollection records a delivery. The authoritative price comes from the
+// catalog: it fills in a missing price_per_unit, and a manually entered price
+// is rejected if it strays more than PriceTolerance from the catalog.
+// A delivery with a rejection reason is stored as rejected. A geotagged
+// delivery outside the farmer's farm boundaries and further than
+// GeofenceRadiusM from the farmer's location and the center is accepted but
+// flagged for review, as are one whose fix is less accurate than
+// GeofenceMaxAccuracyM and one whose weight differs from its scale reading
+// by more than ScaleToleranceKg.
+func CreateCollection(c *gin.Context, d *CollectionDeps) {
 	roleVal, exists := c.Get("role")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "role not found"})
+		apierr.Abort(c, apierr.New(apierr.Unauthenticated, "role not found"))
 		return
 	}
 	role := roleVal.(string)
 
 	if role != "collector" && role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only collectors and admins can create collections"})
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can create collections"))
 		return
 	}
 
@@ -34,35 +104,262 @@ func CreateCollection(c *gin.Context, repo *repository.CollectionRepository) {
 
 	var req CreateCollectionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		apierr.Abort(c, apierr.Invalid("longitude", apierr.RuleRequired, "latitude and longitude must be given together"))
+		return
+	}
+
+	// Places the delivery may legitimately have been made from
+	var fence geo.Fence
+
+	if req.CenterID != "" {
+		center, err := d.Centers.GetByID(c.Request.Context(), req.CenterID)
+		if err != nil {
+			if err == repository.ErrCenterNotFound {
+				apierr.Abort(c, apierr.Unprocessable("center_id", apierr.RuleUnknown, "Unknown collection center"))
+				return
+			}
+			apierr.Abort(c, apierr.Internal(err, "Failed to load collection center"))
+			return
+		}
+		if !center.Active {
+			apierr.Abort(c, apierr.Unprocessable("center_id", apierr.RuleInactive, "Collection center is closed"))
+			return
+		}
+		if req.Region == "" {
+			req.Region = center.Region
+		}
+		if center.Latitude != nil && center.Longitude != nil {
+			fence.Points = append(fence.Points, geo.Point{Lat: *center.Latitude, Lng: *center.Longitude})
+		}
+	}
+
+	if req.PlotID != "" {
+		plot, owner, err := d.Farms.GetPlot(c.Request.Context(), req.PlotID)
+		if err != nil {
+			if err == repository.ErrPlotNotFound {
+				apierr.Abort(c, apierr.Unprocessable("plot_id", apierr.RuleUnknown, "Unknown plot"))
+				return
+			}
+			apierr.Abort(c, apierr.Internal(err, "Failed to load plot"))
+			return
+		}
+		if owner != req.FarmerID {
+			apierr.Abort(c, apierr.Unprocessable("plot_id", apierr.RuleMismatch, "Plot does not belong to this farmer"))
+			return
+		}
+		if plot.CropType != repository.NormalizeCropCode(req.CropType) {
+			apierr.Abort(c, apierr.Unprocessable("plot_id", apierr.RuleMismatch, "Plot is registered for "+plot.CropType))
+			return
+		}
+	}
+
+	// Crop must be an active entry in the registry
+	req.CropType = repository.NormalizeCropCode(req.CropType)
+	cropType, err := d.CropTypes.GetByCode(c.Request.Context(), req.CropType)
+	if err != nil {
+		if err == repository.ErrCropTypeNotFound {
+			apierr.Abort(c, apierr.Unprocessable("crop_type", apierr.RuleUnknown, "Unknown crop type: "+req.CropType))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load crop type"))
+		return
+	}
+	if !cropType.Active {
+		apierr.Abort(c, apierr.Unprocessable("crop_type", apierr.RuleInactive, "Crop type is no longer accepted: "+req.CropType))
+		return
+	}
+	if req.Grade != "" && len(cropType.AllowedGrades) > 0 && !slices.Contains(cropType.AllowedGrades, req.Grade) {
+		apierr.Abort(c, apierr.Unprocessable("grade", apierr.RuleOneOf, "Grade is not allowed for this crop: "+req.Grade))
+		return
+	}
+
+	// Every quality code the collector used must be defined for the crop
+	type qualityCode struct {
+		kind  models.GradeKind
+		code  string
+		field string
+	}
+	checks := []qualityCode{{models.GradeKindGrade, req.Grade, "grade"}, {models.GradeKindRejection, req.RejectionReason, "rejection_reason"}}
+	for code := range req.QualityReadings {
+		checks = append(checks, qualityCode{models.GradeKindReading, code, "quality_readings." + code})
+	}
+	for _, check := range checks {
+		if check.code == "" {
+			continue
+		}
+		if _, err := d.Grades.GetByCode(c.Request.Context(), req.CropType, check.kind, check.code); err != nil {
+			if err == repository.ErrGradeNotFound {
+				apierr.Abort(c, apierr.Unprocessable(check.field, apierr.RuleUnknown, "Unknown "+string(check.kind)+" for this crop: "+check.code))
+				return
+			}
+			apierr.Abort(c, apierr.Internal(err, "Failed to load grade definitions"))
+			return
+		}
+	}
+
+	status := models.StatusPending
+	if req.RejectionReason != "" {
+		status = models.StatusRejected
+	}
+
+	var reading *models.ScaleReading
+	if req.ScaleReadingID != "" {
+		reading, err = d.Scales.GetReading(c.Request.Context(), req.ScaleReadingID)
+		if err != nil {
+			if err == repository.ErrReadingNotFound {
+				apierr.Abort(c, apierr.Unprocessable("scale_reading_id", apierr.RuleUnknown, "Unknown scale reading"))
+				return
+			}
+			apierr.Abort(c, apierr.Internal(err, "Failed to load scale reading"))
+			return
+		}
+		if reading.CollectionID != "" {
+			apierr.Abort(c, apierr.New(apierr.ReadingUsed, "Scale reading is already used by collection "+reading.CollectionID))
+			return
+		}
+		if req.Quantity == 0 && req.WeightKg == 0 {
+			req.Quantity, req.Unit = reading.NetKg, models.BaseUnit
+		}
+	}
+
+	// Legacy weight_kg clients map onto quantity in kg
+	if req.Quantity == 0 {
+		if req.WeightKg == 0 {
+			apierr.Abort(c, apierr.Invalid("quantity", apierr.RuleRequired, "quantity or weight_kg is required"))
+			return
+		}
+		req.Quantity, req.Unit, req.PricePerUnit = req.WeightKg, models.BaseUnit, req.PricePerKg
+	}
+	if req.Unit == "" {
+		req.Unit = cropType.Unit
+	}
+	toKg, err := d.Units.ToKg(c.Request.Context(), req.CropType, req.Unit)
+	if err != nil {
+		if err == repository.ErrNoConversion {
+			apierr.Abort(c, apierr.New(apierr.NoUnitConversion, "No kg conversion for unit "+req.Unit+" on this crop"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load unit conversion"))
+		return
+	}
+
+	catalogPerKg, err := catalogPricePerKg(c.Request.Context(), d.Prices, d.Units, req.CropType, req.Grade, req.Region, time.Now().UTC())
+	if err != nil && err != repository.ErrPriceNotFound {
+		apierr.Abort(c, apierr.Internal(err, "Failed to look up catalog price"))
+		return
+	}
+	hasCatalog := err == nil
+	catalogPrice := catalogPerKg * toKg
+
+	pricePerUnit := req.PricePerUnit
+	switch {
+	case !hasCatalog && pricePerUnit == 0 && status != models.StatusRejected:
+		apierr.Abort(c, apierr.Unprocessable("price_per_unit", apierr.RuleRequired, "No catalog price for this crop; price_per_unit is required"))
+		return
+	case hasCatalog && pricePerUnit == 0:
+		pricePerUnit = catalogPrice
+	case hasCatalog && math.Abs(pricePerUnit-catalogPrice) > catalogPrice*d.PriceTolerance:
+		apierr.Abort(c, apierr.Unprocessable("price_per_unit", apierr.RuleRange, "price_per_unit is outside the allowed tolerance of the catalog price").
+			With("catalog_price", catalogPrice).
+			With("unit", req.Unit).
+			With("tolerance", d.PriceTolerance))
 		return
 	}
 
+	flags := []string{}
+	if req.Latitude != nil {
+		farmer, err := d.Farmers.GetByID(c.Request.Context(), req.FarmerID)
+		if err != nil && !errors.Is(err, repository.ErrFarmerNotFound) {
+			apierr.Abort(c, apierr.Internal(err, "Failed to load farmer"))
+			return
+		}
+		if farmer != nil && farmer.Latitude != nil && farmer.Longitude != nil {
+			fence.Points = append(fence.Points, geo.Point{Lat: *farmer.Latitude, Lng: *farmer.Longitude})
+		}
+		farms, err := d.Farms.List(c.Request.Context(), req.FarmerID, time.Time{})
+		if err != nil {
+			apierr.Abort(c, apierr.Internal(err, "Failed to load farms"))
+			return
+		}
+		for _, farm := range farms {
+			boundaries := []json.RawMessage{farm.Boundary}
+			for _, plot := range farm.Plots {
+				boundaries = append(boundaries, plot.Boundary)
+			}
+			for _, b := range boundaries {
+				if poly, err := geo.ParsePolygon(b); err == nil {
+					fence.Polygons = append(fence.Polygons, poly)
+				}
+			}
+		}
+
+		// A poor GPS fix widens the fence rather than raising a false alarm,
+		// but only up to GeofenceMaxAccuracyM: a worse one is flagged, so a
+		// device can't switch the check off by reporting a huge accuracy.
+		radius := d.GeofenceRadiusM
+		if acc := req.LocationAccuracyM; acc != nil {
+			radius += min(*acc, d.GeofenceMaxAccuracyM)
+			if *acc > d.GeofenceMaxAccuracyM {
+				flags = append(flags, models.FlagLowAccuracy)
+			}
+		}
+		at := geo.Point{Lat: *req.Latitude, Lng: *req.Longitude}
+		if !fence.Empty() && !fence.Allows(at, radius) {
+			flags = append(flags, models.FlagOutsideGeofence)
+		}
+	}
+
+	if reading != nil && math.Abs(req.Quantity*toKg-reading.NetKg) > d.ScaleToleranceKg {
+		flags = append(flags, models.FlagScaleMismatch)
+	}
+
 	collection := &models.Collection{
 		ID:          uuid.New().String(),
 		FarmerID:    req.FarmerID,
 		CollectorID: collectorID,
+		CenterID:    req.CenterID,
+		PlotID:      req.PlotID,
+		ScaleReadingID: req.ScaleReadingID,
 		CropType:    req.CropType,
-		WeightKg:    req.WeightKg,
-		PricePerKg:  req.PricePerKg,
-		Status:      models.StatusPending,
+		Quantity:     req.Quantity,
+		Unit:         req.Unit,
+		PricePerUnit: pricePerUnit,
+		WeightKg:    req.Quantity * toKg,
+		PricePerKg:  pricePerUnit / toKg,
+		Grade:           req.Grade,
+		QualityReadings: req.QualityReadings,
+		RejectionReason: req.RejectionReason,
+		Latitude:           req.Latitude,
+		Longitude:          req.Longitude,
+		LocationAccuracyM:  req.LocationAccuracyM,
+		LocationCapturedAt: req.LocationCapturedAt,
+		Flags:              flags,
+		Status:      status,
 		Verified:    false,
 	}
 
-	if err := repo.Create(collection); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create collection: " + err.Error()})
+	err = audited(c, d.Audit, models.AuditCreate, "collection", collection.ID, nil, collection, func(ctx context.Context) error {
+		return d.Collections.Create(ctx, collection)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to create collection"))
 		return
 	}
 
 	c.JSON(http.StatusCreated, collection)
 }
 
-func GetCollection(c *gin.Context, repo *repository.CollectionRepository) {
+func GetCollection(c *gin.Context, repo repository.CollectionStore) {
 	id := c.Param("id")
 
-	collection, err := repo.GetByID(id)
+	collection, err := repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found or error: " + err.Error()})
+		apierr.Abort(c, apierr.From(err, "Failed to load collection"))
 		return
 	}
 
@@ -73,30 +370,39 @@ func GetCollection(c *gin.Context, repo *repository.CollectionRepository) {
 	userID := userIDVal.(string)
 
 	if role == "farmer" && collection.FarmerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view this collection"})
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not authorized to view this collection"))
 		return
 	}
 
 	c.JSON(http.StatusOK, collection)
 }
 
-func ListCollections(c *gin.Context, repo *repository.CollectionRepository) {
+func ListCollections(c *gin.Context, repo repository.CollectionStore) {
 	roleVal, _ := c.Get("role")
 	userIDVal, _ := c.Get("userId")
 	role := roleVal.(string)
 	userID := userIDVal.(string)
 
-	var collections []*models.Collection
-	var err error
-
+	var filter repository.CollectionFilter
 	if role == "farmer" {
-		collections, err = repo.ListByFarmer(userID)
-	} else {
-		collections, err = repo.ListAll()
+		filter.FarmerID = userID
+	}
+	filter.Flagged = c.Query("flagged") == "true"
+
+	// ?bbox=minLng,minLat,maxLng,maxLat (GeoJSON order) for map views
+	if v := c.Query("bbox"); v != "" {
+		box, err := parseBoundingBox(v)
+		if err != nil {
+			apierr.Abort(c, apierr.Invalid("bbox", apierr.RuleFormat, err.Error()))
+			return
+		}
+		filter.Within = box
 	}
 
+	collections, err := repo.List(c.Request.Context(), filter)
+
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list collections: " + err.Error()})
+		apierr.Abort(c, apierr.Internal(err, "Failed to list collections"))
 		return
 	}
 
@@ -106,43 +412,61 @@ func ListCollections(c *gin.Context, repo *repository.CollectionRepository) {
 	})
 }
 
+func parseBoundingBox(v string) (*geo.BoundingBox, error) {
+	parts := strings.Split(v, ",")
+	if len(parts) != 4 {
+		return nil, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+	}
+	var n [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+		}
+		n[i] = f
+	}
+	box := &geo.BoundingBox{MinLng: n[0], MinLat: n[1], MaxLng: n[2], MaxLat: n[3]}
+	if box.MinLat > box.MaxLat || box.MinLng > box.MaxLng {
+		return nil, errors.New("bbox minimums must not exceed maximums")
+	}
+	return box, nil
+}
+
 type UpdateStatusRequest struct {
 	Status  string `json:"status" binding:"required,oneof=pending verified paid"`
 	Version int    `json:"version" binding:"required"`
 }
 
-func UpdateCollectionStatus(c *gin.Context, repo *repository.CollectionRepository) {
+func UpdateCollectionStatus(c *gin.Context, repo repository.CollectionStore, auditRepo *repository.AuditRepository) {
 	id := c.Param("id")
 	roleVal, roleExists := c.Get("role")
 	userIDVal, userExists := c.Get("userId")
 	if !roleExists || !userExists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		apierr.Abort(c, apierr.New(apierr.Unauthenticated, "authentication required"))
 		return
 	}
 	role := roleVal.(string)
 	userID := userIDVal.(string)
 
 	if role != "collector" && role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only collectors and admins can update collection status"})
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "only collectors and admins can update collection status"))
 		return
 	}
 
+	before, err := repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load collection"))
+		return
+	}
 	// If collector, ensure they own the collection they are updating
-	if role == "collector" {
-		current, err := repo.GetByID(id)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "collection not found"})
-			return
-		}
-		if current.CollectorID != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "not authorized to update this collection"})
-			return
-		}
+	if role == "collector" && before.CollectorID != userID {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "not authorized to update this collection"))
+		return
 	}
 
 	var payload UpdateStatusRequest
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Abort(c, apierr.BadRequest(err))
 		return
 	}
 
@@ -152,19 +476,27 @@ func UpdateCollectionStatus(c *gin.Context, repo *repository.CollectionRepositor
 		Version: payload.Version,
 	}
 
-	err := repo.UpdateWithVersion(col)
+	var after *models.Collection
+	err = audited(c, auditRepo, models.AuditUpdate, "collection", id, before, &after, func(ctx context.Context) error {
+		if err := repo.UpdateWithVersion(ctx, col); err != nil {
+			return err
+		}
+		after, err = repo.GetByID(ctx, id)
+		return err
+	})
 	if err != nil {
 		if err == repository.ErrConflict {
+			metrics.SyncConflict("collection")
 			// fetch current record to return for client merge UI
-			current, fetchErr := repo.GetByID(id)
+			current, fetchErr := repo.GetByID(c.Request.Context(), id)
 			if fetchErr != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "conflict and failed to fetch current"})
+				apierr.Abort(c, apierr.Internal(fetchErr, "Failed to load collection after a version conflict"))
 				return
 			}
-			c.JSON(http.StatusConflict, gin.H{"error": "version conflict", "current": current})
+			apierr.Abort(c, apierr.New(apierr.VersionConflict, "").With("current", current))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		apierr.Abort(c, apierr.Internal(err, "Failed to update collection"))
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
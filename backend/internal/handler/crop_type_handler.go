@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CropTypeRequest struct {
+	Code          string   `json:"code" binding:"required"`
+	NameEn        string   `json:"name_en" binding:"required"`
+	NameSw        string   `json:"name_sw"`
+	Unit          string   `json:"unit" binding:"required"`
+	Active        *bool    `json:"active"`
+	AllowedGrades []string `json:"allowed_grades"`
+}
+
+// ListCropTypes returns the crop registry. Devices pass ?since= with the
+// synced_at of their previous pull to receive only what changed.
+func ListCropTypes(c *gin.Context, repo *repository.CropTypeRepository) {
+	syncedAt := time.Now().UTC()
+
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			apierr.Abort(c, apierr.Invalid("since", apierr.RuleFormat, "since must be an RFC3339 timestamp"))
+			return
+		}
+		since = parsed
+	}
+
+	cropTypes, err := repo.ListChangedSince(c.Request.Context(), since)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list crop types"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"crop_types": cropTypes,
+		"count":      len(cropTypes),
+		"synced_at":  syncedAt.Format(time.RFC3339),
+	})
+}
+
+func CreateCropType(c *gin.Context, repo *repository.CropTypeRepository, unitRepo *repository.UnitRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage crop types"))
+		return
+	}
+
+	var req CropTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if _, err := unitRepo.GetByCode(c.Request.Context(), req.Unit); err != nil {
+		if err == repository.ErrUnitNotFound {
+			apierr.Abort(c, apierr.Unprocessable("unit", apierr.RuleUnknown, "Unknown unit: "+req.Unit))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load unit"))
+		return
+	}
+
+	cropType := &models.CropType{
+		Code:          repository.NormalizeCropCode(req.Code),
+		NameEn:        req.NameEn,
+		NameSw:        req.NameSw,
+		Unit:          req.Unit,
+		Active:        req.Active == nil || *req.Active,
+		AllowedGrades: req.AllowedGrades,
+	}
+
+	if _, err := repo.GetByCode(c.Request.Context(), cropType.Code); err == nil {
+		apierr.Abort(c, apierr.New(apierr.AlreadyExists, "Crop type already exists"))
+		return
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "crop_type", cropType.Code, nil, cropType, func(ctx context.Context) error {
+		return repo.Create(ctx, cropType)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create crop type"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, cropType)
+}
+
+// UpdateCropType edits a registry entry. Codes are immutable; retire a crop
+// by setting active to false rather than deleting it.
+func UpdateCropType(c *gin.Context, repo *repository.CropTypeRepository, unitRepo *repository.UnitRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage crop types"))
+		return
+	}
+
+	var req CropTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if _, err := unitRepo.GetByCode(c.Request.Context(), req.Unit); err != nil {
+		if err == repository.ErrUnitNotFound {
+			apierr.Abort(c, apierr.Unprocessable("unit", apierr.RuleUnknown, "Unknown unit: "+req.Unit))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load unit"))
+		return
+	}
+
+	cropType, err := repo.GetByCode(c.Request.Context(), repository.NormalizeCropCode(c.Param("code")))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load crop type"))
+		return
+	}
+	if repository.NormalizeCropCode(req.Code) != cropType.Code {
+		apierr.Abort(c, apierr.Invalid("code", apierr.RuleMismatch, "Crop type code cannot be changed"))
+		return
+	}
+
+	before := snapshot(cropType)
+	cropType.NameEn = req.NameEn
+	cropType.NameSw = req.NameSw
+	cropType.Unit = req.Unit
+	if req.Active != nil {
+		cropType.Active = *req.Active
+	}
+	cropType.AllowedGrades = req.AllowedGrades
+
+	err = audited(c, auditRepo, models.AuditUpdate, "crop_type", cropType.Code, before, cropType, func(ctx context.Context) error {
+		return repo.Update(ctx, cropType)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to update crop type"))
+		return
+	}
+
+	c.JSON(http.StatusOK, cropType)
+}
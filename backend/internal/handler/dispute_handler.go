@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/metrics"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DisputeRequest struct {
+	Reason             string   `json:"reason" binding:"required"`
+	ProposedWeightKg   *float64 `json:"proposed_weight_kg" binding:"omitempty,gt=0"`
+	ProposedPricePerKg *float64 `json:"proposed_price_per_kg" binding:"omitempty,gte=0"`
+}
+
+type ResolveDisputeRequest struct {
+	Outcome    string `json:"outcome" binding:"required,oneof=upheld rejected"`
+	Resolution string `json:"resolution" binding:"required"`
+	// Corrected values for an upheld dispute; each defaults to the farmer's
+	// proposal, then to the current value.
+	WeightKg   *float64 `json:"weight_kg" binding:"omitempty,gt=0"`
+	PricePerKg *float64 `json:"price_per_kg" binding:"omitempty,gte=0"`
+	Version    int      `json:"version" binding:"required"`
+}
+
+// canReviewDisputes: disputes are reviewed by admins and clerks.
+func canReviewDisputes(c *gin.Context) bool {
+	role, _ := c.Get("role")
+	return role == "admin" || role == "clerk"
+}
+
+// canViewDispute: the farmer who raised it, the collector whose record is
+// disputed, and reviewers.
+func canViewDispute(c *gin.Context, d *models.Dispute) bool {
+	role, _ := c.Get("role")
+	userID, _ := c.Get("userId")
+	switch role {
+	case "farmer":
+		return d.FarmerID == userID
+	case "collector":
+		return d.CollectorID == userID
+	}
+	return canReviewDisputes(c)
+}
+
+func disputeNotification(userID, kind string, d *models.Dispute, message string) *models.Notification {
+	return &models.Notification{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Kind:        kind,
+		SubjectType: models.OwnerDispute,
+		SubjectID:   d.ID,
+		Message:     message,
+	}
+}
+
+// CreateDispute lets a farmer challenge the weight or price recorded on one
+// of their collections. Evidence is attached afterwards through
+// /disputes/:id/attachments.
+func CreateDispute(c *gin.Context, repo *repository.DisputeRepository, collectionRepo repository.CollectionStore, auditRepo *repository.AuditRepository) {
+	roleVal, _ := c.Get("role")
+	userIDVal, _ := c.Get("userId")
+	if roleVal != "farmer" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only farmers can dispute collections"))
+		return
+	}
+
+	var req DisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if req.ProposedWeightKg == nil && req.ProposedPricePerKg == nil {
+		apierr.Abort(c, apierr.Invalid("proposed_weight_kg", apierr.RuleRequired, "proposed_weight_kg or proposed_price_per_kg is required"))
+		return
+	}
+
+	collection, err := collectionRepo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load collection"))
+		return
+	}
+	if collection.FarmerID != userIDVal {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not authorized to dispute this collection"))
+		return
+	}
+
+	dispute := &models.Dispute{
+		ID:                 uuid.New().String(),
+		CollectionID:       collection.ID,
+		FarmerID:           collection.FarmerID,
+		CollectorID:        collection.CollectorID,
+		Reason:             req.Reason,
+		ProposedWeightKg:   req.ProposedWeightKg,
+		ProposedPricePerKg: req.ProposedPricePerKg,
+	}
+	notes := []*models.Notification{
+		disputeNotification(collection.CollectorID, models.NotifyDisputeOpened, dispute,
+			fmt.Sprintf("A farmer has disputed collection %s: %s", collection.ID, req.Reason)),
+	}
+
+	err = audited(c, auditRepo, models.AuditCreate, "dispute", dispute.ID, nil, dispute, func(ctx context.Context) error {
+		return repo.Create(ctx, dispute, notes)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to create dispute"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dispute)
+}
+
+// ListDisputes returns disputes, oldest first. Farmers see their own and
+// collectors those against their records; reviewers see all and may filter
+// by ?collector_id= and ?collection_id=. Everyone may filter by ?status=.
+func ListDisputes(c *gin.Context, repo *repository.DisputeRepository) {
+	roleVal, _ := c.Get("role")
+	userIDVal, _ := c.Get("userId")
+
+	filter := repository.DisputeFilter{
+		Status:       models.DisputeStatus(c.Query("status")),
+		CollectorID:  c.Query("collector_id"),
+		CollectionID: c.Query("collection_id"),
+	}
+	switch roleVal {
+	case "farmer":
+		filter.FarmerID = userIDVal.(string)
+	case "collector":
+		filter.CollectorID = userIDVal.(string)
+	}
+
+	disputes, err := repo.List(c.Request.Context(), filter)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list disputes"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"disputes": disputes,
+		"count":    len(disputes),
+	})
+}
+
+// DisputeQueue is the reviewers' work list: open disputes, oldest first.
+func DisputeQueue(c *gin.Context, repo *repository.DisputeRepository) {
+	if !canReviewDisputes(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins and clerks can review disputes"))
+		return
+	}
+
+	disputes, err := repo.List(c.Request.Context(), repository.DisputeFilter{Status: models.DisputeOpen})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to load review queue"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"disputes": disputes,
+		"count":    len(disputes),
+	})
+}
+
+func GetDispute(c *gin.Context, repo *repository.DisputeRepository) {
+	dispute, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load dispute"))
+		return
+	}
+	if !canViewDispute(c, dispute) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not authorized to view this dispute"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// ResolveDispute closes a dispute. An upheld dispute is settled with an
+// adjustment for the value difference, like a regrade; a rejected one leaves
+// the recorded values as they are. Both parties are notified.
+func ResolveDispute(c *gin.Context, repo *repository.DisputeRepository, collectionRepo repository.CollectionStore, adjustmentRepo *repository.AdjustmentRepository, auditRepo *repository.AuditRepository) {
+	if !canReviewDisputes(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins and clerks can resolve disputes"))
+		return
+	}
+	userIDVal, _ := c.Get("userId")
+
+	var req ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	current, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load dispute"))
+		return
+	}
+	if current.Status != models.DisputeOpen {
+		apierr.Abort(c, apierr.New(apierr.DisputeResolved, "").With("current", current))
+		return
+	}
+
+	dispute := *current
+	dispute.Status = models.DisputeStatus(req.Outcome)
+	dispute.Resolution = req.Resolution
+	dispute.ResolvedBy = userIDVal.(string)
+	dispute.Version = req.Version
+
+	var (
+		collection *models.Collection
+		adjustment *models.Adjustment
+	)
+	if dispute.Status == models.DisputeUpheld {
+		collection, err = collectionRepo.GetByID(c.Request.Context(), dispute.CollectionID)
+		if err != nil {
+			apierr.Abort(c, apierr.Internal(err, "Failed to load collection"))
+			return
+		}
+		adjustment = &models.Adjustment{
+			ID:           uuid.New().String(),
+			CollectionID: collection.ID,
+			Kind:         models.AdjustmentDispute,
+			Reason:       req.Resolution,
+			CreatedBy:    dispute.ResolvedBy,
+		}
+	}
+
+	// upheld values the adjustment from the collection's standing, read in
+	// the transaction the adjustment is added in. Resolve fails with
+	// ErrConflict if another adjustment got there since the collection was
+	// loaded.
+	upheld := func(ctx context.Context) error {
+		history, err := adjustmentRepo.ListByCollection(ctx, collection.ID)
+		if err != nil {
+			return err
+		}
+		weight, grade, price := standing(collection, history)
+
+		newWeight, newPrice := weight, price
+		if req.WeightKg != nil {
+			newWeight = *req.WeightKg
+		} else if dispute.ProposedWeightKg != nil {
+			newWeight = *dispute.ProposedWeightKg
+		}
+		if req.PricePerKg != nil {
+			newPrice = *req.PricePerKg
+		} else if dispute.ProposedPricePerKg != nil {
+			newPrice = *dispute.ProposedPricePerKg
+		}
+		if newWeight == weight && newPrice == price {
+			return apierr.Unprocessable("weight_kg", apierr.RuleRequired, "Upheld dispute does not change the weight or price")
+		}
+
+		adjustment.PreviousWeightKg = weight
+		adjustment.NewWeightKg = newWeight
+		adjustment.PreviousGrade = grade
+		adjustment.NewGrade = grade
+		adjustment.PreviousPricePerKg = price
+		adjustment.NewPricePerKg = newPrice
+		adjustment.Amount = newWeight*newPrice - weight*price
+		return nil
+	}
+
+	message := fmt.Sprintf("Dispute on collection %s was %s: %s", dispute.CollectionID, dispute.Status, dispute.Resolution)
+	notes := []*models.Notification{
+		disputeNotification(dispute.FarmerID, models.NotifyDisputeResolved, &dispute, message),
+		disputeNotification(dispute.CollectorID, models.NotifyDisputeResolved, &dispute, message),
+	}
+
+	err = audited(c, auditRepo, models.AuditUpdate, "dispute", dispute.ID, current, &dispute, func(ctx context.Context) error {
+		if adjustment == nil {
+			return repo.Resolve(ctx, &dispute, nil, 0, notes)
+		}
+		if err := upheld(ctx); err != nil {
+			return err
+		}
+		if err := repo.Resolve(ctx, &dispute, adjustment, collection.Version, notes); err != nil {
+			return err
+		}
+		return recordAudit(ctx, c, auditRepo, models.AuditCreate, "adjustment", adjustment.ID, nil, adjustment)
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			metrics.SyncConflict("dispute")
+			apierr.Abort(c, apierr.New(apierr.VersionConflict, "").With("current", current))
+			return
+		}
+		apierr.Abort(c, apierr.From(err, "Failed to resolve dispute"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"dispute":    dispute,
+		"adjustment": adjustment,
+	})
+}
+
+// GetDisputeReport shows, per collector, how many of their records were
+// disputed in the period and how those disputes ended.
+func GetDisputeReport(c *gin.Context, repo *repository.DisputeRepository) {
+	if !canReviewDisputes(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins and clerks can view dispute statistics"))
+		return
+	}
+
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	stats, err := repo.StatsByCollector(c.Request.Context(), from, to)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to build report"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":       from.Format(time.RFC3339),
+		"to":         to.Format(time.RFC3339),
+		"collectors": stats,
+	})
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/geo"
+	"agri-sync-backend/internal/metrics"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PlotRequest struct {
+	ID         string          `json:"id"` // optional; keep it to update an existing plot
+	Name       string          `json:"name" binding:"required"`
+	CropType   string          `json:"crop_type" binding:"required"`
+	AreaAcres  *float64        `json:"area_acres" binding:"omitempty,gte=0"`
+	Boundary   json.RawMessage `json:"boundary"`
+	StockCount int             `json:"stock_count" binding:"gte=0"`
+	StockUnit  string          `json:"stock_unit"` // bushes, trees, cows
+}
+
+type FarmRequest struct {
+	ID        string          `json:"id"`        // optional on create, for farms registered offline
+	FarmerID  string          `json:"farmer_id"` // ignored for farmers, who register their own
+	Name      string          `json:"name" binding:"required"`
+	AreaAcres *float64        `json:"area_acres" binding:"omitempty,gte=0"`
+	Boundary  json.RawMessage `json:"boundary"`
+	Plots     []PlotRequest   `json:"plots" binding:"dive"`
+	Version   int             `json:"version"` // required on update
+}
+
+// areaAcres returns the entered area, or the boundary's area when none was
+// entered. It validates the boundary.
+func areaAcres(entered *float64, boundary json.RawMessage) (float64, error) {
+	if len(boundary) == 0 || string(boundary) == "null" {
+		if entered == nil {
+			return 0, nil
+		}
+		return *entered, nil
+	}
+	poly, err := geo.ParsePolygon(boundary)
+	if err != nil {
+		return 0, err
+	}
+	if entered != nil {
+		return *entered, nil
+	}
+	return poly.AreaAcres(), nil
+}
+
+// farm turns the request into a model after checking boundaries and crops.
+func (req *FarmRequest) farm(ctx context.Context, cropTypeRepo *repository.CropTypeRepository) (*models.Farm, *apierr.Error) {
+	area, err := areaAcres(req.AreaAcres, req.Boundary)
+	if err != nil {
+		return nil, apierr.Unprocessable("boundary", apierr.RuleFormat, err.Error())
+	}
+	farm := &models.Farm{
+		ID:        req.ID,
+		FarmerID:  req.FarmerID,
+		Name:      req.Name,
+		AreaAcres: area,
+		Boundary:  req.Boundary,
+		Plots:     make([]*models.Plot, 0, len(req.Plots)),
+	}
+	if string(farm.Boundary) == "null" {
+		farm.Boundary = nil
+	}
+
+	for i, p := range req.Plots {
+		field := fmt.Sprintf("plots[%d].", i)
+		plotArea, err := areaAcres(p.AreaAcres, p.Boundary)
+		if err != nil {
+			return nil, apierr.Unprocessable(field+"boundary", apierr.RuleFormat, "plot "+p.Name+": "+err.Error())
+		}
+		cropType := repository.NormalizeCropCode(p.CropType)
+		if _, err := cropTypeRepo.GetByCode(ctx, cropType); err != nil {
+			if errors.Is(err, repository.ErrCropTypeNotFound) {
+				return nil, apierr.Unprocessable(field+"crop_type", apierr.RuleUnknown, "plot "+p.Name+": unknown crop type "+cropType)
+			}
+			return nil, apierr.Internal(err, "Failed to load crop type")
+		}
+		plot := &models.Plot{
+			ID:         p.ID,
+			Name:       p.Name,
+			CropType:   cropType,
+			AreaAcres:  plotArea,
+			Boundary:   p.Boundary,
+			StockCount: p.StockCount,
+			StockUnit:  p.StockUnit,
+		}
+		if plot.ID == "" {
+			plot.ID = uuid.New().String()
+		}
+		if string(plot.Boundary) == "null" {
+			plot.Boundary = nil
+		}
+		farm.Plots = append(farm.Plots, plot)
+	}
+	return farm, nil
+}
+
+// canManageFarm: farmers manage their own farms; collectors (as field
+// officers) and admins manage anyone's.
+func canManageFarm(c *gin.Context, farmerID string) bool {
+	role, _ := c.Get("role")
+	userID, _ := c.Get("userId")
+	return role == "admin" || role == "collector" || (role == "farmer" && userID == farmerID)
+}
+
+// ListFarms returns farms with their plots. Farmers get their own; others
+// may pass ?farmer_id=. Devices pass ?since= with the synced_at of their
+// previous pull to receive only changes, including deletions.
+func ListFarms(c *gin.Context, repo *repository.FarmRepository) {
+	syncedAt := time.Now().UTC()
+
+	farmerID := c.Query("farmer_id")
+	if role, _ := c.Get("role"); role == "farmer" {
+		userIDVal, _ := c.Get("userId")
+		farmerID = userIDVal.(string)
+	}
+
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			apierr.Abort(c, apierr.Invalid("since", apierr.RuleFormat, "since must be an RFC3339 timestamp"))
+			return
+		}
+		since = parsed
+	}
+
+	farms, err := repo.List(c.Request.Context(), farmerID, since)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list farms"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"farms":     farms,
+		"count":     len(farms),
+		"synced_at": syncedAt.Format(time.RFC3339),
+	})
+}
+
+func GetFarm(c *gin.Context, repo *repository.FarmRepository) {
+	farm, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load farm"))
+		return
+	}
+	if !canManageFarm(c, farm.FarmerID) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not authorized to view this farm"))
+		return
+	}
+
+	c.JSON(http.StatusOK, farm)
+}
+
+func CreateFarm(c *gin.Context, repo *repository.FarmRepository, farmerRepo repository.FarmerStore, cropTypeRepo *repository.CropTypeRepository, auditRepo *repository.AuditRepository) {
+	var req FarmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	if role, _ := c.Get("role"); role == "farmer" {
+		userIDVal, _ := c.Get("userId")
+		req.FarmerID = userIDVal.(string)
+	}
+	if req.FarmerID == "" {
+		apierr.Abort(c, apierr.Invalid("farmer_id", apierr.RuleRequired, "farmer_id is required"))
+		return
+	}
+	if !canManageFarm(c, req.FarmerID) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not authorized to register farms"))
+		return
+	}
+	if _, err := farmerRepo.GetByID(c.Request.Context(), req.FarmerID); err != nil {
+		if errors.Is(err, repository.ErrFarmerNotFound) {
+			apierr.Abort(c, apierr.Unprocessable("farmer_id", apierr.RuleUnknown, "Unknown farmer"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load farmer"))
+		return
+	}
+
+	farm, rejected := req.farm(c.Request.Context(), cropTypeRepo)
+	if rejected != nil {
+		apierr.Abort(c, rejected)
+		return
+	}
+	if farm.ID == "" {
+		farm.ID = uuid.New().String()
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "farm", farm.ID, nil, farm, func(ctx context.Context) error {
+		return repo.Create(ctx, farm)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrPlotNotFound) {
+			apierr.Abort(c, apierr.Unprocessable("plots", apierr.RuleUnknown, "A plot id belongs to another farm"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to create farm"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, farm)
+}
+
+// UpdateFarm replaces a farm and its plots. The request must carry the
+// version the client last saw; a stale version gets 409 with the current farm.
+func UpdateFarm(c *gin.Context, repo *repository.FarmRepository, cropTypeRepo *repository.CropTypeRepository, auditRepo *repository.AuditRepository) {
+	var req FarmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if req.Version == 0 {
+		apierr.Abort(c, apierr.Invalid("version", apierr.RuleRequired, "version is required"))
+		return
+	}
+
+	current, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load farm"))
+		return
+	}
+	if !canManageFarm(c, current.FarmerID) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not authorized to update this farm"))
+		return
+	}
+
+	// The owner and ID do not change
+	req.ID, req.FarmerID = current.ID, current.FarmerID
+	farm, rejected := req.farm(c.Request.Context(), cropTypeRepo)
+	if rejected != nil {
+		apierr.Abort(c, rejected)
+		return
+	}
+	farm.Version = req.Version
+	farm.CreatedAt = current.CreatedAt
+
+	var updated *models.Farm
+	err = audited(c, auditRepo, models.AuditUpdate, "farm", farm.ID, current, &updated, func(ctx context.Context) error {
+		if err := repo.Update(ctx, farm); err != nil {
+			return err
+		}
+		updated, err = repo.GetByID(ctx, farm.ID)
+		return err
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			metrics.SyncConflict("farm")
+			apierr.Abort(c, apierr.New(apierr.VersionConflict, "").With("current", current))
+			return
+		}
+		if errors.Is(err, repository.ErrPlotNotFound) {
+			apierr.Abort(c, apierr.Unprocessable("plots", apierr.RuleUnknown, "A plot id belongs to another farm"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to update farm"))
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteFarm removes a farm and its plots. Pass ?version= as for updates.
+func DeleteFarm(c *gin.Context, repo *repository.FarmRepository, auditRepo *repository.AuditRepository) {
+	version, err := strconv.Atoi(c.Query("version"))
+	if err != nil {
+		apierr.Abort(c, apierr.Invalid("version", apierr.RuleRequired, "version is required"))
+		return
+	}
+
+	current, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load farm"))
+		return
+	}
+	if !canManageFarm(c, current.FarmerID) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not authorized to delete this farm"))
+		return
+	}
+
+	err = audited(c, auditRepo, models.AuditDelete, "farm", current.ID, current, nil, func(ctx context.Context) error {
+		return repo.Delete(ctx, current.ID, version)
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			metrics.SyncConflict("farm")
+			apierr.Abort(c, apierr.New(apierr.VersionConflict, "").With("current", current))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to delete farm"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"time"
+	"agri-sync-backend/internal/apierr"
 	"agri-sync-backend/internal/models"
 	"agri-sync-backend/internal/repository"
 
@@ -13,27 +14,27 @@ type WalletSummary struct {
 	TotalPending float64 `json:"total_pending"`
 	TotalPaid    float64 `json:"total_paid"`
 	TotalOverall float64 `json:"total_overall"`
-	Currency     string  `json:"currency"` // hardcoded for now
+	Currency     string  `json:"currency"` // ISO 4217, from the currency setting
 	UpdatedAt    string  `json:"updated_at"`
 }
 
-func GetFarmerHistory(c *gin.Context, repo *repository.CollectionRepository) {
+func GetFarmerHistory(c *gin.Context, repo repository.CollectionStore) {
 	roleVal, exists := c.Get("role")
 	if !exists || roleVal != "farmer" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint is only available to farmers"})
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "This endpoint is only available to farmers"))
 		return
 	}
 
 	userIDVal, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		apierr.Abort(c, apierr.New(apierr.Unauthenticated, "User ID not found in token"))
 		return
 	}
 	farmerID := userIDVal.(string)
 
-	collections, err := repo.ListByFarmer(farmerID)
+	collections, err := repo.ListByFarmer(c.Request.Context(), farmerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve history: " + err.Error()})
+		apierr.Abort(c, apierr.Internal(err, "Failed to retrieve history"))
 		return
 	}
 
@@ -45,29 +46,42 @@ func GetFarmerHistory(c *gin.Context, repo *repository.CollectionRepository) {
 	})
 }
 
-func GetFarmerWallet(c *gin.Context, repo *repository.CollectionRepository) {
+func GetFarmerWallet(c *gin.Context, repo repository.CollectionStore, adjustmentRepo *repository.AdjustmentRepository, currency string) {
 	roleVal, exists := c.Get("role")
 	if !exists || roleVal != "farmer" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint is only available to farmers"})
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "This endpoint is only available to farmers"))
 		return
 	}
 
 	userIDVal, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		apierr.Abort(c, apierr.New(apierr.Unauthenticated, "User ID not found in token"))
 		return
 	}
 	farmerID := userIDVal.(string)
 
-	collections, err := repo.ListByFarmer(farmerID)
+	collections, err := repo.ListByFarmer(c.Request.Context(), farmerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate wallet: " + err.Error()})
+		apierr.Abort(c, apierr.Internal(err, "Failed to calculate wallet"))
 		return
 	}
 
+	// Regrades and other corrections are settled with their collection's
+	// payout, so each counts where its collection does. This is the rule
+	// StatsRepository.PendingPayout uses for the pending payout gauge.
+	adjustments, err := adjustmentRepo.ListByFarmer(c.Request.Context(), farmerID)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to calculate wallet"))
+		return
+	}
+	adjusted := map[string]float64{}
+	for _, adj := range adjustments {
+		adjusted[adj.CollectionID] += adj.Amount
+	}
+
 	var pending, paid float64
 	for _, col := range collections {
-		value := col.WeightKg * col.PricePerKg
+		value := col.WeightKg*col.PricePerKg + adjusted[col.ID]
 		switch col.Status {
 		case models.StatusPending, models.StatusVerified:
 			pending += value
@@ -80,7 +94,7 @@ func GetFarmerWallet(c *gin.Context, repo *repository.CollectionRepository) {
 		TotalPending: pending,
 		TotalPaid:    paid,
 		TotalOverall: pending + paid,
-		Currency:     "USD", // can be made configurable later
+		Currency:     currency,
 		UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
 	}
 
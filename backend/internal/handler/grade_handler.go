@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GradeRequest struct {
+	CropType string   `json:"crop_type" binding:"required"`
+	Kind     string   `json:"kind" binding:"required,oneof=grade reading rejection"`
+	Code     string   `json:"code" binding:"required"`
+	Name     string   `json:"name" binding:"required"`
+	Unit     string   `json:"unit"`
+	MinValue *float64 `json:"min_value"`
+	MaxValue *float64 `json:"max_value"`
+}
+
+// ListGrades returns the grade, reading and rejection definitions, optionally
+// filtered by ?crop_type=, for devices to cache.
+func ListGrades(c *gin.Context, repo *repository.GradeRepository) {
+	grades, err := repo.List(c.Request.Context(), repository.NormalizeCropCode(c.Query("crop_type")))
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list grades"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"grades": grades,
+		"count":  len(grades),
+	})
+}
+
+func CreateGrade(c *gin.Context, repo *repository.GradeRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage grades"))
+		return
+	}
+
+	var req GradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	grade := &models.Grade{
+		ID:       uuid.New().String(),
+		CropType: repository.NormalizeCropCode(req.CropType),
+		Kind:     models.GradeKind(req.Kind),
+		Code:     req.Code,
+		Name:     req.Name,
+		Unit:     req.Unit,
+		MinValue: req.MinValue,
+		MaxValue: req.MaxValue,
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "grade", grade.ID, nil, grade, func(ctx context.Context) error {
+		return repo.Create(ctx, grade)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create grade"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, grade)
+}
+
+func UpdateGrade(c *gin.Context, repo *repository.GradeRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage grades"))
+		return
+	}
+
+	var req GradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	grade, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load grade"))
+		return
+	}
+
+	before := snapshot(grade)
+	grade.CropType = repository.NormalizeCropCode(req.CropType)
+	grade.Kind = models.GradeKind(req.Kind)
+	grade.Code = req.Code
+	grade.Name = req.Name
+	grade.Unit = req.Unit
+	grade.MinValue = req.MinValue
+	grade.MaxValue = req.MaxValue
+
+	err = audited(c, auditRepo, models.AuditUpdate, "grade", grade.ID, before, grade, func(ctx context.Context) error {
+		return repo.Update(ctx, grade)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to update grade"))
+		return
+	}
+
+	c.JSON(http.StatusOK, grade)
+}
+
+func DeleteGrade(c *gin.Context, repo *repository.GradeRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage grades"))
+		return
+	}
+
+	before, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load grade"))
+		return
+	}
+	err = audited(c, auditRepo, models.AuditDelete, "grade", c.Param("id"), before, nil, func(ctx context.Context) error {
+		return repo.Delete(ctx, before.ID)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to delete grade"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type RegradeRequest struct {
+	Grade  string `json:"grade" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// standing returns the weight, grade and price a collection is currently
+// valued at: those of its latest adjustment, if any. A rejected delivery
+// has so far been worth nothing.
+func standing(collection *models.Collection, history []*models.Adjustment) (float64, string, float64) {
+	weight, grade, price := collection.WeightKg, collection.Grade, collection.PricePerKg
+	if collection.Status == models.StatusRejected {
+		price = 0
+	}
+	if n := len(history); n > 0 {
+		weight = history[n-1].NewWeightKg
+		grade = history[n-1].NewGrade
+		price = history[n-1].NewPricePerKg
+	}
+	return weight, grade, price
+}
+
+// RegradeCollection changes the grade of a recorded delivery by appending an
+// adjustment for the value difference. The collection keeps the values it was
+// captured with; only its version moves on.
+func RegradeCollection(c *gin.Context, repo repository.CollectionStore, adjustmentRepo *repository.AdjustmentRepository, gradeRepo *repository.GradeRepository, priceRepo *repository.PriceRepository, unitRepo *repository.UnitRepository, centerRepo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can regrade collections"))
+		return
+	}
+	userIDVal, _ := c.Get("userId")
+	userID := userIDVal.(string)
+
+	var req RegradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	collection, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load collection"))
+		return
+	}
+
+	if _, err := gradeRepo.GetByCode(c.Request.Context(), collection.CropType, models.GradeKindGrade, req.Grade); err != nil {
+		if err == repository.ErrGradeNotFound {
+			apierr.Abort(c, apierr.Unprocessable("grade", apierr.RuleUnknown, "Unknown grade for this crop"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load grade"))
+		return
+	}
+
+	// Regrading prices at the catalog in force when the delivery was made,
+	// in the region of the center it was made at, as CreateCollection does.
+	region := ""
+	if collection.CenterID != "" {
+		center, err := centerRepo.GetByID(c.Request.Context(), collection.CenterID)
+		if err != nil {
+			apierr.Abort(c, apierr.Internal(err, "Failed to load collection center"))
+			return
+		}
+		region = center.Region
+	}
+	newPrice, err := catalogPricePerKg(c.Request.Context(), priceRepo, unitRepo, collection.CropType, req.Grade, region, collection.CreatedAt)
+	if err != nil {
+		if err == repository.ErrPriceNotFound || err == repository.ErrNoConversion {
+			apierr.Abort(c, apierr.Unprocessable("grade", apierr.RuleMismatch, "No catalog price for this crop and grade"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to look up catalog price"))
+		return
+	}
+
+	adjustment := &models.Adjustment{
+		ID:            uuid.New().String(),
+		CollectionID:  collection.ID,
+		Kind:          models.AdjustmentRegrade,
+		NewGrade:      req.Grade,
+		NewPricePerKg: newPrice,
+		Reason:        req.Reason,
+		CreatedBy:     userID,
+	}
+
+	// The standing is read in the transaction the adjustment is added in,
+	// and Create fails with ErrConflict if another adjustment got there
+	// since the collection was loaded.
+	err = audited(c, auditRepo, models.AuditCreate, "adjustment", adjustment.ID, nil, adjustment, func(ctx context.Context) error {
+		history, err := adjustmentRepo.ListByCollection(ctx, collection.ID)
+		if err != nil {
+			return err
+		}
+		weight, previousGrade, previousPrice := standing(collection, history)
+		adjustment.PreviousWeightKg = weight
+		adjustment.NewWeightKg = weight
+		adjustment.PreviousGrade = previousGrade
+		adjustment.PreviousPricePerKg = previousPrice
+		adjustment.Amount = weight * (newPrice - previousPrice)
+		return adjustmentRepo.Create(ctx, adjustment, collection.Version)
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			current, fetchErr := repo.GetByID(c.Request.Context(), collection.ID)
+			if fetchErr != nil {
+				apierr.Abort(c, apierr.Internal(fetchErr, "Failed to load collection after a version conflict"))
+				return
+			}
+			apierr.Abort(c, apierr.New(apierr.VersionConflict, "").With("current", current))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to record adjustment"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, adjustment)
+}
+
+func ListCollectionAdjustments(c *gin.Context, repo repository.CollectionStore, adjustmentRepo *repository.AdjustmentRepository) {
+	collection, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load collection"))
+		return
+	}
+
+	// Same rule as GetCollection: farmers only see their own
+	roleVal, _ := c.Get("role")
+	userIDVal, _ := c.Get("userId")
+	if roleVal.(string) == "farmer" && collection.FarmerID != userIDVal.(string) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Not authorized to view this collection"))
+		return
+	}
+
+	adjustments, err := adjustmentRepo.ListByCollection(c.Request.Context(), collection.ID)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list adjustments"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection_id": collection.ID,
+		"adjustments":   adjustments,
+		"count":         len(adjustments),
+		"retrieved_at":  time.Now().UTC().Format(time.RFC3339),
+	})
+}
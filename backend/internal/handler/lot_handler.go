@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LotRequest struct {
+	Code          string   `json:"code" binding:"required"`
+	CenterID      string   `json:"center_id"`
+	CropType      string   `json:"crop_type" binding:"required"`
+	CollectionIDs []string `json:"collection_ids" binding:"required,min=1"`
+}
+
+type LotCollectionsRequest struct {
+	CollectionIDs []string `json:"collection_ids" binding:"required,min=1"`
+}
+
+type DispatchRequest struct {
+	ToKind      string  `json:"to_kind" binding:"required,oneof=center factory buyer"`
+	ToName      string  `json:"to_name" binding:"required"`
+	WeightOutKg float64 `json:"weight_out_kg" binding:"required,gt=0"`
+}
+
+type ReceiveRequest struct {
+	WeightInKg float64 `json:"weight_in_kg" binding:"required,gt=0"`
+}
+
+// canHandleLots: lots are handled by collectors and admins.
+func canHandleLots(c *gin.Context) bool {
+	role, _ := c.Get("role")
+	return role == "collector" || role == "admin"
+}
+
+// checkLotCollections makes sure every collection can go into the lot: same
+// crop, same center (when recorded), and not rejected.
+func checkLotCollections(ctx context.Context, repo repository.CollectionStore, lot *models.Lot, ids []string) *apierr.Error {
+	for _, id := range ids {
+		col, err := repo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrCollectionNotFound) {
+				return apierr.Unprocessable("collection_ids", apierr.RuleUnknown, "unknown collection "+id)
+			}
+			return apierr.Internal(err, "Failed to load collection")
+		}
+		if col.CropType != lot.CropType {
+			return apierr.Unprocessable("collection_ids", apierr.RuleMismatch, "collection "+id+" is "+col.CropType+", not "+lot.CropType)
+		}
+		if col.Status == models.StatusRejected {
+			return apierr.Unprocessable("collection_ids", apierr.RuleInvalid, "collection "+id+" was rejected")
+		}
+		if lot.CenterID != "" && col.CenterID != "" && col.CenterID != lot.CenterID {
+			return apierr.Unprocessable("collection_ids", apierr.RuleMismatch, "collection "+id+" was made at another center")
+		}
+	}
+	return nil
+}
+
+func ListLots(c *gin.Context, repo *repository.LotRepository) {
+	if !canHandleLots(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can view lots"))
+		return
+	}
+
+	lots, err := repo.List(c.Request.Context(), c.Query("center_id"))
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list lots"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lots":  lots,
+		"count": len(lots),
+	})
+}
+
+func GetLot(c *gin.Context, repo *repository.LotRepository) {
+	if !canHandleLots(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can view lots"))
+		return
+	}
+
+	lot, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load lot"))
+		return
+	}
+
+	c.JSON(http.StatusOK, lot)
+}
+
+func CreateLot(c *gin.Context, repo *repository.LotRepository, collectionRepo repository.CollectionStore, centerRepo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if !canHandleLots(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can create lots"))
+		return
+	}
+	userIDVal, _ := c.Get("userId")
+
+	var req LotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	if req.CenterID != "" {
+		if _, err := centerRepo.GetByID(c.Request.Context(), req.CenterID); err != nil {
+			if errors.Is(err, repository.ErrCenterNotFound) {
+				apierr.Abort(c, apierr.Unprocessable("center_id", apierr.RuleUnknown, "Unknown collection center"))
+				return
+			}
+			apierr.Abort(c, apierr.Internal(err, "Failed to load collection center"))
+			return
+		}
+	}
+
+	lot := &models.Lot{
+		ID:            uuid.New().String(),
+		Code:          req.Code,
+		CenterID:      req.CenterID,
+		CropType:      repository.NormalizeCropCode(req.CropType),
+		CreatedBy:     userIDVal.(string),
+		CollectionIDs: req.CollectionIDs,
+		Transfers:     []*models.LotTransfer{},
+	}
+	if rejected := checkLotCollections(c.Request.Context(), collectionRepo, lot, req.CollectionIDs); rejected != nil {
+		apierr.Abort(c, rejected)
+		return
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "lot", lot.ID, nil, lot, func(ctx context.Context) error {
+		return repo.Create(ctx, lot)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to create lot"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, lot)
+}
+
+// AddLotCollections adds deliveries to a lot that has not left the center.
+// The checks and the insert share a transaction, so a collection cannot be
+// rejected or moved between being checked and being added.
+func AddLotCollections(c *gin.Context, db repository.DB, stores repository.Stores, auditRepo *repository.AuditRepository) {
+	if !canHandleLots(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can manage lots"))
+		return
+	}
+
+	var req LotCollectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	var before, lot *models.Lot
+	err := repository.WithTx(c.Request.Context(), db, func(ctx context.Context, tx *sql.Tx) error {
+		lots := repository.NewLotRepository(tx)
+		var err error
+		if before, err = lots.GetByID(ctx, c.Param("id")); err != nil {
+			return err
+		}
+		if rejected := checkLotCollections(ctx, stores.With(tx).Collections, before, req.CollectionIDs); rejected != nil {
+			return rejected
+		}
+		if err := lots.AddCollections(ctx, before.ID, req.CollectionIDs); err != nil {
+			return err
+		}
+		if lot, err = lots.GetByID(ctx, before.ID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, c, auditRepo, models.AuditUpdate, "lot", lot.ID, before, lot)
+	})
+	if err != nil {
+		// checkLotCollections' rejections come through as they are
+		apierr.Abort(c, apierr.From(err, "Failed to add collections"))
+		return
+	}
+
+	c.JSON(http.StatusOK, lot)
+}
+
+// DispatchLot sends the lot on its next leg: from its center (or wherever the
+// previous leg ended) to a center, factory or buyer.
+func DispatchLot(c *gin.Context, repo *repository.LotRepository, centerRepo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if !canHandleLots(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can dispatch lots"))
+		return
+	}
+	userIDVal, _ := c.Get("userId")
+
+	var req DispatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	lot, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load lot"))
+		return
+	}
+
+	from := "origin"
+	if n := len(lot.Transfers); n > 0 {
+		from = lot.Transfers[n-1].ToName
+	} else if lot.CenterID != "" {
+		if center, err := centerRepo.GetByID(c.Request.Context(), lot.CenterID); err == nil {
+			from = center.Name
+		}
+	}
+
+	transfer := &models.LotTransfer{
+		ID:           uuid.New().String(),
+		LotID:        lot.ID,
+		FromName:     from,
+		ToKind:       req.ToKind,
+		ToName:       req.ToName,
+		WeightOutKg:  req.WeightOutKg,
+		DispatchedBy: userIDVal.(string),
+	}
+
+	err = audited(c, auditRepo, models.AuditCreate, "lot_transfer", transfer.ID, nil, transfer, func(ctx context.Context) error {
+		return repo.Dispatch(ctx, transfer)
+	})
+	if err != nil {
+		if err == repository.ErrLotInTransit {
+			apierr.Abort(c, apierr.New(apierr.LotInTransit, "Lot is still in transit; receive it first"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to dispatch lot"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// ReceiveLotTransfer records the weight that arrived; the difference from
+// the weight sent is the leg's shrinkage.
+func ReceiveLotTransfer(c *gin.Context, repo *repository.LotRepository, auditRepo *repository.AuditRepository) {
+	if !canHandleLots(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can receive lots"))
+		return
+	}
+	userIDVal, _ := c.Get("userId")
+
+	var req ReceiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	var before *models.LotTransfer
+	if lot, err := repo.GetByID(c.Request.Context(), c.Param("id")); err == nil {
+		for _, t := range lot.Transfers {
+			if t.ID == c.Param("transferId") {
+				before = t
+			}
+		}
+	}
+
+	var transfer *models.LotTransfer
+	err := audited(c, auditRepo, models.AuditUpdate, "lot_transfer", c.Param("transferId"), before, &transfer, func(ctx context.Context) error {
+		var err error
+		transfer, err = repo.Receive(ctx, c.Param("id"), c.Param("transferId"), req.WeightInKg, userIDVal.(string))
+		return err
+	})
+	if err != nil {
+		if err == repository.ErrAlreadyReceived {
+			apierr.Abort(c, apierr.New(apierr.TransferReceived, "").With("current", transfer))
+			return
+		}
+		apierr.Abort(c, apierr.From(err, "Failed to receive transfer"))
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// TraceLot returns the lot's full provenance: transfers with shrinkage, and
+// the farmers, deliveries and plots that make it up.
+func TraceLot(c *gin.Context, repo *repository.LotRepository) {
+	if !canHandleLots(c) {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can trace lots"))
+		return
+	}
+
+	trace, err := repo.Trace(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to trace lot"))
+		return
+	}
+
+	c.JSON(http.StatusOK, trace)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListNotifications returns the current user's notifications, newest first.
+// Pass ?unread=true for unread ones only.
+func ListNotifications(c *gin.Context, repo *repository.NotificationRepository) {
+	userIDVal, _ := c.Get("userId")
+
+	notifications, err := repo.ListByUser(c.Request.Context(), userIDVal.(string), c.Query("unread") == "true")
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list notifications"))
+		return
+	}
+
+	unread := 0
+	for _, n := range notifications {
+		if n.ReadAt == nil {
+			unread++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"count":         len(notifications),
+		"unread":        unread,
+	})
+}
+
+func MarkNotificationRead(c *gin.Context, repo *repository.NotificationRepository, auditRepo *repository.AuditRepository) {
+	userIDVal, _ := c.Get("userId")
+
+	err := audited(c, auditRepo, models.AuditUpdate, "notification", c.Param("id"), nil, gin.H{"read": true}, func(ctx context.Context) error {
+		return repo.MarkRead(ctx, c.Param("id"), userIDVal.(string))
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to update notification"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func MarkAllNotificationsRead(c *gin.Context, repo *repository.NotificationRepository, auditRepo *repository.AuditRepository) {
+	userIDVal, _ := c.Get("userId")
+
+	err := audited(c, auditRepo, models.AuditUpdate, "notification", "", nil, gin.H{"read": true, "all": true}, func(ctx context.Context) error {
+		return repo.MarkAllRead(ctx, userIDVal.(string))
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to update notifications"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
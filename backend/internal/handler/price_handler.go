@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PriceRequest struct {
+	CropType      string     `json:"crop_type" binding:"required"`
+	Grade         string     `json:"grade"`
+	Region        string     `json:"region"`
+	PricePerUnit  float64    `json:"price_per_unit" binding:"omitempty,gt=0"`
+	Unit          string     `json:"unit"` // defaults to kg
+	Multiplier    *float64   `json:"multiplier" binding:"omitempty,gt=0"`
+	EffectiveFrom time.Time  `json:"effective_from" binding:"required"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+}
+
+// validate checks the rules binding tags cannot express. It returns nil
+// when the request is valid.
+func (req *PriceRequest) validate() *apierr.Error {
+	if (req.PricePerUnit > 0) == (req.Multiplier != nil) {
+		return apierr.Invalid("price_per_unit", apierr.RuleMismatch, "exactly one of price_per_unit or multiplier is required")
+	}
+	if req.Multiplier != nil && req.Grade == "" {
+		return apierr.Invalid("multiplier", apierr.RuleMismatch, "multiplier is only allowed on grade prices")
+	}
+	if req.EffectiveTo != nil && !req.EffectiveTo.After(req.EffectiveFrom) {
+		return apierr.Invalid("effective_to", apierr.RuleRange, "effective_to must be after effective_from")
+	}
+	return nil
+}
+
+// catalogPricePerKg quotes the catalog for a crop and converts the result
+// from the unit it is quoted in to a price per kg.
+func catalogPricePerKg(ctx context.Context, priceRepo *repository.PriceRepository, unitRepo *repository.UnitRepository, cropType, grade, region string, at time.Time) (float64, error) {
+	price, unit, err := priceRepo.Quote(ctx, cropType, grade, region, at)
+	if err != nil {
+		return 0, err
+	}
+	toKg, err := unitRepo.ToKg(ctx, cropType, unit)
+	if err != nil {
+		return 0, err
+	}
+	return price / toKg, nil
+}
+
+// ListPrices returns the price table in effect now (or at ?at=RFC3339) so
+// devices can cache it and price collections while offline.
+func ListPrices(c *gin.Context, repo *repository.PriceRepository) {
+	at := time.Now().UTC()
+	if v := c.Query("at"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			apierr.Abort(c, apierr.Invalid("at", apierr.RuleFormat, "at must be an RFC3339 timestamp"))
+			return
+		}
+		at = parsed.UTC()
+	}
+
+	prices, err := repo.ListActive(c.Request.Context(), at)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list prices"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"prices": prices,
+		"count":  len(prices),
+		"as_of":  at.Format(time.RFC3339),
+	})
+}
+
+func CreatePrice(c *gin.Context, repo *repository.PriceRepository, unitRepo *repository.UnitRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage prices"))
+		return
+	}
+
+	var req PriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if invalid := req.validate(); invalid != nil {
+		apierr.Abort(c, invalid)
+		return
+	}
+	if req.Unit == "" {
+		req.Unit = models.BaseUnit
+	}
+	if _, err := unitRepo.ToKg(c.Request.Context(), repository.NormalizeCropCode(req.CropType), req.Unit); err != nil {
+		if err == repository.ErrNoConversion {
+			apierr.Abort(c, apierr.Unprocessable("unit", apierr.RuleMismatch, "No kg conversion for unit "+req.Unit+" on this crop"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load unit conversion"))
+		return
+	}
+
+	price := &models.Price{
+		ID:            uuid.New().String(),
+		CropType:      repository.NormalizeCropCode(req.CropType),
+		Grade:         req.Grade,
+		Region:        req.Region,
+		PricePerUnit:  req.PricePerUnit,
+		Unit:          req.Unit,
+		Multiplier:    req.Multiplier,
+		EffectiveFrom: req.EffectiveFrom,
+		EffectiveTo:   req.EffectiveTo,
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "price", price.ID, nil, price, func(ctx context.Context) error {
+		return repo.Create(ctx, price)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create price"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, price)
+}
+
+func UpdatePrice(c *gin.Context, repo *repository.PriceRepository, unitRepo *repository.UnitRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage prices"))
+		return
+	}
+
+	var req PriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if invalid := req.validate(); invalid != nil {
+		apierr.Abort(c, invalid)
+		return
+	}
+	if req.Unit == "" {
+		req.Unit = models.BaseUnit
+	}
+	if _, err := unitRepo.ToKg(c.Request.Context(), repository.NormalizeCropCode(req.CropType), req.Unit); err != nil {
+		if err == repository.ErrNoConversion {
+			apierr.Abort(c, apierr.Unprocessable("unit", apierr.RuleMismatch, "No kg conversion for unit "+req.Unit+" on this crop"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load unit conversion"))
+		return
+	}
+
+	price, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load price"))
+		return
+	}
+
+	before := snapshot(price)
+	price.CropType = repository.NormalizeCropCode(req.CropType)
+	price.Grade = req.Grade
+	price.Region = req.Region
+	price.PricePerUnit = req.PricePerUnit
+	price.Unit = req.Unit
+	price.Multiplier = req.Multiplier
+	price.EffectiveFrom = req.EffectiveFrom
+	price.EffectiveTo = req.EffectiveTo
+
+	err = audited(c, auditRepo, models.AuditUpdate, "price", price.ID, before, price, func(ctx context.Context) error {
+		return repo.Update(ctx, price)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to update price"))
+		return
+	}
+
+	c.JSON(http.StatusOK, price)
+}
+
+func DeletePrice(c *gin.Context, repo *repository.PriceRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage prices"))
+		return
+	}
+
+	before, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load price"))
+		return
+	}
+	err = audited(c, auditRepo, models.AuditDelete, "price", c.Param("id"), before, nil, func(ctx context.Context) error {
+		return repo.Delete(ctx, before.ID)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to delete price"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,32 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
-	// "agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
 	"agri-sync-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetFarmerProfile(c *gin.Context, repo *repository.FarmerRepository) {
+func GetFarmerProfile(c *gin.Context, repo repository.FarmerStore) {
 	id := c.Param("id")
 
 	// Check if authenticated user is requesting their own profile
 	userIDVal, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		apierr.Abort(c, apierr.New(apierr.Unauthenticated, "Authentication required"))
 		return
 	}
 	userID := userIDVal.(string)
 
 	if id != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own profile"})
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "You can only view your own profile"))
 		return
 	}
 
-	farmer, err := repo.GetByID(id)
+	farmer, err := repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Farmer not found or error: " + err.Error()})
+		apierr.Abort(c, apierr.From(err, "Failed to load farmer"))
 		return
 	}
 
@@ -35,6 +37,8 @@ func GetFarmerProfile(c *gin.Context, repo *repository.FarmerRepository) {
 		"id":         farmer.ID,
 		"name":       farmer.Name,
 		"phone":      farmer.Phone,
+		"latitude":   farmer.Latitude,
+		"longitude":  farmer.Longitude,
 		"created_at": farmer.CreatedAt,
 		"updated_at": farmer.UpdatedAt,
 		"version":    farmer.Version,
@@ -45,25 +49,71 @@ func GetFarmerProfile(c *gin.Context, repo *repository.FarmerRepository) {
 	})
 }
 
-func GetCollectorProfile(c *gin.Context, repo *repository.CollectorRepository) {
+type FarmLocationRequest struct {
+	Latitude  *float64 `json:"latitude" binding:"required,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" binding:"required,gte=-180,lte=180"`
+}
+
+// SetFarmerLocation registers where a farmer's farm is. Geotagged deliveries
+// from that farmer are checked against it. Farmers set their own; admins can
+// set anyone's.
+func SetFarmerLocation(c *gin.Context, repo repository.FarmerStore, auditRepo *repository.AuditRepository) {
+	id := c.Param("id")
+
+	roleVal, _ := c.Get("role")
+	userIDVal, _ := c.Get("userId")
+	if roleVal != "admin" && id != userIDVal {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "You can only set your own farm location"))
+		return
+	}
+
+	var req FarmLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	before, err := repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load farmer"))
+		return
+	}
+
+	err = audited(c, auditRepo, models.AuditUpdate, "farmer", id, before,
+		gin.H{"latitude": *req.Latitude, "longitude": *req.Longitude}, func(ctx context.Context) error {
+			return repo.SetLocation(ctx, id, *req.Latitude, *req.Longitude)
+		})
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to save farm location"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":        id,
+		"latitude":  *req.Latitude,
+		"longitude": *req.Longitude,
+	})
+}
+
+func GetCollectorProfile(c *gin.Context, repo repository.CollectorStore) {
 	id := c.Param("id")
 
 	// Check if authenticated user is requesting their own profile
 	userIDVal, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		apierr.Abort(c, apierr.New(apierr.Unauthenticated, "Authentication required"))
 		return
 	}
 	userID := userIDVal.(string)
 
 	if id != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own profile"})
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "You can only view your own profile"))
 		return
 	}
 
-	collector, err := repo.GetByID(id)
+	collector, err := repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Collector not found or error: " + err.Error()})
+		apierr.Abort(c, apierr.From(err, "Failed to load collector"))
 		return
 	}
 
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseReportRange reads ?from= and ?to= (RFC3339). Defaults to the last
+// 30 days.
+func parseReportRange(c *gin.Context) (from, to time.Time, ok bool) {
+	to = time.Now().UTC()
+	from = to.AddDate(0, 0, -30)
+
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			apierr.Abort(c, apierr.Invalid("from", apierr.RuleFormat, "from must be an RFC3339 timestamp"))
+			return from, to, false
+		}
+		from = parsed.UTC()
+	}
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			apierr.Abort(c, apierr.Invalid("to", apierr.RuleFormat, "to must be an RFC3339 timestamp"))
+			return from, to, false
+		}
+		to = parsed.UTC()
+	}
+	return from, to, true
+}
+
+// GetCropReport totals deliveries per crop in kg, whatever unit they were
+// measured in.
+func GetCropReport(c *gin.Context, repo repository.CollectionStore) {
+	if role, _ := c.Get("role"); role != "admin" && role != "collector" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can view reports"))
+		return
+	}
+
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	totals, err := repo.TotalsByCrop(c.Request.Context(), from, to)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to build report"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":   from.Format(time.RFC3339),
+		"to":     to.Format(time.RFC3339),
+		"totals": totals,
+	})
+}
+
+// GetCenterReport totals deliveries per center and crop in kg so tonnage
+// can be reconciled per center.
+func GetCenterReport(c *gin.Context, repo repository.CollectionStore) {
+	if role, _ := c.Get("role"); role != "admin" && role != "collector" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can view reports"))
+		return
+	}
+
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	totals, err := repo.TotalsByCenter(c.Request.Context(), from, to)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to build report"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":   from.Format(time.RFC3339),
+		"to":     to.Format(time.RFC3339),
+		"totals": totals,
+	})
+}
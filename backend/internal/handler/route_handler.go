@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RouteStopRequest struct {
+	CenterID    string   `json:"center_id" binding:"required"`
+	PlannedTime string   `json:"planned_time"` // HH:MM
+	FarmerIDs   []string `json:"farmer_ids"`
+}
+
+type RouteRequest struct {
+	Code        string             `json:"code" binding:"required"`
+	Name        string             `json:"name" binding:"required"`
+	CollectorID string             `json:"collector_id"`
+	Days        []string           `json:"days" binding:"dive,oneof=sun mon tue wed thu fri sat"`
+	Active      *bool              `json:"active"`
+	Stops       []RouteStopRequest `json:"stops" binding:"required,min=1,dive"`
+}
+
+// stops turns the request's stops into models, numbering them in the order
+// given, after checking every center exists.
+func (req *RouteRequest) stops(ctx context.Context, centerRepo *repository.CenterRepository) ([]*models.RouteStop, *apierr.Error) {
+	stops := make([]*models.RouteStop, 0, len(req.Stops))
+	for i, s := range req.Stops {
+		if _, err := centerRepo.GetByID(ctx, s.CenterID); err != nil {
+			if err == repository.ErrCenterNotFound {
+				return nil, apierr.Unprocessable(fmt.Sprintf("stops[%d].center_id", i), apierr.RuleUnknown, "Unknown collection center")
+			}
+			return nil, apierr.Internal(err, "Failed to load collection center")
+		}
+		if s.PlannedTime != "" {
+			if _, err := time.Parse("15:04", s.PlannedTime); err != nil {
+				return nil, apierr.Unprocessable(fmt.Sprintf("stops[%d].planned_time", i), apierr.RuleFormat, "planned_time must be HH:MM")
+			}
+		}
+		stops = append(stops, &models.RouteStop{
+			ID:          uuid.New().String(),
+			CenterID:    s.CenterID,
+			Position:    i + 1,
+			PlannedTime: s.PlannedTime,
+			FarmerIDs:   s.FarmerIDs,
+		})
+	}
+	return stops, nil
+}
+
+func ListRoutes(c *gin.Context, repo *repository.RouteRepository) {
+	role, _ := c.Get("role")
+	if role != "admin" && role != "collector" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can view routes"))
+		return
+	}
+
+	// Collectors only see their own routes
+	collectorID := c.Query("collector_id")
+	if role == "collector" {
+		userIDVal, _ := c.Get("userId")
+		collectorID = userIDVal.(string)
+	}
+
+	routes, err := repo.List(c.Request.Context(), collectorID)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list routes"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"routes": routes,
+		"count":  len(routes),
+	})
+}
+
+func CreateRoute(c *gin.Context, repo *repository.RouteRepository, centerRepo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage routes"))
+		return
+	}
+
+	var req RouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	stops, invalid := req.stops(c.Request.Context(), centerRepo)
+	if invalid != nil {
+		apierr.Abort(c, invalid)
+		return
+	}
+
+	route := &models.Route{
+		ID:          uuid.New().String(),
+		Code:        req.Code,
+		Name:        req.Name,
+		CollectorID: req.CollectorID,
+		Days:        req.Days,
+		Active:      req.Active == nil || *req.Active,
+		Stops:       stops,
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "route", route.ID, nil, route, func(ctx context.Context) error {
+		return repo.Create(ctx, route)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create route"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, route)
+}
+
+func UpdateRoute(c *gin.Context, repo *repository.RouteRepository, centerRepo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage routes"))
+		return
+	}
+
+	var req RouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	stops, invalid := req.stops(c.Request.Context(), centerRepo)
+	if invalid != nil {
+		apierr.Abort(c, invalid)
+		return
+	}
+
+	route, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load route"))
+		return
+	}
+
+	before := snapshot(route)
+	route.Code = req.Code
+	route.Name = req.Name
+	route.CollectorID = req.CollectorID
+	route.Days = req.Days
+	if req.Active != nil {
+		route.Active = *req.Active
+	}
+	route.Stops = stops
+
+	err = audited(c, auditRepo, models.AuditUpdate, "route", route.ID, before, route, func(ctx context.Context) error {
+		return repo.Update(ctx, route)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to update route"))
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+// GetRouteOfTheDay returns the active routes a collector runs on ?date=
+// (YYYY-MM-DD, default today) with each stop's center and the farmers
+// expected there, so the device can pre-load them before going offline.
+// Admins may pass ?collector_id=.
+func GetRouteOfTheDay(c *gin.Context, repo *repository.RouteRepository, centerRepo *repository.CenterRepository) {
+	role, _ := c.Get("role")
+	userIDVal, _ := c.Get("userId")
+	collectorID := userIDVal.(string)
+	switch role {
+	case "collector":
+	case "admin":
+		collectorID = c.Query("collector_id")
+		if collectorID == "" {
+			apierr.Abort(c, apierr.Invalid("collector_id", apierr.RuleRequired, "collector_id is required"))
+			return
+		}
+	default:
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can view routes"))
+		return
+	}
+
+	day := time.Now().UTC()
+	if v := c.Query("date"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			apierr.Abort(c, apierr.Invalid("date", apierr.RuleFormat, "date must be YYYY-MM-DD"))
+			return
+		}
+		day = parsed
+	}
+
+	routes, err := repo.List(c.Request.Context(), collectorID)
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list routes"))
+		return
+	}
+
+	today := []*models.Route{}
+	for _, route := range routes {
+		if !route.Active || !route.RunsOn(day) {
+			continue
+		}
+		for _, stop := range route.Stops {
+			if stop.Center, err = centerRepo.GetByID(c.Request.Context(), stop.CenterID); err != nil {
+				apierr.Abort(c, apierr.Internal(err, "Failed to load center"))
+				return
+			}
+			if stop.Farmers, err = repo.ExpectedFarmers(c.Request.Context(), stop.ID); err != nil {
+				apierr.Abort(c, apierr.Internal(err, "Failed to load expected farmers"))
+				return
+			}
+		}
+		today = append(today, route)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collector_id": collectorID,
+		"date":         day.Format("2006-01-02"),
+		"routes":       today,
+		"count":        len(today),
+	})
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+	"agri-sync-backend/internal/scale"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxClockSkew is how far in the future a reading's measured_at may be,
+// allowing for scales whose clocks drift.
+const maxClockSkew = 5 * time.Minute
+
+type ScaleRequest struct {
+	Serial   string `json:"serial" binding:"required"`
+	Name     string `json:"name"`
+	CenterID string `json:"center_id"`
+	Active   *bool  `json:"active"`
+}
+
+func ListScales(c *gin.Context, repo *repository.ScaleRepository) {
+	role, _ := c.Get("role")
+	if role != "admin" && role != "collector" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can view scales"))
+		return
+	}
+
+	scales, err := repo.List(c.Request.Context())
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list scales"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scales": scales,
+		"count":  len(scales),
+	})
+}
+
+// CreateScale registers a scale and returns its signing secret. This is the
+// only time the secret is shown; it must be provisioned onto the scale.
+func CreateScale(c *gin.Context, repo *repository.ScaleRepository, centerRepo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can register scales"))
+		return
+	}
+
+	var req ScaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if req.CenterID != "" {
+		if _, err := centerRepo.GetByID(c.Request.Context(), req.CenterID); err != nil {
+			if err == repository.ErrCenterNotFound {
+				apierr.Abort(c, apierr.Unprocessable("center_id", apierr.RuleUnknown, "Unknown collection center"))
+				return
+			}
+			apierr.Abort(c, apierr.Internal(err, "Failed to load collection center"))
+			return
+		}
+	}
+
+	secret, err := scale.NewSecret()
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to generate scale secret"))
+		return
+	}
+
+	sc := &models.Scale{
+		ID:       uuid.New().String(),
+		Serial:   req.Serial,
+		Name:     req.Name,
+		CenterID: req.CenterID,
+		Secret:   secret,
+		Active:   req.Active == nil || *req.Active,
+	}
+
+	err = audited(c, auditRepo, models.AuditCreate, "scale", sc.ID, nil, sc, func(ctx context.Context) error {
+		return repo.Create(ctx, sc)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to register scale"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"scale":  sc,
+		"secret": secret,
+	})
+}
+
+func UpdateScale(c *gin.Context, repo *repository.ScaleRepository, centerRepo *repository.CenterRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage scales"))
+		return
+	}
+
+	var req ScaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if req.CenterID != "" {
+		if _, err := centerRepo.GetByID(c.Request.Context(), req.CenterID); err != nil {
+			if err == repository.ErrCenterNotFound {
+				apierr.Abort(c, apierr.Unprocessable("center_id", apierr.RuleUnknown, "Unknown collection center"))
+				return
+			}
+			apierr.Abort(c, apierr.Internal(err, "Failed to load collection center"))
+			return
+		}
+	}
+
+	sc, err := repo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load scale"))
+		return
+	}
+
+	before := snapshot(sc)
+	sc.Serial = req.Serial
+	sc.Name = req.Name
+	sc.CenterID = req.CenterID
+	if req.Active != nil {
+		sc.Active = *req.Active
+	}
+
+	err = audited(c, auditRepo, models.AuditUpdate, "scale", sc.ID, before, sc, func(ctx context.Context) error {
+		return repo.Update(ctx, sc)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to update scale"))
+		return
+	}
+
+	c.JSON(http.StatusOK, sc)
+}
+
+// SubmitScaleReading accepts a signed reading relayed from a scale. The
+// returned ID is what a collection references as scale_reading_id.
+func SubmitScaleReading(c *gin.Context, repo *repository.ScaleRepository, auditRepo *repository.AuditRepository) {
+	role, _ := c.Get("role")
+	if role != "admin" && role != "collector" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can submit scale readings"))
+		return
+	}
+	userIDVal, _ := c.Get("userId")
+
+	var req scale.Reading
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	sc, err := repo.GetByID(c.Request.Context(), req.ScaleID)
+	if err != nil {
+		if err == repository.ErrScaleNotFound {
+			apierr.Abort(c, apierr.Unprocessable("scale_id", apierr.RuleUnknown, "Unknown scale"))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load scale"))
+		return
+	}
+	if !sc.Active {
+		apierr.Abort(c, apierr.Unprocessable("scale_id", apierr.RuleInactive, "Scale is deactivated"))
+		return
+	}
+	if !req.Verify(sc.Secret) {
+		apierr.Abort(c, apierr.New(apierr.InvalidSignature, "Invalid reading signature"))
+		return
+	}
+	if !req.Consistent() {
+		apierr.Abort(c, apierr.Unprocessable("net_kg", apierr.RuleMismatch, "net_kg must equal gross_kg - tare_kg"))
+		return
+	}
+	if req.MeasuredAt.After(time.Now().Add(maxClockSkew)) {
+		apierr.Abort(c, apierr.Unprocessable("measured_at", apierr.RuleRange, "measured_at is in the future"))
+		return
+	}
+
+	reading := &models.ScaleReading{
+		ID:          uuid.New().String(),
+		ScaleID:     req.ScaleID,
+		Sequence:    req.Sequence,
+		GrossKg:     req.GrossKg,
+		TareKg:      req.TareKg,
+		NetKg:       req.NetKg,
+		MeasuredAt:  req.MeasuredAt.UTC(),
+		Signature:   req.Signature,
+		SubmittedBy: userIDVal.(string),
+	}
+
+	err = audited(c, auditRepo, models.AuditCreate, "scale_reading", reading.ID, nil, reading, func(ctx context.Context) error {
+		return repo.RecordReading(ctx, reading)
+	})
+	if err != nil {
+		if err == repository.ErrStaleSequence {
+			apierr.Abort(c, apierr.New(apierr.StaleSequence, "Reading sequence must be greater than the last accepted one").With("last_sequence", sc.LastSequence))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to record reading"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, reading)
+}
+
+func GetScaleReading(c *gin.Context, repo *repository.ScaleRepository) {
+	role, _ := c.Get("role")
+	if role != "admin" && role != "collector" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only collectors and admins can view scale readings"))
+		return
+	}
+
+	reading, err := repo.GetReading(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierr.Abort(c, apierr.From(err, "Failed to load scale reading"))
+		return
+	}
+
+	c.JSON(http.StatusOK, reading)
+}
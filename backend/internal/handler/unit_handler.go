@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UnitRequest struct {
+	Code      string `json:"code" binding:"required"`
+	NameEn    string `json:"name_en" binding:"required"`
+	NameSw    string `json:"name_sw"`
+	Dimension string `json:"dimension" binding:"required,oneof=mass volume count"`
+}
+
+type UnitConversionRequest struct {
+	CropType string  `json:"crop_type"` // empty = every crop
+	Unit     string  `json:"unit" binding:"required"`
+	ToKg     float64 `json:"to_kg" binding:"required,gt=0"`
+}
+
+// ListUnits returns units and their kg conversions so devices can convert
+// and price deliveries offline.
+func ListUnits(c *gin.Context, repo *repository.UnitRepository) {
+	units, err := repo.List(c.Request.Context())
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list units"))
+		return
+	}
+	conversions, err := repo.ListConversions(c.Request.Context())
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to list unit conversions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"base_unit":   models.BaseUnit,
+		"units":       units,
+		"conversions": conversions,
+	})
+}
+
+func CreateUnit(c *gin.Context, repo *repository.UnitRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage units"))
+		return
+	}
+
+	var req UnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+
+	if _, err := repo.GetByCode(c.Request.Context(), req.Code); err == nil {
+		apierr.Abort(c, apierr.New(apierr.AlreadyExists, "Unit already exists"))
+		return
+	}
+
+	unit := &models.Unit{
+		Code:      req.Code,
+		NameEn:    req.NameEn,
+		NameSw:    req.NameSw,
+		Dimension: req.Dimension,
+	}
+
+	err := audited(c, auditRepo, models.AuditCreate, "unit", unit.Code, nil, unit, func(ctx context.Context) error {
+		return repo.Create(ctx, unit)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to create unit"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, unit)
+}
+
+// SaveUnitConversion sets how many kg one unit of a crop weighs, e.g. the
+// density of milk or the weight of a bag of coffee cherry.
+func SaveUnitConversion(c *gin.Context, repo *repository.UnitRepository, auditRepo *repository.AuditRepository) {
+	if role, _ := c.Get("role"); role != "admin" {
+		apierr.Abort(c, apierr.New(apierr.Forbidden, "Only admins can manage units"))
+		return
+	}
+
+	var req UnitConversionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Abort(c, apierr.BadRequest(err))
+		return
+	}
+	if req.Unit == models.BaseUnit && req.ToKg != 1 {
+		apierr.Abort(c, apierr.Invalid("to_kg", apierr.RuleMismatch, "The base unit always converts 1:1"))
+		return
+	}
+
+	if _, err := repo.GetByCode(c.Request.Context(), req.Unit); err != nil {
+		if err == repository.ErrUnitNotFound {
+			apierr.Abort(c, apierr.Unprocessable("unit", apierr.RuleUnknown, "Unknown unit: "+req.Unit))
+			return
+		}
+		apierr.Abort(c, apierr.Internal(err, "Failed to load unit"))
+		return
+	}
+
+	var before *models.UnitConversion
+	conversions, err := repo.ListConversions(c.Request.Context())
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to load unit conversions"))
+		return
+	}
+	for _, existing := range conversions {
+		if existing.CropType == repository.NormalizeCropCode(req.CropType) && existing.Unit == req.Unit {
+			before = existing
+		}
+	}
+
+	conversion := &models.UnitConversion{
+		CropType: repository.NormalizeCropCode(req.CropType),
+		Unit:     req.Unit,
+		ToKg:     req.ToKg,
+	}
+
+	action := models.AuditUpdate
+	if before == nil {
+		action = models.AuditCreate
+	}
+	err = audited(c, auditRepo, action, "unit_conversion", conversion.CropType+"/"+conversion.Unit, before, conversion, func(ctx context.Context) error {
+		return repo.SaveConversion(ctx, conversion)
+	})
+	if err != nil {
+		apierr.Abort(c, apierr.Internal(err, "Failed to save unit conversion"))
+		return
+	}
+
+	c.JSON(http.StatusOK, conversion)
+}
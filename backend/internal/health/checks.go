@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"agri-sync-backend/internal/database"
+	"agri-sync-backend/internal/repository"
+	"agri-sync-backend/internal/worker"
+)
+
+// Database pings every pool of db.
+func Database(db *database.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) (string, error) {
+		if err := db.PingContext(ctx); err != nil {
+			return "", err
+		}
+		return db.Driver() + " reachable", nil
+	})
+}
+
+// DatabaseWrite commits a write, so a full disk, a read-only file or a
+// writer stuck behind a lock shows up before requests fail.
+func DatabaseWrite(repo *repository.HealthRepository) Checker {
+	return CheckerFunc(func(ctx context.Context) (string, error) {
+		if err := repo.ProbeWrite(ctx); err != nil {
+			return "", err
+		}
+		return "write committed", nil
+	})
+}
+
+// Migrations checks that the schema is at this build's latest version and
+// not dirty.
+func Migrations(db *database.DB, driver string) Checker {
+	return CheckerFunc(func(ctx context.Context) (string, error) {
+		latest, err := database.LatestVersion(driver)
+		if err != nil {
+			return "", err
+		}
+		version, dirty, err := database.MigrationStatus(db, driver)
+		if err != nil {
+			return "", err
+		}
+		detail := fmt.Sprintf("version %d of %d", version, latest)
+		switch {
+		case dirty:
+			return detail, fmt.Errorf("schema is dirty at version %d", version)
+		case version != latest:
+			return detail, fmt.Errorf("schema is at version %d, this build expects %d", version, latest)
+		}
+		return detail, nil
+	})
+}
+
+// DiskSpace checks that the file system holding dir has at least min
+// bytes free.
+func DiskSpace(dir string, min int64) Checker {
+	return CheckerFunc(func(ctx context.Context) (string, error) {
+		free, err := freeBytes(dir)
+		if err != nil {
+			return "", err
+		}
+		detail := fmt.Sprintf("%s free in %s", formatBytes(free), dir)
+		if free < uint64(min) {
+			return detail, fmt.Errorf("less than %s free", formatBytes(uint64(min)))
+		}
+		return detail, nil
+	})
+}
+
+// Workers checks that no background job has stalled. A job whose last run
+// failed is still alive; its error is in the detail.
+func Workers(g *worker.Group) Checker {
+	return CheckerFunc(func(ctx context.Context) (string, error) {
+		jobs := g.Jobs()
+		if len(jobs) == 0 {
+			return "no background jobs", nil
+		}
+		now := time.Now()
+		var details []string
+		var stalled []error
+		for _, j := range jobs {
+			state := "idle"
+			if j.Running {
+				state = "running"
+			}
+			detail := fmt.Sprintf("%s %s for %s", j.Name, state, now.Sub(j.Since).Round(time.Second))
+			if j.LastError != nil {
+				detail += fmt.Sprintf(", last run failed: %v", j.LastError)
+			}
+			details = append(details, detail)
+			if j.Stalled(now) {
+				stalled = append(stalled, fmt.Errorf("%s has not started or finished a run in %s (every %s)",
+					j.Name, now.Sub(j.Since).Round(time.Second), j.Interval))
+			}
+		}
+		return strings.Join(details, "; "), errors.Join(stalled...)
+	})
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
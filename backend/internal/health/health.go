@@ -0,0 +1,115 @@
+// Package health runs the checks behind /healthz and /readyz. A check is
+// anything with a Check method; each registry runs its checks at once,
+// each within the registry's timeout.
+package health
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Checker checks one thing. It returns a short description of what it
+// saw, and an error if that is unhealthy.
+type Checker interface {
+	Check(ctx context.Context) (detail string, err error)
+}
+
+// CheckerFunc lets a plain function be a Checker.
+type CheckerFunc func(ctx context.Context) (string, error)
+
+func (f CheckerFunc) Check(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// Result statuses
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+var errTimedOut = errors.New("check timed out")
+
+// Result is the outcome of one check.
+type Result struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Detail     string  `json:"detail,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// Report is the outcome of every check in a registry. Its Status is
+// StatusFail if any check failed.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Registry is a named set of checks.
+type Registry struct {
+	timeout time.Duration
+	names   []string
+	checks  []Checker
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// Add registers a check. Results are reported in the order added.
+func (r *Registry) Add(name string, c Checker) {
+	r.names = append(r.names, name)
+	r.checks = append(r.checks, c)
+}
+
+// Run runs every check at once. A check still running at the timeout is
+// reported as failed; Run does not wait for it.
+func (r *Registry) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	type outcome struct {
+		i      int
+		detail string
+		err    error
+		took   time.Duration
+	}
+	done := make(chan outcome, len(r.checks))
+	for i, c := range r.checks {
+		go func() {
+			start := time.Now()
+			detail, err := c.Check(ctx)
+			done <- outcome{i, detail, err, time.Since(start)}
+		}()
+	}
+
+	report := Report{Status: StatusOK, Checks: make([]Result, len(r.checks))}
+	for i, name := range r.names {
+		report.Checks[i] = Result{Name: name, Status: StatusFail, Error: errTimedOut.Error(), DurationMs: ms(r.timeout)}
+	}
+	for range r.checks {
+		var o outcome
+		select {
+		case o = <-done:
+		case <-ctx.Done():
+			report.Status = StatusFail
+			return report
+		}
+		res := &report.Checks[o.i]
+		res.Detail, res.DurationMs, res.Status, res.Error = o.detail, ms(o.took), StatusOK, ""
+		if o.err != nil {
+			res.Status, res.Error = StatusFail, o.err.Error()
+		}
+	}
+	for _, res := range report.Checks {
+		if res.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces a value logged under a sensitive key.
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are parts of attribute (and JSON field) names whose values
+// are never logged, whatever they look like.
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "phone", "msisdn", "apikey"}
+
+// sensitiveKey reports whether a value under key must not be logged.
+// Matching ignores case, "_" and "-": "jwt_secret", "PasswordHash" and
+// "X-Auth-Token" are all sensitive.
+func sensitiveKey(key string) bool {
+	k := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return strings.HasSuffix(k, "hash")
+}
+
+// scrubbers find secrets and phone numbers inside free text, such as
+// messages and errors. Bearer tokens go before JWTs so the whole header
+// value is caught.
+var scrubbers = []struct {
+	re   *regexp.Regexp
+	with string
+}{
+	{regexp.MustCompile(`(?i)\bbearer\s+\S+`), "Bearer [TOKEN]"},
+	{regexp.MustCompile(`\beyJ[\w-]+\.[\w-]+\.[\w-]*`), "[TOKEN]"},
+	{regexp.MustCompile(`\$2[abxy]?\$\d\d\$[./A-Za-z0-9]{53}`), "[HASH]"},
+	// +254 712 345 678, 0712-345-678
+	{regexp.MustCompile(`\+?\b254[ -]?\d{3}[ -]?\d{3}[ -]?\d{3}\b`), "[PHONE]"},
+	{regexp.MustCompile(`\b0[17]\d{2}[ -]\d{3}[ -]?\d{3}\b`), "[PHONE]"},
+	// Any other run of 9 to 15 digits, except the tail of a UUID or other
+	// dashed id
+	{regexp.MustCompile(`(^|[^\w+-])(\+?\d{9,15})\b`), "${1}[PHONE]"},
+}
+
+func scrub(s string) string {
+	for _, sc := range scrubbers {
+		s = sc.re.ReplaceAllString(s, sc.with)
+	}
+	return s
+}
+
+// idKey reports whether key holds an id (request_id, user_id, ...). Ids
+// are UUIDs and the like, and are left as they are.
+func idKey(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), "id")
+}
+
+// redactAttr is the handlers' ReplaceAttr: it applies the rules above to
+// every attribute, the message included.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if sensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if !idKey(a.Key) {
+			a.Value = slog.StringValue(scrub(a.Value.String()))
+		}
+	case slog.KindAny:
+		a.Value = redactAny(a.Value.Any())
+	}
+	return a
+}
+
+// redactAny redacts an error's text, or a struct, map or slice by way of
+// its JSON form, so a logged model's phone field is caught too.
+func redactAny(v any) slog.Value {
+	if err, ok := v.(error); ok {
+		return slog.StringValue(scrub(err.Error()))
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return slog.StringValue(scrub(fmt.Sprint(v)))
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return slog.StringValue(scrub(string(b)))
+	}
+	return slog.AnyValue(redactTree(tree))
+}
+
+func redactTree(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, x := range v {
+			if sensitiveKey(k) {
+				v[k] = redacted
+			} else {
+				v[k] = redactTree(x)
+			}
+		}
+	case []any:
+		for i, x := range v {
+			v[i] = redactTree(x)
+		}
+	case string:
+		return scrub(v)
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"agri-sync-backend/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// business reads its gauges from the database on each scrape, so they are
+// right however many API instances share it.
+var business = &businessCollector{
+	collectionsToday: prometheus.NewDesc(namespace+"_collections_today",
+		"Collections recorded since midnight (server time), excluding rejected ones.", nil, nil),
+	kgToday: prometheus.NewDesc(namespace+"_collected_kg_today",
+		"Kilograms collected since midnight (server time), excluding rejected collections, by crop.", []string{"crop"}, nil),
+	pendingPayout: prometheus.NewDesc(namespace+"_pending_payout",
+		"Value owed to farmers and not yet paid, in the configured currency.", nil, nil),
+}
+
+type businessSource struct {
+	collections repository.CollectionStore
+	stats       *repository.StatsRepository
+}
+
+type businessCollector struct {
+	source atomic.Pointer[businessSource]
+
+	collectionsToday *prometheus.Desc
+	kgToday          *prometheus.Desc
+	pendingPayout    *prometheus.Desc
+}
+
+// ReportBusiness has scrapes include the business gauges, read from
+// collections and stats. Until it is called they are left out.
+func ReportBusiness(collections repository.CollectionStore, stats *repository.StatsRepository) {
+	business.source.Store(&businessSource{collections: collections, stats: stats})
+}
+
+func (b *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- b.collectionsToday
+	ch <- b.kgToday
+	ch <- b.pendingPayout
+}
+
+func (b *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	src := b.source.Load()
+	if src == nil {
+		return
+	}
+	ctx := context.Background()
+
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	totals, err := src.collections.TotalsByCrop(ctx, midnight, midnight.AddDate(0, 0, 1))
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(b.collectionsToday, err)
+	} else {
+		var count int
+		for _, t := range totals {
+			count += t.Count
+			ch <- prometheus.MustNewConstMetric(b.kgToday, prometheus.GaugeValue, t.TotalQuantity, t.CropType)
+		}
+		ch <- prometheus.MustNewConstMetric(b.collectionsToday, prometheus.GaugeValue, float64(count))
+	}
+
+	pending, err := src.stats.PendingPayout(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(b.pendingPayout, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(b.pendingPayout, prometheus.GaugeValue, pending)
+	}
+}
@@ -0,0 +1,108 @@
+// Package metrics holds the API's Prometheus metrics. Handlers and
+// middleware record into them through the functions here; Handler serves
+// them on /metrics.
+package metrics
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "agrisync"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to serve an HTTP request, by method, route and status.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route", "status"})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time a repository call spent on the database, by repository method.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5},
+	}, []string{"method"})
+
+	syncBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_batch_size",
+		Help:      "Queued offline changes a device uploaded in one sync run.",
+		Buckets:   []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+	})
+
+	syncConflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_conflicts_total",
+		Help:      "Updates rejected because the client's version was stale, by entity.",
+	}, []string{"entity"})
+
+	loginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Failed logins, by role and reason.",
+	}, []string{"role", "reason"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, dbDuration,
+		syncBatchSize, syncConflicts, loginFailures,
+		business,
+	)
+}
+
+// Handler serves every metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		// A failing business query should not hide the other metrics
+		ErrorHandling: promhttp.ContinueOnError,
+		ErrorLog:      log.Default(),
+	})
+}
+
+// ObserveRequest records a served request. route is the route pattern,
+// such as "/collections/:id", so that ids don't each get a series.
+func ObserveRequest(method, route string, status int, took time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(took.Seconds())
+}
+
+// ObserveQuery records a repository call; it is the repository package's
+// query observer.
+func ObserveQuery(method string, took time.Duration) {
+	dbDuration.WithLabelValues(method).Observe(took.Seconds())
+}
+
+// ObserveSyncBatch records the size of a device's sync run.
+func ObserveSyncBatch(size int) {
+	syncBatchSize.Observe(float64(size))
+}
+
+// SyncConflict counts an update rejected for a stale version of entity.
+func SyncConflict(entity string) {
+	syncConflicts.WithLabelValues(entity).Inc()
+}
+
+// LoginFailure counts a failed login.
+func LoginFailure(role, reason string) {
+	loginFailures.WithLabelValues(role, reason).Inc()
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type AdjustmentKind string
+
+const (
+	AdjustmentRegrade AdjustmentKind = "regrade"
+	AdjustmentDispute AdjustmentKind = "dispute" // settles an upheld dispute
+)
+
+// Adjustment is an append-only correction to a collection. The original
+// collection row is never rewritten; its value is the original amount plus
+// the sum of its adjustments.
+type Adjustment struct {
+	ID           string         `json:"id" db:"id"` // UUID
+	CollectionID string         `json:"collection_id" db:"collection_id"`
+	Kind         AdjustmentKind `json:"kind" db:"kind"`
+
+	PreviousWeightKg   float64 `json:"previous_weight_kg" db:"previous_weight_kg"`
+	NewWeightKg        float64 `json:"new_weight_kg" db:"new_weight_kg"`
+	PreviousGrade      string  `json:"previous_grade" db:"previous_grade"`
+	NewGrade           string  `json:"new_grade" db:"new_grade"`
+	PreviousPricePerKg float64 `json:"previous_price_per_kg" db:"previous_price_per_kg"`
+	NewPricePerKg      float64 `json:"new_price_per_kg" db:"new_price_per_kg"`
+	Amount             float64 `json:"amount" db:"amount"`
+
+	Reason    string `json:"reason,omitempty" db:"reason"`
+	CreatedBy string `json:"created_by" db:"created_by"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
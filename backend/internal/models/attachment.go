@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Attachment owners
+const (
+	OwnerCollection = "collection"
+	OwnerDispute    = "dispute"
+)
+
+// Attachment is a photo or document attached to a collection or dispute.
+// The content is stored in the blob store under SHA256.
+type Attachment struct {
+	ID         string `json:"id" db:"id"` // UUID
+	OwnerType  string `json:"owner_type" db:"owner_type"`
+	OwnerID    string `json:"owner_id" db:"owner_id"`
+	SHA256     string `json:"sha256" db:"sha256"`
+	SizeBytes  int64  `json:"size_bytes" db:"size_bytes"`
+	MimeType   string `json:"mime_type" db:"mime_type"`
+	Filename   string `json:"filename" db:"filename"`
+	UploadedBy string `json:"uploaded_by" db:"uploaded_by"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// UploadSession is a resumable upload in progress. Offset is how many bytes
+// have been received; it is not stored but read from the staged file.
+type UploadSession struct {
+	ID        string `json:"id" db:"id"` // UUID
+	OwnerType string `json:"owner_type" db:"owner_type"`
+	OwnerID   string `json:"owner_id" db:"owner_id"`
+	Filename  string `json:"filename" db:"filename"`
+	SizeBytes int64  `json:"size_bytes" db:"size_bytes"`
+	Offset    int64  `json:"offset"`
+	CreatedBy string `json:"created_by" db:"created_by"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditCreate      = "create"
+	AuditUpdate      = "update"
+	AuditDelete      = "delete"
+	AuditLogin       = "login"
+	AuditLoginFailed = "login_failed"
+)
+
+// AuditEntry records one change: who made it, from where, and the entity
+// before and after. Entries form a hash chain: Hash covers the entry and
+// the previous entry's hash, so editing or removing a row breaks the chain.
+type AuditEntry struct {
+	Seq int64     `json:"seq" db:"seq"`
+	At  time.Time `json:"at" db:"at"`
+
+	ActorID   string `json:"actor_id,omitempty" db:"actor_id"`
+	ActorRole string `json:"actor_role,omitempty" db:"actor_role"`
+	DeviceID  string `json:"device_id,omitempty" db:"device_id"` // X-Device-ID header
+	IP        string `json:"ip,omitempty" db:"ip"`
+	RequestID string `json:"request_id,omitempty" db:"request_id"`
+
+	Action     string          `json:"action" db:"action"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   string          `json:"entity_id,omitempty" db:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before"`
+	After      json.RawMessage `json:"after,omitempty" db:"after"`
+
+	PrevHash string `json:"prev_hash" db:"prev_hash"`
+	Hash     string `json:"hash" db:"hash"`
+}
+
+// AuditVerification is the result of walking the hash chain.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`  // entries checked
+	HeadSeq  int64  `json:"head_seq"` // last entry; record it to detect truncation later
+	HeadHash string `json:"head_hash"`
+	BrokenAt int64  `json:"broken_at,omitempty"` // first entry that fails
+	Problem  string `json:"problem,omitempty"`
+}
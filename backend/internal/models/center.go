@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// CollectionCenter is a fixed buying point run by the cooperative.
+type CollectionCenter struct {
+	ID        string   `json:"id" db:"id"` // UUID
+	Code      string   `json:"code" db:"code"`
+	Name      string   `json:"name" db:"name"`
+	Latitude  *float64 `json:"latitude,omitempty" db:"latitude"`
+	Longitude *float64 `json:"longitude,omitempty" db:"longitude"`
+	Region    string   `json:"region,omitempty" db:"region"` // used for regional catalog prices
+	Active    bool     `json:"active" db:"active"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Clerk reviews disputes and dispute reports. Clerks are added by admins
+// and sign in with their phone and password.
+type Clerk struct {
+	ID    string `json:"id" db:"id"` // UUID
+	Name  string `json:"name" db:"name"`
+	Phone string `json:"phone" db:"phone"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+
+	PasswordHash string `json:"-" db:"password_hash"`
+}
@@ -8,6 +8,15 @@ const (
 	StatusPending TransactionStatus = "pending"
 	StatusVerified TransactionStatus = "verified"
 	StatusPaid TransactionStatus = "paid"
+	StatusRejected TransactionStatus = "rejected"
+)
+
+// Review flags raised on a collection. Flagged records are accepted but
+// need a human to look at them.
+const (
+	FlagOutsideGeofence = "outside_geofence"
+	FlagLowAccuracy     = "low_accuracy"   // GPS fix less accurate than the geofence credits
+	FlagScaleMismatch   = "scale_mismatch" // weight differs from the referenced scale reading
 )
 
 type Collection struct {
@@ -15,11 +24,34 @@ type Collection struct {
 
 	FarmerID    string    `json:"farmer_id" db:"farmer_id"`
 	CollectorID string    `json:"collector_id" db:"collector_id"`
+	CenterID    string    `json:"center_id,omitempty" db:"center_id"` // collection center, if any
+	PlotID      string    `json:"plot_id,omitempty" db:"plot_id"`     // plot harvested from, if known
+	ScaleReadingID string `json:"scale_reading_id,omitempty" db:"scale_reading_id"` // signed weighing backing the weight
 
 	CropType    string    `json:"crop_type" db:"crop_type"`   // tea, coffee, milk
+
+	// What was measured, in the collector's unit (litres of milk, bags of cherry)
+	Quantity     float64 `json:"quantity" db:"quantity"`
+	Unit         string  `json:"unit" db:"unit"`
+	PricePerUnit float64 `json:"price_per_unit" db:"price_per_unit"`
+
+	// Normalized to the base unit (kg); kept for reports and legacy clients
 	WeightKg    float64   `json:"weight_kg" db:"weight_kg"`
 	PricePerKg  float64   `json:"price_per_kg" db:"price_per_kg"`
 
+	// Quality captured at collection time
+	Grade           string             `json:"grade,omitempty" db:"grade"`
+	QualityReadings map[string]float64 `json:"quality_readings,omitempty" db:"quality_readings"` // e.g. butterfat, density
+	RejectionReason string             `json:"rejection_reason,omitempty" db:"rejection_reason"`
+
+	// Where the device was when the delivery was recorded (optional)
+	Latitude           *float64   `json:"latitude,omitempty" db:"latitude"`
+	Longitude          *float64   `json:"longitude,omitempty" db:"longitude"`
+	LocationAccuracyM  *float64   `json:"location_accuracy_m,omitempty" db:"location_accuracy_m"`
+	LocationCapturedAt *time.Time `json:"location_captured_at,omitempty" db:"location_captured_at"`
+
+	Flags []string `json:"flags" db:"flags"` // review flags, e.g. outside_geofence
+
 	Verified        bool   `json:"verified" db:"verified"` // digital handshake complete
 	// FarmerSignature string `json:"farmer_signature" db:"farmer_signature"`
 	// CollectorSignature string `json:"collector_signature" db:"collector_signature"`
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// CropType is an entry in the managed crop registry. Collections, prices and
+// grades refer to it by Code.
+type CropType struct {
+	Code          string   `json:"code" db:"code"` // e.g. "tea", lowercase
+	NameEn        string   `json:"name_en" db:"name_en"`
+	NameSw        string   `json:"name_sw" db:"name_sw"`
+	Unit          string   `json:"unit" db:"unit"` // kg, litres
+	Active        bool     `json:"active" db:"active"`
+	AllowedGrades []string `json:"allowed_grades" db:"allowed_grades"` // empty = any defined grade
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+type DisputeStatus string
+
+const (
+	DisputeOpen     DisputeStatus = "open"
+	DisputeUpheld   DisputeStatus = "upheld"   // settled with an adjustment
+	DisputeRejected DisputeStatus = "rejected" // the recorded values stand
+)
+
+// Dispute is a farmer's challenge to the weight or price recorded on one of
+// their collections.
+type Dispute struct {
+	ID           string `json:"id" db:"id"` // UUID
+	CollectionID string `json:"collection_id" db:"collection_id"`
+	FarmerID     string `json:"farmer_id" db:"farmer_id"`
+	CollectorID  string `json:"collector_id" db:"collector_id"`
+
+	Reason             string   `json:"reason" db:"reason"`
+	ProposedWeightKg   *float64 `json:"proposed_weight_kg,omitempty" db:"proposed_weight_kg"`
+	ProposedPricePerKg *float64 `json:"proposed_price_per_kg,omitempty" db:"proposed_price_per_kg"`
+
+	Status       DisputeStatus `json:"status" db:"status"`
+	Resolution   string        `json:"resolution,omitempty" db:"resolution"`
+	AdjustmentID string        `json:"adjustment_id,omitempty" db:"adjustment_id"`
+	ResolvedBy   string        `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt   *time.Time    `json:"resolved_at,omitempty" db:"resolved_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
+
+// CollectorDisputeStats summarises the disputes raised against one
+// collector's records.
+type CollectorDisputeStats struct {
+	CollectorID   string  `json:"collector_id"`
+	CollectorName string  `json:"collector_name"`
+	Total         int     `json:"total"`
+	Open          int     `json:"open"`
+	Upheld        int     `json:"upheld"`
+	Rejected      int     `json:"rejected"`
+	UpheldRate    float64 `json:"upheld_rate"`    // upheld / resolved
+	AdjustedTotal float64 `json:"adjusted_total"` // sum of settlement amounts
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Farm is a farmer's holding. Boundary is a GeoJSON Polygon; AreaAcres is
+// computed from it when not entered.
+type Farm struct {
+	ID        string          `json:"id" db:"id"` // UUID (may be generated on client)
+	FarmerID  string          `json:"farmer_id" db:"farmer_id"`
+	Name      string          `json:"name" db:"name"`
+	AreaAcres float64         `json:"area_acres" db:"area_acres"`
+	Boundary  json.RawMessage `json:"boundary,omitempty" db:"boundary"`
+
+	Plots []*Plot `json:"plots"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	Version int `json:"version" db:"version"`
+}
+
+// Plot is the part of a farm under a single crop.
+type Plot struct {
+	ID         string          `json:"id" db:"id"` // UUID (may be generated on client)
+	FarmID     string          `json:"farm_id" db:"farm_id"`
+	Name       string          `json:"name" db:"name"`
+	CropType   string          `json:"crop_type" db:"crop_type"`
+	AreaAcres  float64         `json:"area_acres" db:"area_acres"`
+	Boundary   json.RawMessage `json:"boundary,omitempty" db:"boundary"`
+	StockCount int             `json:"stock_count" db:"stock_count"` // number of bushes, trees or cows
+	StockUnit  string          `json:"stock_unit,omitempty" db:"stock_unit"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
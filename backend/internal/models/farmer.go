@@ -6,7 +6,10 @@ type Farmer struct {
 	ID        string    `json:"id" db:"id"` // UUID (generated on client)
 	Name      string    `json:"name" db:"name"`
 	Phone     string    `json:"phone" db:"phone"`
-	// Location  string    `json:"location" db:"location"` // TODO: Remove this
+	// Registered home location; deliveries are geofenced against it and
+	// against the farmer's farms (see Farm)
+	Latitude  *float64 `json:"latitude,omitempty" db:"latitude"`
+	Longitude *float64 `json:"longitude,omitempty" db:"longitude"`
 
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
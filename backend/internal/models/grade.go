@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type GradeKind string
+
+const (
+	GradeKindGrade     GradeKind = "grade"     // e.g. tea leaf grade "A", "B"
+	GradeKindReading   GradeKind = "reading"   // e.g. milk butterfat or density
+	GradeKindRejection GradeKind = "rejection" // reason a delivery was refused
+)
+
+// Grade is a per-crop quality definition. Collectors pick grades and
+// rejection reasons from this list and record readings against it.
+type Grade struct {
+	ID       string    `json:"id" db:"id"` // UUID
+	CropType string    `json:"crop_type" db:"crop_type"`
+	Kind     GradeKind `json:"kind" db:"kind"`
+	Code     string    `json:"code" db:"code"`
+	Name     string    `json:"name" db:"name"`
+
+	// Readings only: unit and acceptable range
+	Unit     string   `json:"unit,omitempty" db:"unit"`
+	MinValue *float64 `json:"min_value,omitempty" db:"min_value"`
+	MaxValue *float64 `json:"max_value,omitempty" db:"max_value"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
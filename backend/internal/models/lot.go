@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+type LotStatus string
+
+const (
+	LotOpen       LotStatus = "open"       // still taking collections
+	LotDispatched LotStatus = "dispatched" // has left the center; sealed
+)
+
+// Lot is a batch of collections of one crop, aggregated at a center and
+// shipped onwards as a unit.
+type Lot struct {
+	ID        string    `json:"id" db:"id"` // UUID
+	Code      string    `json:"code" db:"code"`
+	CenterID  string    `json:"center_id,omitempty" db:"center_id"`
+	CropType  string    `json:"crop_type" db:"crop_type"`
+	WeightKg  float64   `json:"weight_kg" db:"weight_kg"` // sum of contributing collections
+	Status    LotStatus `json:"status" db:"status"`
+	CreatedBy string    `json:"created_by" db:"created_by"`
+
+	CollectionIDs []string       `json:"collection_ids"`
+	Transfers     []*LotTransfer `json:"transfers"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
+
+// Transfer destinations
+const (
+	TransferToCenter  = "center"
+	TransferToFactory = "factory"
+	TransferToBuyer   = "buyer"
+)
+
+// LotTransfer is one leg of a lot's journey. WeightInKg and ReceivedAt are
+// nil while the lot is in transit.
+type LotTransfer struct {
+	ID           string     `json:"id" db:"id"` // UUID
+	LotID        string     `json:"lot_id" db:"lot_id"`
+	Position     int        `json:"position" db:"position"`
+	FromName     string     `json:"from_name" db:"from_name"`
+	ToKind       string     `json:"to_kind" db:"to_kind"`
+	ToName       string     `json:"to_name" db:"to_name"`
+	WeightOutKg  float64    `json:"weight_out_kg" db:"weight_out_kg"`
+	WeightInKg   *float64   `json:"weight_in_kg,omitempty" db:"weight_in_kg"`
+	ShrinkageKg  *float64   `json:"shrinkage_kg,omitempty"` // out - in, once received
+	DispatchedBy string     `json:"dispatched_by" db:"dispatched_by"`
+	ReceivedBy   string     `json:"received_by,omitempty" db:"received_by"`
+	DispatchedAt time.Time  `json:"dispatched_at" db:"dispatched_at"`
+	ReceivedAt   *time.Time `json:"received_at,omitempty" db:"received_at"`
+}
+
+// LotTrace is the full provenance of a lot: where it went, and which
+// farmers, deliveries and plots it is made of.
+type LotTrace struct {
+	Lot *Lot `json:"lot"`
+
+	// Total loss from the collected weight to the last weight received
+	ShrinkageKg  float64 `json:"shrinkage_kg"`
+	ShrinkagePct float64 `json:"shrinkage_pct"`
+
+	Farmers []*TraceFarmer `json:"farmers"`
+}
+
+type TraceFarmer struct {
+	ID         string           `json:"id"`
+	Name       string           `json:"name"`
+	Phone      string           `json:"phone"`
+	WeightKg   float64          `json:"weight_kg"`
+	Deliveries []*TraceDelivery `json:"deliveries"`
+}
+
+type TraceDelivery struct {
+	Collection *Collection `json:"collection"`
+	Plot       *Plot       `json:"plot,omitempty"`
+}
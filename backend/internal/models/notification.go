@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Notification kinds
+const (
+	NotifyDisputeOpened   = "dispute_opened"
+	NotifyDisputeResolved = "dispute_resolved"
+)
+
+// Notification is an in-app message for a farmer, collector or admin.
+type Notification struct {
+	ID          string     `json:"id" db:"id"` // UUID
+	UserID      string     `json:"user_id" db:"user_id"`
+	Kind        string     `json:"kind" db:"kind"`
+	SubjectType string     `json:"subject_type" db:"subject_type"` // dispute
+	SubjectID   string     `json:"subject_id" db:"subject_id"`
+	Message     string     `json:"message" db:"message"`
+	ReadAt      *time.Time `json:"read_at,omitempty" db:"read_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Price is one row of the crop price catalog. Grade and Region are optional;
+// an empty value means the price applies to every grade / region.
+type Price struct {
+	ID       string `json:"id" db:"id"` // UUID
+	CropType string `json:"crop_type" db:"crop_type"`
+	Grade    string `json:"grade,omitempty" db:"grade"`
+	Region   string `json:"region,omitempty" db:"region"`
+	// Price per Unit, e.g. per kg of tea or per litre of milk
+	PricePerUnit float64 `json:"price_per_unit" db:"price_per_unit"`
+	Unit         string  `json:"unit" db:"unit"`
+	// Grade rows only: when set, the price is the base price times Multiplier
+	// and PricePerUnit is ignored.
+	Multiplier *float64 `json:"multiplier,omitempty" db:"multiplier"`
+
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" db:"effective_to"` // nil = open ended
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
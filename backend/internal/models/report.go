@@ -0,0 +1,16 @@
+package models
+
+// CropTotal is one row of a volume report, in the base unit.
+type CropTotal struct {
+	CropType      string  `json:"crop_type"`
+	Count         int     `json:"count"`
+	TotalQuantity float64 `json:"total_quantity"`
+	Unit          string  `json:"unit"`
+	TotalValue    float64 `json:"total_value"`
+}
+
+// CenterTotal is a CropTotal for a single collection center.
+type CenterTotal struct {
+	CenterID string `json:"center_id"`
+	CropTotal
+}
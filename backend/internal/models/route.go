@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Route is a truck run that visits centers in order. Days holds the
+// weekdays it runs on ("mon", "tue", ...); empty means every day.
+type Route struct {
+	ID          string   `json:"id" db:"id"` // UUID
+	Code        string   `json:"code" db:"code"`
+	Name        string   `json:"name" db:"name"`
+	CollectorID string   `json:"collector_id,omitempty" db:"collector_id"`
+	Days        []string `json:"days" db:"days"`
+	Active      bool     `json:"active" db:"active"`
+
+	Stops []*RouteStop `json:"stops"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
+
+type RouteStop struct {
+	ID          string   `json:"id" db:"id"` // UUID
+	RouteID     string   `json:"route_id" db:"route_id"`
+	CenterID    string   `json:"center_id" db:"center_id"`
+	Position    int      `json:"position" db:"position"`
+	PlannedTime string   `json:"planned_time,omitempty" db:"planned_time"` // HH:MM local
+	FarmerIDs   []string `json:"farmer_ids"`
+
+	// Filled in for a collector's route of the day
+	Center  *CollectionCenter `json:"center,omitempty"`
+	Farmers []*ExpectedFarmer `json:"farmers,omitempty"`
+}
+
+// ExpectedFarmer is the slice of a farmer a collector needs on the road.
+type ExpectedFarmer struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+// RunsOn reports whether the route is scheduled on the given date.
+func (r *Route) RunsOn(day time.Time) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	want := WeekdayCode(day.Weekday())
+	for _, d := range r.Days {
+		if d == want {
+			return true
+		}
+	}
+	return false
+}
+
+// WeekdayCode returns the short code used in Route.Days, e.g. "mon".
+func WeekdayCode(d time.Weekday) string {
+	return [...]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}[d]
+}
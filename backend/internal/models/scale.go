@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Scale is a registered digital scale. Secret signs its readings; it is never
+// serialized and is handed out once, when the scale is registered.
+type Scale struct {
+	ID           string `json:"id" db:"id"` // UUID
+	Serial       string `json:"serial" db:"serial"`
+	Name         string `json:"name" db:"name"`
+	CenterID     string `json:"center_id,omitempty" db:"center_id"`
+	Secret       string `json:"-" db:"secret"`
+	Active       bool   `json:"active" db:"active"`
+	LastSequence int64  `json:"last_sequence" db:"last_sequence"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
+
+// ScaleReading is a verified weighing accepted from a scale.
+type ScaleReading struct {
+	ID          string    `json:"id" db:"id"` // UUID
+	ScaleID     string    `json:"scale_id" db:"scale_id"`
+	Sequence    int64     `json:"sequence" db:"sequence"`
+	GrossKg     float64   `json:"gross_kg" db:"gross_kg"`
+	TareKg      float64   `json:"tare_kg" db:"tare_kg"`
+	NetKg       float64   `json:"net_kg" db:"net_kg"`
+	MeasuredAt  time.Time `json:"measured_at" db:"measured_at"`
+	Signature   string    `json:"signature" db:"signature"`
+	SubmittedBy string    `json:"submitted_by" db:"submitted_by"`
+
+	// ID of the collection that used this reading, if any
+	CollectionID string `json:"collection_id,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
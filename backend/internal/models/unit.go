@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// BaseUnit is what weights are normalized to for pricing and reports.
+const BaseUnit = "kg"
+
+type Unit struct {
+	Code      string `json:"code" db:"code"` // kg, litres, bag
+	NameEn    string `json:"name_en" db:"name_en"`
+	NameSw    string `json:"name_sw" db:"name_sw"`
+	Dimension string `json:"dimension" db:"dimension"` // mass, volume, count
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UnitConversion gives the kg equivalent of one unit. An empty CropType
+// applies to every crop; crop-specific rows (milk density, coffee bag
+// weight) take precedence.
+type UnitConversion struct {
+	CropType string  `json:"crop_type" db:"crop_type"`
+	Unit     string  `json:"unit" db:"unit"`
+	ToKg     float64 `json:"to_kg" db:"to_kg"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Version int `json:"version" db:"version"`
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+
+	"agri-sync-backend/internal/models"
+)
+
+// AdjustmentRepository is append-only: adjustments are never updated or
+// deleted, so a collection's history can always be replayed.
+type AdjustmentRepository struct {
+	db DBTX
+}
+
+func NewAdjustmentRepository(db DBTX) *AdjustmentRepository {
+	return &AdjustmentRepository{db: db}
+}
+
+const adjustmentColumns = `id, collection_id, kind, previous_weight_kg, new_weight_kg,
+	previous_grade, new_grade, previous_price_per_kg, new_price_per_kg, amount,
+	reason, created_by, created_at`
+
+// Create appends an adjustment to a collection that is at collectionVersion
+// and moves the collection to the next version, in one transaction. The
+// caller computes the adjustment from the collection's standing at that
+// version, so a concurrent adjustment makes this one fail with ErrConflict
+// instead of both counting the same difference.
+func (r *AdjustmentRepository) Create(ctx context.Context, a *models.Adjustment, collectionVersion int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertAdjustment(ctx, tx, a, collectionVersion); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertAdjustment lets other repositories record an adjustment inside
+// their own transaction. See Create for collectionVersion.
+func insertAdjustment(ctx context.Context, db DBTX, a *models.Adjustment, collectionVersion int) error {
+	a.CreatedAt = timeNow()
+
+	res, err := db.ExecContext(ctx, `
+		UPDATE collections SET version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ?`,
+		formatTime(a.CreatedAt), a.CollectionID, collectionVersion,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO collection_adjustments (`+adjustmentColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.CollectionID, a.Kind, a.PreviousWeightKg, a.NewWeightKg,
+		a.PreviousGrade, a.NewGrade, a.PreviousPricePerKg, a.NewPricePerKg, a.Amount,
+		a.Reason, a.CreatedBy, formatTime(a.CreatedAt),
+	)
+	return err
+}
+
+// ListByCollection returns a collection's adjustments, oldest first.
+func (r *AdjustmentRepository) ListByCollection(ctx context.Context, collectionID string) ([]*models.Adjustment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.list(ctx, `
+		SELECT `+adjustmentColumns+` FROM collection_adjustments
+		WHERE collection_id = ?
+		ORDER BY created_at, rowid`, collectionID)
+}
+
+// ListByFarmer returns the adjustments on all of a farmer's collections.
+func (r *AdjustmentRepository) ListByFarmer(ctx context.Context, farmerID string) ([]*models.Adjustment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.list(ctx, `
+		SELECT `+prefixColumns("a", adjustmentColumns)+`
+		FROM collection_adjustments a
+		JOIN collections c ON c.id = a.collection_id
+		WHERE c.farmer_id = ?
+		ORDER BY a.created_at, a.rowid`, farmerID)
+}
+
+func (r *AdjustmentRepository) list(ctx context.Context, query string, args ...any) ([]*models.Adjustment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Adjustment
+	for rows.Next() {
+		var a models.Adjustment
+		err := rows.Scan(&a.ID, &a.CollectionID, &a.Kind, &a.PreviousWeightKg, &a.NewWeightKg,
+			&a.PreviousGrade, &a.NewGrade, &a.PreviousPricePerKg, &a.NewPricePerKg, &a.Amount,
+			&a.Reason, &a.CreatedBy, timeColumn{&a.CreatedAt})
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &a)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TestAdjustmentVersion checks that an adjustment moves its collection to
+// the next version, so a second one computed from the same standing is
+// refused instead of counting the same difference twice.
+func TestAdjustmentVersion(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := t.Context()
+		farmer := &models.Farmer{ID: uuid.New().String(), Name: "Adjusted Farmer", Phone: "+2547" + b.run + "1"}
+		collector := &models.Collector{ID: uuid.New().String(), Name: "Adjusting Collector", Phone: "+2547" + b.run + "2"}
+		if err := b.stores.Farmers.Create(ctx, farmer); err != nil {
+			t.Fatalf("fixture farmer: %v", err)
+		}
+		if err := b.stores.Collectors.Create(ctx, collector); err != nil {
+			t.Fatalf("fixture collector: %v", err)
+		}
+		c := &models.Collection{
+			ID: uuid.New().String(), FarmerID: farmer.ID, CollectorID: collector.ID,
+			CropType: "tea", Quantity: 10, Unit: "kg", WeightKg: 10, PricePerKg: 20,
+		}
+		if err := b.stores.Collections.Create(ctx, c); err != nil {
+			t.Fatalf("fixture collection: %v", err)
+		}
+
+		adjustments := repository.NewAdjustmentRepository(b.db)
+		regrade := func() *models.Adjustment {
+			return &models.Adjustment{
+				ID: uuid.New().String(), CollectionID: c.ID, Kind: models.AdjustmentRegrade,
+				PreviousWeightKg: 10, NewWeightKg: 10, PreviousPricePerKg: 20, NewPricePerKg: 25,
+				Amount: 50, Reason: "regraded", CreatedBy: "admin",
+			}
+		}
+		if err := adjustments.Create(ctx, regrade(), c.Version); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		got, err := b.stores.Collections.GetByID(ctx, c.ID)
+		if err != nil {
+			t.Fatalf("get collection: %v", err)
+		}
+		if got.Version != c.Version+1 {
+			t.Errorf("collection version = %d, want %d", got.Version, c.Version+1)
+		}
+
+		if err := adjustments.Create(ctx, regrade(), c.Version); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("create at a stale version: err = %v, want %v", err, repository.ErrConflict)
+		}
+		list, err := adjustments.ListByCollection(ctx, c.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != 1 {
+			t.Errorf("%d adjustments, want 1", len(list))
+		}
+	})
+}
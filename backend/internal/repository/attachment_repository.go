@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"agri-sync-backend/internal/models"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrUploadNotFound     = errors.New("upload not found")
+)
+
+type AttachmentRepository struct {
+	db DBTX
+}
+
+func NewAttachmentRepository(db DBTX) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+const attachmentColumns = `id, owner_type, owner_id, sha256, size_bytes, mime_type, filename, uploaded_by, created_at`
+
+const uploadColumns = `id, owner_type, owner_id, filename, size_bytes, created_by, created_at`
+
+// CREATE
+func (r *AttachmentRepository) Create(ctx context.Context, a *models.Attachment) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	a.CreatedAt = timeNow()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO attachments (`+attachmentColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.OwnerType, a.OwnerID, a.SHA256, a.SizeBytes, a.MimeType, a.Filename, a.UploadedBy,
+		formatTime(a.CreatedAt),
+	)
+	return err
+}
+
+// READ
+func (r *AttachmentRepository) GetByID(ctx context.Context, id string) (*models.Attachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+attachmentColumns+` FROM attachments WHERE id = ?`, id)
+	a, err := scanAttachment(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+// FindByHash returns the owner's attachment with the given content, if the
+// same file was already attached.
+func (r *AttachmentRepository) FindByHash(ctx context.Context, ownerType, ownerID, sha256 string) (*models.Attachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+attachmentColumns+` FROM attachments
+		WHERE owner_type = ? AND owner_id = ? AND sha256 = ?`, ownerType, ownerID, sha256)
+	a, err := scanAttachment(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+// ListByOwner returns an owner's attachments, oldest first.
+func (r *AttachmentRepository) ListByOwner(ctx context.Context, ownerType, ownerID string) ([]*models.Attachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+attachmentColumns+` FROM attachments
+		WHERE owner_type = ? AND owner_id = ?
+		ORDER BY created_at`, ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func (r *AttachmentRepository) CreateUpload(ctx context.Context, u *models.UploadSession) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	u.CreatedAt = timeNow()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO upload_sessions (`+uploadColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		u.ID, u.OwnerType, u.OwnerID, u.Filename, u.SizeBytes, u.CreatedBy,
+		formatTime(u.CreatedAt),
+	)
+	return err
+}
+
+func (r *AttachmentRepository) GetUpload(ctx context.Context, id string) (*models.UploadSession, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var u models.UploadSession
+	err := r.db.QueryRowContext(ctx, `SELECT `+uploadColumns+` FROM upload_sessions WHERE id = ?`, id).
+		Scan(&u.ID, &u.OwnerType, &u.OwnerID, &u.Filename, &u.SizeBytes, &u.CreatedBy, timeColumn{&u.CreatedAt})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *AttachmentRepository) DeleteUpload(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE id = ?`, id)
+	return err
+}
+
+func scanAttachment(s rowScanner) (*models.Attachment, error) {
+	var a models.Attachment
+
+	err := s.Scan(&a.ID, &a.OwnerType, &a.OwnerID, &a.SHA256, &a.SizeBytes, &a.MimeType, &a.Filename, &a.UploadedBy, timeColumn{&a.CreatedAt})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"agri-sync-backend/internal/models"
+)
+
+// AuditRepository appends to the audit log and checks its hash chain.
+type AuditRepository struct {
+	db DBTX
+}
+
+func NewAuditRepository(db DBTX) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+const auditColumns = `seq, at, actor_id, actor_role, device_id, ip, request_id,
+	action, entity_type, entity_id, before, after, prev_hash, hash`
+
+// AuditFilter narrows List. Empty fields match everything.
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	ActorID    string
+	Action     string
+	RequestID  string
+	From, To   time.Time // zero means unbounded
+	BeforeSeq  int64     // paging: only entries older than this
+	Limit      int
+}
+
+// auditHash is the hex SHA-256 of the entry's fields and the previous hash,
+// joined by newlines.
+func auditHash(e *models.AuditEntry) string {
+	fields := []string{
+		e.PrevHash,
+		strconv.FormatInt(e.Seq, 10),
+		e.At.UTC().Format(time.RFC3339),
+		e.ActorID, e.ActorRole, e.DeviceID, e.IP, e.RequestID,
+		e.Action, e.EntityType, e.EntityID,
+		string(e.Before), string(e.After),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// CREATE (append only; entries are never updated or deleted)
+//
+// Append runs in the caller's transaction when there is one, so that the
+// entry is saved with the change it records (see Record). Appends are
+// serialized by the database: each one first updates the audit_head row,
+// and concurrent appends wait on that row's lock until the transaction
+// holding it ends, so each entry links to the one before it.
+func (r *AuditRepository) Append(ctx context.Context, e *models.AuditEntry) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE audit_head SET seq = seq + 1 WHERE id = 1
+		RETURNING seq, hash`).Scan(&e.Seq, &e.PrevHash)
+	if err != nil {
+		return err
+	}
+
+	// Whole seconds: the hash covers At as RFC3339.
+	e.At = timeNow().Truncate(time.Second)
+	e.Hash = auditHash(e)
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_log (`+auditColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Seq, formatTime(e.At), e.ActorID, e.ActorRole, e.DeviceID, e.IP, e.RequestID,
+		e.Action, e.EntityType, e.EntityID, nullableJSON(e.Before), nullableJSON(e.After),
+		e.PrevHash, e.Hash,
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE audit_head SET hash = ? WHERE id = 1`, e.Hash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Record runs change and appends the entry it returns in one transaction,
+// so a change is never saved without its entry: if either fails, neither
+// is kept. Repositories called with the ctx change is given take part in
+// the transaction (see WithTx). On a repository built on a *sql.Tx, both
+// run in that transaction.
+func (r *AuditRepository) Record(ctx context.Context, change func(ctx context.Context) (*models.AuditEntry, error)) error {
+	run := func(ctx context.Context) error {
+		e, err := change(ctx)
+		if err != nil {
+			return err
+		}
+		return r.Append(ctx, e)
+	}
+	db, ok := r.db.(DB)
+	if !ok {
+		// Built on a transaction: its commit covers both
+		return run(ctx)
+	}
+	return WithTx(ctx, db, func(ctx context.Context, _ *sql.Tx) error {
+		return run(ctx)
+	})
+}
+
+// List returns entries matching the filter, newest first.
+func (r *AuditRepository) List(ctx context.Context, f AuditFilter) ([]*models.AuditEntry, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE 1 = 1`
+	var args []any
+	for _, cond := range []struct{ column, value string }{
+		{"entity_type", f.EntityType},
+		{"entity_id", f.EntityID},
+		{"actor_id", f.ActorID},
+		{"action", f.Action},
+		{"request_id", f.RequestID},
+	} {
+		if cond.value != "" {
+			query += ` AND ` + cond.column + ` = ?`
+			args = append(args, cond.value)
+		}
+	}
+	if !f.From.IsZero() {
+		query += ` AND at >= ?`
+		args = append(args, formatTime(f.From))
+	}
+	if !f.To.IsZero() {
+		query += ` AND at < ?`
+		args = append(args, formatTime(f.To))
+	}
+	if f.BeforeSeq > 0 {
+		query += ` AND seq < ?`
+		args = append(args, f.BeforeSeq)
+	}
+	query += ` ORDER BY seq DESC LIMIT ?`
+	args = append(args, f.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.AuditEntry{}
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// verifyBatch is how many entries Verify reads per query.
+const verifyBatch = 1000
+
+// Verify walks the whole chain from the first entry. It stops at the first
+// entry whose hash does not match its contents, whose link to the previous
+// entry is broken, or whose sequence number skips (a deleted row).
+// Truncating the newest entries cannot be seen from the chain alone; compare
+// HeadSeq and HeadHash with a previously recorded result for that.
+//
+// The chain is read verifyBatch entries at a time, each batch under its own
+// query timeout, so a long log is not cut off by a single deadline.
+func (r *AuditRepository) Verify(ctx context.Context) (*models.AuditVerification, error) {
+	v := &models.AuditVerification{Valid: true}
+	for {
+		n, err := r.verifyFrom(ctx, v)
+		if err != nil || !v.Valid || n < verifyBatch {
+			return v, err
+		}
+	}
+}
+
+// verifyFrom checks the next batch of entries after v.HeadSeq, advancing v,
+// and returns how many it read.
+func (r *AuditRepository) verifyFrom(ctx context.Context, v *models.AuditVerification) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+auditColumns+` FROM audit_log
+		WHERE seq > ?
+		ORDER BY seq
+		LIMIT ?`, v.HeadSeq, verifyBatch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return n, err
+		}
+		n++
+
+		switch {
+		case e.Seq != v.HeadSeq+1:
+			v.Problem = fmt.Sprintf("entries %d to %d are missing", v.HeadSeq+1, e.Seq-1)
+		case e.PrevHash != v.HeadHash:
+			v.Problem = "prev_hash does not match the previous entry"
+		case auditHash(e) != e.Hash:
+			v.Problem = "hash does not match the entry's contents"
+		}
+		if v.Problem != "" {
+			v.Valid = false
+			v.BrokenAt = e.Seq
+			return n, nil
+		}
+
+		v.Entries++
+		v.HeadSeq, v.HeadHash = e.Seq, e.Hash
+	}
+	return n, rows.Err()
+}
+
+func scanAuditEntry(s rowScanner) (*models.AuditEntry, error) {
+	var e models.AuditEntry
+	var before, after sql.NullString
+
+	err := s.Scan(&e.Seq, timeColumn{&e.At}, &e.ActorID, &e.ActorRole, &e.DeviceID, &e.IP, &e.RequestID,
+		&e.Action, &e.EntityType, &e.EntityID, &before, &after, &e.PrevHash, &e.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if before.Valid {
+		e.Before = []byte(before.String)
+	}
+	if after.Valid {
+		e.After = []byte(after.String)
+	}
+	return &e, nil
+}
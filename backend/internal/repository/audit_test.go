@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TestAuditVerify checks a chain longer than one Verify batch, then breaks
+// it past the first batch. The entries are rolled back afterwards, so the
+// PostgreSQL log stays valid for later runs.
+func TestAuditVerify(t *testing.T) {
+	const appended = 2500
+	eachBackend(t, func(t *testing.T, b *backend) {
+		err := repository.WithTx(t.Context(), b.db, func(ctx context.Context, tx *sql.Tx) error {
+			audit := repository.NewAuditRepository(b.db)
+			start, err := audit.Verify(ctx)
+			if err != nil {
+				t.Fatalf("verify before: %v", err)
+			}
+			if !start.Valid {
+				t.Fatalf("chain already broken at %d: %s", start.BrokenAt, start.Problem)
+			}
+
+			for range appended {
+				entry := &models.AuditEntry{
+					ActorID: "verify-" + b.run, ActorRole: "admin",
+					Action: models.AuditUpdate, EntityType: "farmer", EntityID: uuid.New().String(),
+				}
+				if err := audit.Append(ctx, entry); err != nil {
+					t.Fatalf("append: %v", err)
+				}
+			}
+			v, err := audit.Verify(ctx)
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if !v.Valid || v.Entries != start.Entries+appended || v.HeadSeq != start.HeadSeq+appended {
+				t.Errorf("verify: valid %v, %d entries, head %d; want valid, %d entries, head %d (%s)",
+					v.Valid, v.Entries, v.HeadSeq, start.Entries+appended, start.HeadSeq+appended, v.Problem)
+			}
+
+			tampered := v.HeadSeq - 10
+			if _, err := tx.ExecContext(ctx, `UPDATE audit_log SET entity_id = 'tampered' WHERE seq = ?`, tampered); err != nil {
+				t.Fatalf("tamper: %v", err)
+			}
+			v, err = audit.Verify(ctx)
+			if err != nil {
+				t.Fatalf("verify tampered: %v", err)
+			}
+			if v.Valid || v.BrokenAt != tampered {
+				t.Errorf("verify tampered: valid %v, broken at %d, want broken at %d", v.Valid, v.BrokenAt, tampered)
+			}
+			return errAbort
+		})
+		if err != errAbort {
+			t.Fatalf("err = %v, want %v", err, errAbort)
+		}
+	})
+}
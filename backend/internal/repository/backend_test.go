@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/database"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// postgresDSN names the variable holding the PostgreSQL database the tests
+// also run against, e.g.
+// postgres://agrisync@localhost/agrisync_test?sslmode=disable. Use a
+// scratch database: the tests add rows and leave most of them.
+const postgresDSN = "AGRISYNC_TEST_POSTGRES_DSN"
+
+// backend is a migrated database the tests run against.
+type backend struct {
+	db     *database.DB
+	stores repository.Stores
+	// run makes phone numbers and codes unique, since a PostgreSQL
+	// database keeps the rows of earlier runs.
+	run string
+}
+
+// eachBackend runs test as a subtest against a fresh SQLite database and,
+// when AGRISYNC_TEST_POSTGRES_DSN is set, against PostgreSQL. Both must
+// pass unchanged.
+func eachBackend(t *testing.T, test func(t *testing.T, b *backend)) {
+	t.Run(config.DriverSQLite, func(t *testing.T) {
+		db, err := database.ConnectSQLite(filepath.Join(t.TempDir(), "test.db"), database.DefaultSQLiteOptions)
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		test(t, migrated(t, db, config.DriverSQLite))
+	})
+	t.Run(config.DriverPostgres, func(t *testing.T) {
+		dsn := os.Getenv(postgresDSN)
+		if dsn == "" {
+			t.Skip(postgresDSN + " is not set")
+		}
+		db, err := database.ConnectPostgres(dsn)
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		test(t, migrated(t, db, config.DriverPostgres))
+	})
+}
+
+func migrated(t *testing.T, db *database.DB, driver string) *backend {
+	t.Helper()
+	t.Cleanup(func() { db.Close() })
+	if err := database.RunMigrations(db, driver); err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+	return &backend{db: db, stores: repository.NewStores(db, driver), run: uuid.New().String()[:8]}
+}
+
+// sameTime reports whether a timestamp read back is the one written. Stores
+// keep milliseconds and return UTC, so nothing is lost on the way.
+func sameTime(a, b time.Time) bool {
+	return a.Equal(b) && a.Location() == time.UTC
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"agri-sync-backend/internal/models"
+)
+
+var ErrCenterNotFound = errors.New("collection center not found")
+
+type CenterRepository struct {
+	db DBTX
+}
+
+func NewCenterRepository(db DBTX) *CenterRepository {
+	return &CenterRepository{db: db}
+}
+
+const centerColumns = `id, code, name, latitude, longitude, region, active,
+	version, created_at, updated_at`
+
+// CREATE
+func (r *CenterRepository) Create(ctx context.Context, ctr *models.CollectionCenter) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	ctr.CreatedAt = now
+	ctr.UpdatedAt = now
+	ctr.Version = 1
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO collection_centers (`+centerColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		ctr.ID, ctr.Code, ctr.Name, ctr.Latitude, ctr.Longitude, ctr.Region, ctr.Active,
+		ctr.Version, formatTime(ctr.CreatedAt), formatTime(ctr.UpdatedAt),
+	)
+	return err
+}
+
+// READ
+func (r *CenterRepository) GetByID(ctx context.Context, id string) (*models.CollectionCenter, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+centerColumns+` FROM collection_centers WHERE id = ?`, id)
+	ctr, err := scanCenter(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrCenterNotFound
+	}
+	return ctr, err
+}
+
+// UPDATE
+func (r *CenterRepository) Update(ctx context.Context, ctr *models.CollectionCenter) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ctr.Version++
+	ctr.UpdatedAt = timeNow()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE collection_centers
+		SET code = ?, name = ?, latitude = ?, longitude = ?, region = ?, active = ?,
+		    version = ?, updated_at = ?
+		WHERE id = ?`,
+		ctr.Code, ctr.Name, ctr.Latitude, ctr.Longitude, ctr.Region, ctr.Active,
+		ctr.Version, formatTime(ctr.UpdatedAt), ctr.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCenterNotFound
+	}
+	return nil
+}
+
+func (r *CenterRepository) List(ctx context.Context) ([]*models.CollectionCenter, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+centerColumns+` FROM collection_centers ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.CollectionCenter
+	for rows.Next() {
+		ctr, err := scanCenter(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, ctr)
+	}
+	return list, rows.Err()
+}
+
+func scanCenter(s rowScanner) (*models.CollectionCenter, error) {
+	var ctr models.CollectionCenter
+	var lat, lng sql.NullFloat64
+
+	err := s.Scan(&ctr.ID, &ctr.Code, &ctr.Name, &lat, &lng, &ctr.Region, &ctr.Active,
+		&ctr.Version, timeColumn{&ctr.CreatedAt}, timeColumn{&ctr.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	if lat.Valid {
+		ctr.Latitude = &lat.Float64
+	}
+	if lng.Valid {
+		ctr.Longitude = &lng.Float64
+	}
+	return &ctr, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"agri-sync-backend/internal/models"
+)
+
+var ErrClerkNotFound = errors.New("clerk not found")
+
+type ClerkRepository struct {
+	db DBTX
+}
+
+func NewClerkRepository(db DBTX) *ClerkRepository {
+	return &ClerkRepository{db: db}
+}
+
+const clerkColumns = `id, name, phone, password_hash, version, created_at, updated_at`
+
+// CREATE
+func (r *ClerkRepository) Create(ctx context.Context, c *models.Clerk) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.Version = 1
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO clerks (`+clerkColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.Name, c.Phone, c.PasswordHash, c.Version,
+		formatTime(c.CreatedAt), formatTime(c.UpdatedAt),
+	)
+	return err
+}
+
+// READ
+func (r *ClerkRepository) GetByPhone(ctx context.Context, phone string) (*models.Clerk, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+clerkColumns+` FROM clerks WHERE phone = ?`, phone)
+
+	var c models.Clerk
+	err := row.Scan(&c.ID, &c.Name, &c.Phone, &c.PasswordHash, &c.Version,
+		timeColumn{&c.CreatedAt}, timeColumn{&c.UpdatedAt})
+	if err == sql.ErrNoRows {
+		return nil, ErrClerkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -1,121 +1,169 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
+	"agri-sync-backend/internal/geo"
 	"agri-sync-backend/internal/models"
+
+	"github.com/mattn/go-sqlite3"
 )
 
 var ErrConflict = errors.New("version conflict")
 
 type CollectionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewCollectionRepository(db *sql.DB) *CollectionRepository {
+func NewCollectionRepository(db DBTX) *CollectionRepository {
 	return &CollectionRepository{db: db}
 }
 
+const collectionColumns = `id, farmer_id, collector_id, center_id, plot_id, crop_type, quantity, unit, price_per_unit, weight_kg, price_per_kg,
+	status, grade, quality_readings, rejection_reason,
+	latitude, longitude, location_accuracy_m, location_captured_at, flags, scale_reading_id,
+	version, created_at, updated_at`
+
 // ── Your original CREATE ──
-func (r *CollectionRepository) Create(c *models.Collection) error {
-	now := time.Now().UTC()
+func (r *CollectionRepository) Create(ctx context.Context, c *models.Collection) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
 	c.CreatedAt = now
 	c.UpdatedAt = now
 	c.Version = 1
+	if c.Status == "" {
+		c.Status = models.StatusPending
+	}
 
-	_, err := r.db.Exec(`
-		INSERT INTO collections
-		(id, farmer_id, collector_id, crop_type, weight_kg, price_per_kg, version, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-		c.ID, c.FarmerID, c.CollectorID, c.CropType, c.WeightKg, c.PricePerKg, c.Version,
-		c.CreatedAt, c.UpdatedAt,
+	readings := []byte("{}")
+	if len(c.QualityReadings) > 0 {
+		var err error
+		if readings, err = json.Marshal(c.QualityReadings); err != nil {
+			return err
+		}
+	}
+
+	if c.Flags == nil {
+		c.Flags = []string{}
+	}
+	flags, err := json.Marshal(c.Flags)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO collections (`+collectionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.FarmerID, c.CollectorID, nullableString(c.CenterID), nullableString(c.PlotID), c.CropType, c.Quantity, c.Unit, c.PricePerUnit, c.WeightKg, c.PricePerKg,
+		c.Status, c.Grade, string(readings), c.RejectionReason,
+		c.Latitude, c.Longitude, c.LocationAccuracyM, formatOptionalTime(c.LocationCapturedAt), string(flags), nullableString(c.ScaleReadingID),
+		c.Version, formatTime(c.CreatedAt), formatTime(c.UpdatedAt),
 	)
+	// Two deliveries citing one reading can both pass the handler's check;
+	// the unique index on scale_reading_id lets only one in
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique &&
+		strings.Contains(sqliteErr.Error(), "collections.scale_reading_id") {
+		return ErrReadingUsed
+	}
 	return err
 }
 
 // ── Your original READ ──
-func (r *CollectionRepository) GetByID(id string) (*models.Collection, error) {
-	row := r.db.QueryRow(`
-		SELECT id, farmer_id, collector_id, crop_type, weight_kg, price_per_kg,
-		       version, created_at, updated_at
-		FROM collections WHERE id = $1`, id)
+func (r *CollectionRepository) GetByID(ctx context.Context, id string) (*models.Collection, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
-	var c models.Collection
-	var createdAt, updatedAt time.Time
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+collectionColumns+`
+		FROM collections WHERE id = ?`, id)
 
-	err := row.Scan(&c.ID, &c.FarmerID, &c.CollectorID, &c.CropType, &c.WeightKg, &c.PricePerKg,
-		&c.Version, &createdAt, &updatedAt)
+	c, err := scanCollection(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("collection not found")
+			return nil, ErrCollectionNotFound
 		}
 		return nil, err
 	}
-
-	c.CreatedAt = createdAt
-	c.UpdatedAt = updatedAt
-	return &c, nil
+	return c, nil
 }
 
 // ── Your original UPDATE ──
 // UPDATE (optimistic concurrency)
-func (r *CollectionRepository) Update(c *models.Collection) error {
+func (r *CollectionRepository) Update(ctx context.Context, c *models.Collection) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	c.Version++
-	c.UpdatedAt = time.Now().UTC()
+	c.UpdatedAt = timeNow()
 
-	_, err := r.db.Exec(`
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE collections
-		SET farmer_id = $1, collector_id = $2, crop_type = $3, weight_kg = $4, price_per_kg = $5, version = $6, updated_at = $7
-		WHERE id = $8`,
-		c.FarmerID, c.CollectorID, c.CropType, c.WeightKg, c.PricePerKg, c.Version,
-		c.UpdatedAt, c.ID,
+		SET farmer_id = ?, collector_id = ?, crop_type = ?, quantity = ?, unit = ?, price_per_unit = ?,
+			weight_kg = ?, price_per_kg = ?, version = ?, updated_at = ?
+		WHERE id = ?`,
+		c.FarmerID, c.CollectorID, c.CropType, c.Quantity, c.Unit, c.PricePerUnit,
+		c.WeightKg, c.PricePerKg, c.Version, formatTime(c.UpdatedAt), c.ID,
 	)
 	return err
 }
 
 // ── Your original DELETE ──
-func (r *CollectionRepository) Delete(id string) error {
-	_, err := r.db.Exec(`DELETE FROM collections WHERE id = $1`, id)
+func (r *CollectionRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM collections WHERE id = ?`, id)
 	return err
 }
 
 // ── Added helpers (needed for endpoints) ──
 
-func (r *CollectionRepository) ListByFarmer(farmerID string) ([]*models.Collection, error) {
-	rows, err := r.db.Query(`
-		SELECT id, farmer_id, collector_id, crop_type, weight_kg, price_per_kg,
-		       version, created_at, updated_at
-		FROM collections WHERE farmer_id = $1
-		ORDER BY created_at DESC`, farmerID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+func (r *CollectionRepository) ListByFarmer(ctx context.Context, farmerID string) ([]*models.Collection, error) {
+	return r.List(ctx, CollectionFilter{FarmerID: farmerID})
+}
 
-	var list []*models.Collection
-	for rows.Next() {
-		var c models.Collection
-		var createdAt, updatedAt time.Time
-		err := rows.Scan(&c.ID, &c.FarmerID, &c.CollectorID, &c.CropType, &c.WeightKg, &c.PricePerKg,
-			&c.Version, &createdAt, &updatedAt)
-		if err != nil {
-			return nil, err
-		}
-		c.CreatedAt = createdAt
-		c.UpdatedAt = updatedAt
-		list = append(list, &c)
-	}
-	return list, rows.Err()
+func (r *CollectionRepository) ListAll(ctx context.Context) ([]*models.Collection, error) {
+	return r.List(ctx, CollectionFilter{})
 }
 
-func (r *CollectionRepository) ListAll() ([]*models.Collection, error) {
-	rows, err := r.db.Query(`
-		SELECT id, farmer_id, collector_id, crop_type, weight_kg, price_per_kg,
-		       version, created_at, updated_at
-		FROM collections
-		ORDER BY created_at DESC`)
+// CollectionFilter narrows List. Zero values mean "no filter".
+type CollectionFilter struct {
+	FarmerID string
+	// Within keeps only geotagged collections inside the box
+	Within *geo.BoundingBox
+	// Flagged keeps only collections carrying at least one review flag
+	Flagged bool
+}
+
+// List returns collections matching the filter, newest first.
+func (r *CollectionRepository) List(ctx context.Context, f CollectionFilter) ([]*models.Collection, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + collectionColumns + ` FROM collections WHERE 1 = 1`
+	var args []any
+	if f.FarmerID != "" {
+		query += ` AND farmer_id = ?`
+		args = append(args, f.FarmerID)
+	}
+	if f.Within != nil {
+		query += ` AND latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?`
+		args = append(args, f.Within.MinLat, f.Within.MaxLat, f.Within.MinLng, f.Within.MaxLng)
+	}
+	if f.Flagged {
+		query += ` AND flags <> '[]'`
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -123,44 +171,45 @@ func (r *CollectionRepository) ListAll() ([]*models.Collection, error) {
 
 	var list []*models.Collection
 	for rows.Next() {
-		var c models.Collection
-		var createdAt, updatedAt time.Time
-		err := rows.Scan(&c.ID, &c.FarmerID, &c.CollectorID, &c.CropType, &c.WeightKg, &c.PricePerKg,
-			&c.Version, &createdAt, &updatedAt)
+		c, err := scanCollection(rows)
 		if err != nil {
 			return nil, err
 		}
-		c.CreatedAt = createdAt
-		c.UpdatedAt = updatedAt
-		list = append(list, &c)
+		list = append(list, c)
 	}
 	return list, rows.Err()
 }
 
-func (r *CollectionRepository) UpdateStatus(id string, status models.TransactionStatus) error {
-	_, err := r.db.Exec(`
+func (r *CollectionRepository) UpdateStatus(ctx context.Context, id string, status models.TransactionStatus) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE collections
-		SET status = $1, updated_at = NOW()
-		WHERE id = $2`,
-		status, id,
+		SET status = ?, updated_at = ?
+		WHERE id = ?`,
+		status, formatTime(timeNow()), id,
 	)
 	return err
 }
 
 // UpdateWithVersion updates collection only if the provided version matches current.
 // It increments the version on success. Returns ErrConflict if mismatch.
-func (r *CollectionRepository) UpdateWithVersion(c *models.Collection) error {
-	tx, err := r.db.Begin()
+func (r *CollectionRepository) UpdateWithVersion(ctx context.Context, c *models.Collection) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(ctx, `
       UPDATE collections
       SET status = ?, version = version + 1, updated_at = ?
       WHERE id = ? AND version = ?
-    `, c.Status, time.Now().UTC(), c.ID, c.Version)
+    `, c.Status, formatTime(timeNow()), c.ID, c.Version)
 	if err != nil {
 		return err
 	}
@@ -172,4 +221,94 @@ func (r *CollectionRepository) UpdateWithVersion(c *models.Collection) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// TotalsByCrop sums non-rejected collections created in [from, to) per crop,
+// normalized to kg so litres of milk and bags of cherry add up.
+func (r *CollectionRepository) TotalsByCrop(ctx context.Context, from, to time.Time) ([]*models.CropTotal, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT crop_type, COUNT(*), SUM(weight_kg), SUM(weight_kg * price_per_kg)
+		FROM collections
+		WHERE status <> ? AND created_at >= ? AND created_at < ?
+		GROUP BY crop_type
+		ORDER BY crop_type`, models.StatusRejected, formatTime(from), formatTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.CropTotal
+	for rows.Next() {
+		t := models.CropTotal{Unit: models.BaseUnit}
+		if err := rows.Scan(&t.CropType, &t.Count, &t.TotalQuantity, &t.TotalValue); err != nil {
+			return nil, err
+		}
+		list = append(list, &t)
+	}
+	return list, rows.Err()
+}
+
+// TotalsByCenter sums non-rejected collections per center and crop in kg,
+// for reconciling what each center received against what was shipped.
+// Collections without a center are grouped under an empty center_id.
+func (r *CollectionRepository) TotalsByCenter(ctx context.Context, from, to time.Time) ([]*models.CenterTotal, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(center_id, ''), crop_type, COUNT(*), SUM(weight_kg), SUM(weight_kg * price_per_kg)
+		FROM collections
+		WHERE status <> ? AND created_at >= ? AND created_at < ?
+		GROUP BY center_id, crop_type
+		ORDER BY center_id, crop_type`, models.StatusRejected, formatTime(from), formatTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.CenterTotal
+	for rows.Next() {
+		t := models.CenterTotal{CropTotal: models.CropTotal{Unit: models.BaseUnit}}
+		if err := rows.Scan(&t.CenterID, &t.CropType, &t.Count, &t.TotalQuantity, &t.TotalValue); err != nil {
+			return nil, err
+		}
+		list = append(list, &t)
+	}
+	return list, rows.Err()
+}
+
+// scanCollection scans collectionColumns followed by any extra destinations.
+func scanCollection(s rowScanner, extra ...any) (*models.Collection, error) {
+	var c models.Collection
+	var centerID, plotID, scaleReadingID sql.NullString
+	var readings, flags string
+	var lat, lng, accuracy sql.NullFloat64
+
+	dest := append([]any{&c.ID, &c.FarmerID, &c.CollectorID, &centerID, &plotID, &c.CropType, &c.Quantity, &c.Unit, &c.PricePerUnit, &c.WeightKg, &c.PricePerKg,
+		&c.Status, &c.Grade, &readings, &c.RejectionReason,
+		&lat, &lng, &accuracy, nullTimeColumn{&c.LocationCapturedAt}, &flags, &scaleReadingID,
+		&c.Version, timeColumn{&c.CreatedAt}, timeColumn{&c.UpdatedAt}}, extra...)
+	err := s.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	c.CenterID = centerID.String
+	c.PlotID = plotID.String
+	c.ScaleReadingID = scaleReadingID.String
+	if err := json.Unmarshal([]byte(readings), &c.QualityReadings); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(flags), &c.Flags); err != nil {
+		return nil, err
+	}
+	if lat.Valid && lng.Valid {
+		c.Latitude, c.Longitude = &lat.Float64, &lng.Float64
+	}
+	if accuracy.Valid {
+		c.LocationAccuracyM = &accuracy.Float64
+	}
+	return &c, nil
+}
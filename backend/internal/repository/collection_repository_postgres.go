@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"agri-sync-backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// PostgresCollectionRepository is the CollectionStore for PostgreSQL, where
+// timestamps are TIMESTAMPTZ and quality_readings and flags are JSONB.
+type PostgresCollectionRepository struct {
+	db DBTX
+}
+
+func NewPostgresCollectionRepository(db DBTX) *PostgresCollectionRepository {
+	return &PostgresCollectionRepository{db: db}
+}
+
+// CREATE
+func (r *PostgresCollectionRepository) Create(ctx context.Context, c *models.Collection) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.Version = 1
+	if c.Status == "" {
+		c.Status = models.StatusPending
+	}
+
+	readings := []byte("{}")
+	if len(c.QualityReadings) > 0 {
+		var err error
+		if readings, err = json.Marshal(c.QualityReadings); err != nil {
+			return err
+		}
+	}
+
+	if c.Flags == nil {
+		c.Flags = []string{}
+	}
+	flags, err := json.Marshal(c.Flags)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO collections (`+collectionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)`,
+		c.ID, c.FarmerID, c.CollectorID, nullableString(c.CenterID), nullableString(c.PlotID), c.CropType, c.Quantity, c.Unit, c.PricePerUnit, c.WeightKg, c.PricePerKg,
+		c.Status, c.Grade, string(readings), c.RejectionReason,
+		c.Latitude, c.Longitude, c.LocationAccuracyM, c.LocationCapturedAt, string(flags), nullableString(c.ScaleReadingID),
+		c.Version, c.CreatedAt, c.UpdatedAt,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_collections_scale_reading_id" {
+		return ErrReadingUsed
+	}
+	return err
+}
+
+// READ
+func (r *PostgresCollectionRepository) GetByID(ctx context.Context, id string) (*models.Collection, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+collectionColumns+` FROM collections WHERE id = $1`, id)
+
+	c, err := scanPostgresCollection(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrCollectionNotFound
+	}
+	return c, err
+}
+
+// UPDATE
+func (r *PostgresCollectionRepository) Update(ctx context.Context, c *models.Collection) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	c.Version++
+	c.UpdatedAt = timeNow()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE collections
+		SET farmer_id = $1, collector_id = $2, crop_type = $3, quantity = $4, unit = $5, price_per_unit = $6,
+			weight_kg = $7, price_per_kg = $8, version = $9, updated_at = $10
+		WHERE id = $11`,
+		c.FarmerID, c.CollectorID, c.CropType, c.Quantity, c.Unit, c.PricePerUnit,
+		c.WeightKg, c.PricePerKg, c.Version, c.UpdatedAt, c.ID,
+	)
+	return err
+}
+
+// DELETE
+func (r *PostgresCollectionRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM collections WHERE id = $1`, id)
+	return err
+}
+
+func (r *PostgresCollectionRepository) ListByFarmer(ctx context.Context, farmerID string) ([]*models.Collection, error) {
+	return r.List(ctx, CollectionFilter{FarmerID: farmerID})
+}
+
+func (r *PostgresCollectionRepository) ListAll(ctx context.Context) ([]*models.Collection, error) {
+	return r.List(ctx, CollectionFilter{})
+}
+
+// List returns collections matching the filter, newest first.
+func (r *PostgresCollectionRepository) List(ctx context.Context, f CollectionFilter) ([]*models.Collection, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + collectionColumns + ` FROM collections WHERE 1 = 1`
+	var args []any
+	if f.FarmerID != "" {
+		args = append(args, f.FarmerID)
+		query += fmt.Sprintf(` AND farmer_id = $%d`, len(args))
+	}
+	if f.Within != nil {
+		args = append(args, f.Within.MinLat, f.Within.MaxLat, f.Within.MinLng, f.Within.MaxLng)
+		n := len(args)
+		query += fmt.Sprintf(` AND latitude BETWEEN $%d AND $%d AND longitude BETWEEN $%d AND $%d`, n-3, n-2, n-1, n)
+	}
+	if f.Flagged {
+		query += ` AND flags <> '[]'::jsonb`
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Collection
+	for rows.Next() {
+		c, err := scanPostgresCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresCollectionRepository) UpdateStatus(ctx context.Context, id string, status models.TransactionStatus) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE collections
+		SET status = $1, updated_at = $2
+		WHERE id = $3`,
+		status, timeNow(), id,
+	)
+	return err
+}
+
+// UpdateWithVersion updates the status only if c.Version is current, and
+// increments the version. Returns ErrConflict if it is not.
+func (r *PostgresCollectionRepository) UpdateWithVersion(ctx context.Context, c *models.Collection) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE collections
+		SET status = $1, version = version + 1, updated_at = $2
+		WHERE id = $3 AND version = $4`,
+		c.Status, timeNow(), c.ID, c.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// TotalsByCrop sums non-rejected collections created in [from, to) per crop,
+// in kg.
+func (r *PostgresCollectionRepository) TotalsByCrop(ctx context.Context, from, to time.Time) ([]*models.CropTotal, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT crop_type, COUNT(*), SUM(weight_kg), SUM(weight_kg * price_per_kg)
+		FROM collections
+		WHERE status <> $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY crop_type
+		ORDER BY crop_type`, models.StatusRejected, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.CropTotal
+	for rows.Next() {
+		t := models.CropTotal{Unit: models.BaseUnit}
+		if err := rows.Scan(&t.CropType, &t.Count, &t.TotalQuantity, &t.TotalValue); err != nil {
+			return nil, err
+		}
+		list = append(list, &t)
+	}
+	return list, rows.Err()
+}
+
+// TotalsByCenter sums non-rejected collections per center and crop in kg.
+// Collections without a center are grouped under an empty center_id.
+func (r *PostgresCollectionRepository) TotalsByCenter(ctx context.Context, from, to time.Time) ([]*models.CenterTotal, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(center_id, ''), crop_type, COUNT(*), SUM(weight_kg), SUM(weight_kg * price_per_kg)
+		FROM collections
+		WHERE status <> $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY COALESCE(center_id, ''), crop_type
+		ORDER BY COALESCE(center_id, ''), crop_type`, models.StatusRejected, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.CenterTotal
+	for rows.Next() {
+		t := models.CenterTotal{CropTotal: models.CropTotal{Unit: models.BaseUnit}}
+		if err := rows.Scan(&t.CenterID, &t.CropType, &t.Count, &t.TotalQuantity, &t.TotalValue); err != nil {
+			return nil, err
+		}
+		list = append(list, &t)
+	}
+	return list, rows.Err()
+}
+
+func scanPostgresCollection(s rowScanner) (*models.Collection, error) {
+	var c models.Collection
+	var centerID, plotID, scaleReadingID sql.NullString
+	var readings, flags []byte
+	var lat, lng, accuracy sql.NullFloat64
+
+	err := s.Scan(&c.ID, &c.FarmerID, &c.CollectorID, &centerID, &plotID, &c.CropType, &c.Quantity, &c.Unit, &c.PricePerUnit, &c.WeightKg, &c.PricePerKg,
+		&c.Status, &c.Grade, &readings, &c.RejectionReason,
+		&lat, &lng, &accuracy, nullTimeColumn{&c.LocationCapturedAt}, &flags, &scaleReadingID,
+		&c.Version, timeColumn{&c.CreatedAt}, timeColumn{&c.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+	c.CenterID = centerID.String
+	c.PlotID = plotID.String
+	c.ScaleReadingID = scaleReadingID.String
+	if err := json.Unmarshal(readings, &c.QualityReadings); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(flags, &c.Flags); err != nil {
+		return nil, err
+	}
+	if lat.Valid && lng.Valid {
+		c.Latitude, c.Longitude = &lat.Float64, &lng.Float64
+	}
+	if accuracy.Valid {
+		c.LocationAccuracyM = &accuracy.Float64
+	}
+	return &c, nil
+}
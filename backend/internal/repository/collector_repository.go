@@ -1,109 +1,110 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-	"time"
-	"errors"
+
 	"agri-sync-backend/internal/models"
-	"fmt"
 )
 
 type CollectorRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewCollectorRepository(db *sql.DB) *CollectorRepository {
+func NewCollectorRepository(db DBTX) *CollectorRepository {
 	return &CollectorRepository{db: db}
 }
 
 // CREATE
-func (r *CollectorRepository) Create(c *models.Collector) error {
-	now := time.Now().UTC()
+func (r *CollectorRepository) Create(ctx context.Context, c *models.Collector) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
 	c.CreatedAt = now
 	c.UpdatedAt = now
 	c.Version = 1
 
-	_, err := r.db.Exec(`
+	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO collectors (id, name, phone, password_hash, version, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		c.ID, c.Name, c.Phone, c.PasswordHash, c.Version,
-		c.CreatedAt.Format(time.RFC3339), c.UpdatedAt.Format(time.RFC3339),
+		formatTime(c.CreatedAt), formatTime(c.UpdatedAt),
 	)
 	return err
 }
 
 // READ
-func (r *CollectorRepository) GetByID(id string) (*models.Collector, error) {
-	row := r.db.QueryRow(`
+func (r *CollectorRepository) GetByID(ctx context.Context, id string) (*models.Collector, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
 		SELECT id, name, phone, password_hash, version, created_at, updated_at
 		FROM collectors WHERE id = ?`, id)
 
 	var c models.Collector
-	var createdAt, updatedAt string
-	err := row.Scan(&c.ID, &c.Name, &c.Phone, &c.PasswordHash, &c.Version, &createdAt, &updatedAt)
+	err := row.Scan(&c.ID, &c.Name, &c.Phone, &c.PasswordHash, &c.Version, timeColumn{&c.CreatedAt}, timeColumn{&c.UpdatedAt})
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCollectorNotFound
+		}
 		return nil, err
 	}
-	c.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
-	c.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
 	return &c, nil
 }
 
 // UPDATE (idempotent)
-func (r *CollectorRepository) Update(c *models.Collector) error {
+func (r *CollectorRepository) Update(ctx context.Context, c *models.Collector) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	c.Version++
-	c.UpdatedAt = time.Now().UTC()
+	c.UpdatedAt = timeNow()
 
-	_, err := r.db.Exec(`
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE collectors 
 		SET name = ?, phone = ?, version = ?, updated_at = ?
 		WHERE id = ?`,
-		c.Name, c.Phone, c.Version, c.UpdatedAt.Format(time.RFC3339), c.ID,
+		c.Name, c.Phone, c.Version, formatTime(c.UpdatedAt), c.ID,
 	)
 	return err
 }
 
 // DELETE
-func (r *CollectorRepository) Delete(id string) error {
-	_, err := r.db.Exec(`DELETE FROM collectors WHERE id = ?`, id)
+func (r *CollectorRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM collectors WHERE id = ?`, id)
 	return err
 }
 
 
-func (r *CollectorRepository) GetByPhone(phone string) (*models.Collector, error) {
-    row := r.db.QueryRow(`
+func (r *CollectorRepository) GetByPhone(ctx context.Context, phone string) (*models.Collector, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+    row := r.db.QueryRowContext(ctx, `
         SELECT id, name, phone, password_hash, version, created_at, updated_at
-        FROM collectors WHERE phone = $1`, phone)
+        FROM collectors WHERE phone = ?`, phone)
 
     var c models.Collector
-    var createdAtStr, updatedAtStr string
-
     err := row.Scan(
         &c.ID,
         &c.Name,
         &c.Phone,
         &c.PasswordHash,
         &c.Version,
-        &createdAtStr,
-        &updatedAtStr,
+        timeColumn{&c.CreatedAt},
+        timeColumn{&c.UpdatedAt},
     )
     if err != nil {
         if err == sql.ErrNoRows {
-            return nil, errors.New("collector not found")
+            return nil, ErrCollectorNotFound
         }
         return nil, err
     }
 
-    // Parse timestamps manually
-    c.CreatedAt, err = time.Parse(time.RFC3339, createdAtStr)
-    if err != nil {
-        return nil, fmt.Errorf("failed to parse created_at: %w", err)
-    }
-
-    c.UpdatedAt, err = time.Parse(time.RFC3339, updatedAtStr)
-    if err != nil {
-        return nil, fmt.Errorf("failed to parse updated_at: %w", err)
-    }
-
     return &c, nil
 }
\ No newline at end of file
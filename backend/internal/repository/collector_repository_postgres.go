@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"agri-sync-backend/internal/models"
+)
+
+// PostgresCollectorRepository is the CollectorStore for PostgreSQL.
+type PostgresCollectorRepository struct {
+	db DBTX
+}
+
+func NewPostgresCollectorRepository(db DBTX) *PostgresCollectorRepository {
+	return &PostgresCollectorRepository{db: db}
+}
+
+const collectorColumns = `id, name, phone, password_hash, version, created_at, updated_at`
+
+// CREATE
+func (r *PostgresCollectorRepository) Create(ctx context.Context, c *models.Collector) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.Version = 1
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO collectors (`+collectorColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		c.ID, c.Name, c.Phone, c.PasswordHash, c.Version, c.CreatedAt, c.UpdatedAt,
+	)
+	return err
+}
+
+// READ
+func (r *PostgresCollectorRepository) GetByID(ctx context.Context, id string) (*models.Collector, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	c, err := scanPostgresCollector(r.db.QueryRowContext(ctx, `SELECT `+collectorColumns+` FROM collectors WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrCollectorNotFound
+	}
+	return c, err
+}
+
+func (r *PostgresCollectorRepository) GetByPhone(ctx context.Context, phone string) (*models.Collector, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	c, err := scanPostgresCollector(r.db.QueryRowContext(ctx, `SELECT `+collectorColumns+` FROM collectors WHERE phone = $1`, phone))
+	if err == sql.ErrNoRows {
+		return nil, ErrCollectorNotFound
+	}
+	return c, err
+}
+
+// UPDATE
+func (r *PostgresCollectorRepository) Update(ctx context.Context, c *models.Collector) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	c.Version++
+	c.UpdatedAt = timeNow()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE collectors
+		SET name = $1, phone = $2, version = $3, updated_at = $4
+		WHERE id = $5`,
+		c.Name, c.Phone, c.Version, c.UpdatedAt, c.ID,
+	)
+	return err
+}
+
+// DELETE
+func (r *PostgresCollectorRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM collectors WHERE id = $1`, id)
+	return err
+}
+
+func scanPostgresCollector(s rowScanner) (*models.Collector, error) {
+	var c models.Collector
+	var phone, passwordHash sql.NullString
+
+	err := s.Scan(&c.ID, &c.Name, &phone, &passwordHash, &c.Version, timeColumn{&c.CreatedAt}, timeColumn{&c.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+	c.Phone = phone.String
+	c.PasswordHash = passwordHash.String
+	return &c, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"agri-sync-backend/internal/models"
+)
+
+var ErrCropTypeNotFound = errors.New("crop type not found")
+
+type CropTypeRepository struct {
+	db DBTX
+}
+
+func NewCropTypeRepository(db DBTX) *CropTypeRepository {
+	return &CropTypeRepository{db: db}
+}
+
+const cropTypeColumns = `code, name_en, name_sw, unit, active, allowed_grades,
+	version, created_at, updated_at`
+
+// NormalizeCropCode maps free text such as " Tea" onto a registry code.
+func NormalizeCropCode(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// CREATE
+func (r *CropTypeRepository) Create(ctx context.Context, ct *models.CropType) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	ct.CreatedAt = now
+	ct.UpdatedAt = now
+	ct.Version = 1
+
+	grades, err := marshalGrades(&ct.AllowedGrades)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO crop_types (`+cropTypeColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		ct.Code, ct.NameEn, ct.NameSw, ct.Unit, ct.Active, grades,
+		ct.Version, formatTime(ct.CreatedAt), formatTime(ct.UpdatedAt),
+	)
+	return err
+}
+
+// READ
+func (r *CropTypeRepository) GetByCode(ctx context.Context, code string) (*models.CropType, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+cropTypeColumns+` FROM crop_types WHERE code = ?`, code)
+	ct, err := scanCropType(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrCropTypeNotFound
+	}
+	return ct, err
+}
+
+// UPDATE
+func (r *CropTypeRepository) Update(ctx context.Context, ct *models.CropType) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ct.Version++
+	ct.UpdatedAt = timeNow()
+
+	grades, err := marshalGrades(&ct.AllowedGrades)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE crop_types
+		SET name_en = ?, name_sw = ?, unit = ?, active = ?, allowed_grades = ?,
+		    version = ?, updated_at = ?
+		WHERE code = ?`,
+		ct.NameEn, ct.NameSw, ct.Unit, ct.Active, grades,
+		ct.Version, formatTime(ct.UpdatedAt), ct.Code,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCropTypeNotFound
+	}
+	return nil
+}
+
+// ListChangedSince returns crop types updated at or after since, including
+// inactive ones so devices learn about deactivations. A zero since returns
+// the whole registry.
+func (r *CropTypeRepository) ListChangedSince(ctx context.Context, since time.Time) ([]*models.CropType, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+cropTypeColumns+` FROM crop_types
+		WHERE updated_at >= ?
+		ORDER BY code`, formatTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.CropType
+	for rows.Next() {
+		ct, err := scanCropType(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, ct)
+	}
+	return list, rows.Err()
+}
+
+func scanCropType(s rowScanner) (*models.CropType, error) {
+	var ct models.CropType
+	var grades string
+
+	err := s.Scan(&ct.Code, &ct.NameEn, &ct.NameSw, &ct.Unit, &ct.Active, &grades,
+		&ct.Version, timeColumn{&ct.CreatedAt}, timeColumn{&ct.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(grades), &ct.AllowedGrades); err != nil {
+		return nil, fmt.Errorf("failed to parse allowed_grades: %w", err)
+	}
+	return &ct, nil
+}
+
+func marshalGrades(grades *[]string) (string, error) {
+	if *grades == nil {
+		*grades = []string{}
+	}
+	b, err := json.Marshal(*grades)
+	return string(b), err
+}
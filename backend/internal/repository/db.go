@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agri-sync-backend/internal/database"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DBTX is what repositories run their queries on: the shared *sql.DB, or a
+// *sql.Tx handed out by WithTx.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// DB is a database repositories can also open transactions on: a *sql.DB,
+// or the *database.DB that database.Connect returns.
+type DB interface {
+	DBTX
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// DefaultQueryTimeout bounds a repository call until SetQueryTimeout says
+// otherwise.
+const DefaultQueryTimeout = 5 * time.Second
+
+var queryTimeout atomic.Int64
+
+func init() {
+	queryTimeout.Store(int64(DefaultQueryTimeout))
+}
+
+// SetQueryTimeout sets how long a single repository call may take before
+// its context is cancelled. Zero or less disables the limit, leaving only
+// the caller's deadline.
+func SetQueryTimeout(d time.Duration) {
+	queryTimeout.Store(int64(d))
+}
+
+// QueryTimeout returns the limit set by SetQueryTimeout.
+func QueryTimeout() time.Duration {
+	return time.Duration(queryTimeout.Load())
+}
+
+// QueryObserver is told how long each repository call took. method names
+// the call, as in "FarmerRepository.GetByPhone".
+type QueryObserver func(method string, took time.Duration)
+
+var queryObserver atomic.Pointer[QueryObserver]
+
+// SetQueryObserver has every repository call reported to observe, or to
+// nothing when observe is nil.
+func SetQueryObserver(observe QueryObserver) {
+	if observe == nil {
+		queryObserver.Store(nil)
+		return
+	}
+	queryObserver.Store(&observe)
+}
+
+var tracer = otel.Tracer("agri-sync-backend/internal/repository")
+
+// withTimeout derives the context a repository call runs under. Every
+// repository method starts with it and defers the cancel func, so this is
+// where each call gets its span, and where the cancel func ends the span
+// and reports the call's duration.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	pc, _, _, _ := runtime.Caller(1)
+	method := methodName(pc)
+	// Calls made outside a traced request or job, such as by health
+	// checks and metrics scrapes, get no span of their own
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		ctx, span = tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	var cancel context.CancelFunc
+	if d := QueryTimeout(); d <= 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, d)
+	}
+
+	observe := queryObserver.Load()
+	start := time.Now()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			// Set only if the call ran out of time or its caller gave up
+			if err := ctx.Err(); err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+			if observe != nil {
+				(*observe)(method, time.Since(start))
+			}
+		})
+		cancel()
+	}
+}
+
+var methodNames sync.Map // pc -> string
+
+// methodName turns the function at pc, such as
+// "agri-sync-backend/internal/repository.(*FarmerRepository).GetByPhone",
+// into "FarmerRepository.GetByPhone".
+func methodName(pc uintptr) string {
+	if name, ok := methodNames.Load(pc); ok {
+		return name.(string)
+	}
+	name := "unknown"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = fn.Name()
+		name = name[strings.LastIndex(name, "/")+1:]
+		name = strings.TrimPrefix(name, "repository.")
+		name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+	}
+	methodNames.Store(pc, name)
+	return name
+}
+
+// WithTx runs fn in one transaction on db, committing if fn returns nil and
+// rolling back otherwise. Repositories built on tx take part in it, and so
+// do repositories built on a *database.DB when called with the ctx fn is
+// given: their statements go to tx, and their multi-statement methods join
+// tx instead of opening their own. Called with a ctx that is already inside
+// a transaction on db, WithTx runs fn in that one, leaving the commit to
+// whoever began it.
+//
+// Once a statement inside fn fails, return the error rather than carrying
+// on; PostgreSQL refuses further statements in the transaction anyway.
+func WithTx(ctx context.Context, db DB, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if tx := database.TxFrom(ctx, db); tx != nil {
+		return fn(ctx, tx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(database.WithinTx(ctx, db, tx), tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// txn is the transaction a multi-statement repository method runs in. On a
+// repository bound to a WithTx transaction, or called inside one, it is that
+// transaction, and Commit and Rollback are left to WithTx.
+type txn struct {
+	DBTX
+	own *sql.Tx
+}
+
+func beginTx(ctx context.Context, db DBTX) (*txn, error) {
+	switch db := db.(type) {
+	case *sql.Tx:
+		return &txn{DBTX: db}, nil
+	case DB:
+		if tx := database.TxFrom(ctx, db); tx != nil {
+			return &txn{DBTX: tx}, nil
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &txn{DBTX: tx, own: tx}, nil
+	}
+	return nil, fmt.Errorf("repository: cannot begin a transaction on %T", db)
+}
+
+func (t *txn) Commit() error {
+	if t.own == nil {
+		return nil
+	}
+	return t.own.Commit()
+}
+
+func (t *txn) Rollback() error {
+	if t.own == nil {
+		return nil
+	}
+	return t.own.Rollback()
+}
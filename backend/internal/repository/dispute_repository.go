@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"agri-sync-backend/internal/models"
+)
+
+var (
+	ErrDisputeNotFound = errors.New("dispute not found")
+	ErrDisputeOpen     = errors.New("collection already has an open dispute")
+	ErrDisputeClosed   = errors.New("dispute already resolved")
+)
+
+type DisputeRepository struct {
+	db DBTX
+}
+
+func NewDisputeRepository(db DBTX) *DisputeRepository {
+	return &DisputeRepository{db: db}
+}
+
+const disputeColumns = `id, collection_id, farmer_id, collector_id, reason,
+	proposed_weight_kg, proposed_price_per_kg, status, resolution, adjustment_id,
+	resolved_by, resolved_at, version, created_at, updated_at`
+
+// DisputeFilter narrows List. Empty fields match everything.
+type DisputeFilter struct {
+	Status       models.DisputeStatus
+	FarmerID     string
+	CollectorID  string
+	CollectionID string
+}
+
+// CREATE (with the notifications announcing it)
+func (r *DisputeRepository) Create(ctx context.Context, d *models.Dispute, notes []*models.Notification) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	d.Status = models.DisputeOpen
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	d.Version = 1
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var n int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM disputes WHERE collection_id = ? AND status = ?`,
+		d.CollectionID, models.DisputeOpen).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrDisputeOpen
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO disputes (`+disputeColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, '', NULL, NULL, NULL, ?, ?, ?)`,
+		d.ID, d.CollectionID, d.FarmerID, d.CollectorID, d.Reason,
+		d.ProposedWeightKg, d.ProposedPricePerKg, d.Status,
+		d.Version, formatTime(now), formatTime(now),
+	)
+	if err != nil {
+		return err
+	}
+	if err := insertNotifications(ctx, tx, notes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// READ
+func (r *DisputeRepository) GetByID(ctx context.Context, id string) (*models.Dispute, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE id = ?`, id)
+	d, err := scanDispute(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrDisputeNotFound
+	}
+	return d, err
+}
+
+// List returns disputes matching the filter, oldest first, so the review
+// queue is worked in the order disputes were raised.
+func (r *DisputeRepository) List(ctx context.Context, f DisputeFilter) ([]*models.Dispute, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + disputeColumns + ` FROM disputes WHERE 1 = 1`
+	var args []any
+	if f.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, f.Status)
+	}
+	if f.FarmerID != "" {
+		query += ` AND farmer_id = ?`
+		args = append(args, f.FarmerID)
+	}
+	if f.CollectorID != "" {
+		query += ` AND collector_id = ?`
+		args = append(args, f.CollectorID)
+	}
+	if f.CollectionID != "" {
+		query += ` AND collection_id = ?`
+		args = append(args, f.CollectionID)
+	}
+	query += ` ORDER BY created_at, rowid`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.Dispute{}
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// Resolve closes an open dispute at the given version. When adj is not nil
+// (an upheld dispute) it is recorded in the same transaction against the
+// collection at collectionVersion, as AdjustmentRepository.Create does, and
+// so are the notifications.
+func (r *DisputeRepository) Resolve(ctx context.Context, d *models.Dispute, adj *models.Adjustment, collectionVersion int, notes []*models.Notification) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var adjustmentID any
+	if adj != nil {
+		if err := insertAdjustment(ctx, tx, adj, collectionVersion); err != nil {
+			return err
+		}
+		adjustmentID = adj.ID
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE disputes
+		SET status = ?, resolution = ?, adjustment_id = ?, resolved_by = ?, resolved_at = ?,
+		    version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ? AND status = ?`,
+		d.Status, d.Resolution, adjustmentID, d.ResolvedBy, formatTime(now),
+		formatTime(now), d.ID, d.Version, models.DisputeOpen,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		var status models.DisputeStatus
+		if err := tx.QueryRowContext(ctx, `SELECT status FROM disputes WHERE id = ?`, d.ID).Scan(&status); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrDisputeNotFound
+			}
+			return err
+		}
+		if status != models.DisputeOpen {
+			return ErrDisputeClosed
+		}
+		return ErrConflict
+	}
+	if err := insertNotifications(ctx, tx, notes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if adj != nil {
+		d.AdjustmentID = adj.ID
+	}
+	d.ResolvedAt = &now
+	d.UpdatedAt = now
+	d.Version++
+	return nil
+}
+
+// StatsByCollector counts disputes raised between from and to against each
+// collector's records, with the total paid out on upheld ones.
+func (r *DisputeRepository) StatsByCollector(ctx context.Context, from, to time.Time) ([]*models.CollectorDisputeStats, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT d.collector_id, COALESCE(c.name, ''),
+		       COUNT(*),
+		       SUM(CASE WHEN d.status = 'open' THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN d.status = 'upheld' THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN d.status = 'rejected' THEN 1 ELSE 0 END),
+		       COALESCE(SUM(a.amount), 0)
+		FROM disputes d
+		LEFT JOIN collectors c ON c.id = d.collector_id
+		LEFT JOIN collection_adjustments a ON a.id = d.adjustment_id
+		WHERE d.created_at >= ? AND d.created_at < ?
+		GROUP BY d.collector_id, c.name
+		ORDER BY COUNT(*) DESC, d.collector_id`,
+		formatTime(from), formatTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*models.CollectorDisputeStats{}
+	for rows.Next() {
+		var s models.CollectorDisputeStats
+		if err := rows.Scan(&s.CollectorID, &s.CollectorName, &s.Total, &s.Open, &s.Upheld, &s.Rejected, &s.AdjustedTotal); err != nil {
+			return nil, err
+		}
+		if resolved := s.Upheld + s.Rejected; resolved > 0 {
+			s.UpheldRate = float64(s.Upheld) / float64(resolved)
+		}
+		stats = append(stats, &s)
+	}
+	return stats, rows.Err()
+}
+
+func scanDispute(s rowScanner) (*models.Dispute, error) {
+	var d models.Dispute
+	var weight, price sql.NullFloat64
+	var adjustmentID, resolvedBy sql.NullString
+
+	err := s.Scan(&d.ID, &d.CollectionID, &d.FarmerID, &d.CollectorID, &d.Reason,
+		&weight, &price, &d.Status, &d.Resolution, &adjustmentID,
+		&resolvedBy, nullTimeColumn{&d.ResolvedAt}, &d.Version, timeColumn{&d.CreatedAt}, timeColumn{&d.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	if weight.Valid {
+		d.ProposedWeightKg = &weight.Float64
+	}
+	if price.Valid {
+		d.ProposedPricePerKg = &price.Float64
+	}
+	d.AdjustmentID = adjustmentID.String
+	d.ResolvedBy = resolvedBy.String
+	return &d, nil
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"agri-sync-backend/internal/models"
+)
+
+var (
+	ErrFarmNotFound = errors.New("farm not found")
+	ErrPlotNotFound = errors.New("plot not found")
+)
+
+type FarmRepository struct {
+	db DBTX
+}
+
+func NewFarmRepository(db DBTX) *FarmRepository {
+	return &FarmRepository{db: db}
+}
+
+const farmColumns = `id, farmer_id, name, area_acres, boundary, version, created_at, updated_at, deleted_at`
+
+const plotColumns = `id, farm_id, name, crop_type, area_acres, boundary, stock_count, stock_unit,
+	created_at, updated_at, deleted_at`
+
+// CREATE (farm and its plots in one transaction)
+func (r *FarmRepository) Create(ctx context.Context, f *models.Farm) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	f.CreatedAt = now
+	f.UpdatedAt = now
+	f.Version = 1
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO farms (`+farmColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULL)`,
+		f.ID, f.FarmerID, f.Name, f.AreaAcres, nullableJSON(f.Boundary),
+		f.Version, formatTime(f.CreatedAt), formatTime(f.UpdatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	if err := savePlots(ctx, tx, f); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// READ (with live plots). Deleted farms are reported as not found.
+func (r *FarmRepository) GetByID(ctx context.Context, id string) (*models.Farm, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+farmColumns+` FROM farms WHERE id = ? AND deleted_at IS NULL`, id)
+	f, err := scanFarm(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrFarmNotFound
+		}
+		return nil, err
+	}
+	if f.Plots, err = r.listPlots(ctx, f.ID, false); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UPDATE replaces the farm's fields and plots if f.Version matches the
+// stored version. Plots left out of f.Plots are soft-deleted; plot IDs are
+// kept so collections recorded against them stay linked.
+func (r *FarmRepository) Update(ctx context.Context, f *models.Farm) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE farms
+		SET name = ?, area_acres = ?, boundary = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ? AND deleted_at IS NULL`,
+		f.Name, f.AreaAcres, nullableJSON(f.Boundary), formatTime(now), f.ID, f.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return r.missingOrConflict(ctx, tx, f.ID)
+	}
+
+	keep := make([]any, 0, len(f.Plots)+2)
+	keep = append(keep, formatTime(now), f.ID)
+	placeholders := ""
+	for _, p := range f.Plots {
+		keep = append(keep, p.ID)
+		placeholders += ", ?"
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE plots SET deleted_at = ?1, updated_at = ?1
+		WHERE farm_id = ?2 AND deleted_at IS NULL AND id NOT IN (''`+placeholders+`)`, keep...)
+	if err != nil {
+		return err
+	}
+	if err := savePlots(ctx, tx, f); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	f.Version++
+	f.UpdatedAt = now
+	return nil
+}
+
+// DELETE marks the farm and its plots deleted, if version matches.
+func (r *FarmRepository) Delete(ctx context.Context, id string, version int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := formatTime(timeNow())
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE farms SET deleted_at = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL`,
+		now, now, id, version,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return r.missingOrConflict(ctx, tx, id)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE plots SET deleted_at = ?, updated_at = ?
+		WHERE farm_id = ? AND deleted_at IS NULL`, now, now, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// List returns farms with their plots, for one farmer or for everyone when
+// farmerID is empty. A zero since returns the live registry; otherwise every
+// farm changed at or after since is returned, including deleted farms and
+// plots, so devices can apply removals.
+func (r *FarmRepository) List(ctx context.Context, farmerID string, since time.Time) ([]*models.Farm, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	withDeleted := !since.IsZero()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+farmColumns+` FROM farms
+		WHERE (? = '' OR farmer_id = ?)
+		  AND updated_at >= ?
+		  AND (? OR deleted_at IS NULL)
+		ORDER BY updated_at`,
+		farmerID, farmerID, formatTime(since), withDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*models.Farm
+	for rows.Next() {
+		f, err := scanFarm(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, f := range list {
+		if f.Plots, err = r.listPlots(ctx, f.ID, withDeleted); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// GetPlot returns a live plot and the farmer who owns it.
+func (r *FarmRepository) GetPlot(ctx context.Context, id string) (*models.Plot, string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT p.id, p.farm_id, p.name, p.crop_type, p.area_acres, p.boundary, p.stock_count, p.stock_unit,
+		       p.created_at, p.updated_at, p.deleted_at, f.farmer_id
+		FROM plots p
+		JOIN farms f ON f.id = p.farm_id
+		WHERE p.id = ? AND p.deleted_at IS NULL`, id)
+
+	var farmerID string
+	p, err := scanPlot(row, &farmerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", ErrPlotNotFound
+		}
+		return nil, "", err
+	}
+	return p, farmerID, nil
+}
+
+// missingOrConflict explains why a versioned write touched no rows.
+func (r *FarmRepository) missingOrConflict(ctx context.Context, tx DBTX, id string) error {
+	var n int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM farms WHERE id = ? AND deleted_at IS NULL`, id).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrFarmNotFound
+	}
+	return ErrConflict
+}
+
+func (r *FarmRepository) listPlots(ctx context.Context, farmID string, withDeleted bool) ([]*models.Plot, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+plotColumns+` FROM plots
+		WHERE farm_id = ? AND (? OR deleted_at IS NULL)
+		ORDER BY name`, farmID, withDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plots := []*models.Plot{}
+	for rows.Next() {
+		p, err := scanPlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		plots = append(plots, p)
+	}
+	return plots, rows.Err()
+}
+
+// savePlots inserts new plots and updates existing ones, reviving any that
+// had been deleted. A plot ID belonging to another farm is left untouched
+// and fails with ErrPlotNotFound.
+func savePlots(ctx context.Context, tx DBTX, f *models.Farm) error {
+	now := timeNow()
+	for _, p := range f.Plots {
+		p.FarmID = f.ID
+		p.UpdatedAt = now
+		if p.CreatedAt.IsZero() {
+			p.CreatedAt = now
+		}
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO plots (`+plotColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL)
+			ON CONFLICT(id) DO UPDATE SET
+				name = excluded.name, crop_type = excluded.crop_type, area_acres = excluded.area_acres,
+				boundary = excluded.boundary, stock_count = excluded.stock_count, stock_unit = excluded.stock_unit,
+				updated_at = excluded.updated_at, deleted_at = NULL
+			WHERE plots.farm_id = excluded.farm_id`,
+			p.ID, p.FarmID, p.Name, p.CropType, p.AreaAcres, nullableJSON(p.Boundary), p.StockCount, p.StockUnit,
+			formatTime(p.CreatedAt), formatTime(p.UpdatedAt),
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrPlotNotFound
+		}
+	}
+	return nil
+}
+
+func scanFarm(s rowScanner) (*models.Farm, error) {
+	var f models.Farm
+	var boundary sql.NullString
+
+	err := s.Scan(&f.ID, &f.FarmerID, &f.Name, &f.AreaAcres, &boundary,
+		&f.Version, timeColumn{&f.CreatedAt}, timeColumn{&f.UpdatedAt}, nullTimeColumn{&f.DeletedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	if boundary.Valid {
+		f.Boundary = json.RawMessage(boundary.String)
+	}
+	return &f, nil
+}
+
+// scanPlot scans plotColumns followed by any extra destinations.
+func scanPlot(s rowScanner, extra ...any) (*models.Plot, error) {
+	var p models.Plot
+	var boundary sql.NullString
+
+	dest := append([]any{&p.ID, &p.FarmID, &p.Name, &p.CropType, &p.AreaAcres, &boundary,
+		&p.StockCount, &p.StockUnit, timeColumn{&p.CreatedAt}, timeColumn{&p.UpdatedAt}, nullTimeColumn{&p.DeletedAt}}, extra...)
+	if err := s.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if boundary.Valid {
+		p.Boundary = json.RawMessage(boundary.String)
+	}
+	return &p, nil
+}
+
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
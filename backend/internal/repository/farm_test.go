@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TestFarmForeignPlot checks that a farm cannot take over, or silently
+// drop, a plot ID that belongs to another farm.
+func TestFarmForeignPlot(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := t.Context()
+		farmer := &models.Farmer{ID: uuid.New().String(), Name: "Plot Farmer", Phone: "+2547" + b.run + "6"}
+		if err := b.stores.Farmers.Create(ctx, farmer); err != nil {
+			t.Fatalf("fixture farmer: %v", err)
+		}
+		farms := repository.NewFarmRepository(b.db)
+		plot := &models.Plot{ID: uuid.New().String(), Name: "Upper", CropType: "tea"}
+		owner := &models.Farm{ID: uuid.New().String(), FarmerID: farmer.ID, Name: "Owner", Plots: []*models.Plot{plot}}
+		other := &models.Farm{ID: uuid.New().String(), FarmerID: farmer.ID, Name: "Other"}
+		for _, f := range []*models.Farm{owner, other} {
+			if err := farms.Create(ctx, f); err != nil {
+				t.Fatalf("create %s: %v", f.Name, err)
+			}
+		}
+
+		foreign := func() []*models.Plot {
+			return []*models.Plot{{ID: plot.ID, Name: "Taken", CropType: "coffee"}}
+		}
+		renamed := *other
+		renamed.Name, renamed.Plots = "Renamed", foreign()
+		if err := farms.Update(ctx, &renamed); !errors.Is(err, repository.ErrPlotNotFound) {
+			t.Errorf("update with a foreign plot: err = %v, want %v", err, repository.ErrPlotNotFound)
+		}
+		created := &models.Farm{ID: uuid.New().String(), FarmerID: farmer.ID, Name: "New", Plots: foreign()}
+		if err := farms.Create(ctx, created); !errors.Is(err, repository.ErrPlotNotFound) {
+			t.Errorf("create with a foreign plot: err = %v, want %v", err, repository.ErrPlotNotFound)
+		}
+
+		if got, err := farms.GetByID(ctx, other.ID); err != nil {
+			t.Errorf("get other: %v", err)
+		} else if got.Name != "Other" || got.Version != other.Version || len(got.Plots) != 0 {
+			t.Errorf("other farm changed: %q version %d with %d plots", got.Name, got.Version, len(got.Plots))
+		}
+		if _, err := farms.GetByID(ctx, created.ID); !errors.Is(err, repository.ErrFarmNotFound) {
+			t.Errorf("rejected farm was created: err = %v", err)
+		}
+		if got, err := farms.GetByID(ctx, owner.ID); err != nil {
+			t.Errorf("get owner: %v", err)
+		} else if len(got.Plots) != 1 || got.Plots[0].Name != "Upper" || got.Plots[0].CropType != "tea" {
+			t.Errorf("owner's plot changed: %+v", got.Plots)
+		}
+	})
+}
@@ -1,35 +1,37 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-	"time"
-	"errors"
+
 	"agri-sync-backend/internal/models"
-	"fmt"
 )
 
 type FarmerRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // Constructor
-func NewFarmerRepository(db *sql.DB) *FarmerRepository {
+func NewFarmerRepository(db DBTX) *FarmerRepository {
 	return &FarmerRepository{db: db}
 }
 
 // -------------------------
 // CREATE
 // -------------------------
-func (r *FarmerRepository) Create(f *models.Farmer) error {
-	now := time.Now().UTC()
+func (r *FarmerRepository) Create(ctx context.Context, f *models.Farmer) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
 	f.CreatedAt = now
 	f.UpdatedAt = now
 	f.Version = 1
 
-	_, err := r.db.Exec(`
+	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO farmers (id, name, phone, password_hash, version, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		f.ID, f.Name, f.Phone, f.PasswordHash, f.Version, f.CreatedAt.Format(time.RFC3339), f.UpdatedAt.Format(time.RFC3339),
+		f.ID, f.Name, f.Phone, f.PasswordHash, f.Version, formatTime(f.CreatedAt), formatTime(f.UpdatedAt),
 	)
 	return err
 }
@@ -37,83 +39,102 @@ func (r *FarmerRepository) Create(f *models.Farmer) error {
 // -------------------------
 // READ
 // -------------------------
-func (r *FarmerRepository) GetByID(id string) (*models.Farmer, error) {
-	row := r.db.QueryRow(`
-		SELECT id, name, phone, password_hash, version, created_at, updated_at
+func (r *FarmerRepository) GetByID(ctx context.Context, id string) (*models.Farmer, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, name, phone, password_hash, latitude, longitude, version, created_at, updated_at
 		FROM farmers WHERE id = ?`, id)
 
 	var f models.Farmer
-	var createdAt, updatedAt string
-	err := row.Scan(&f.ID, &f.Name, &f.Phone, &f.PasswordHash, &f.Version, &createdAt, &updatedAt)
+	var lat, lng sql.NullFloat64
+	err := row.Scan(&f.ID, &f.Name, &f.Phone, &f.PasswordHash, &lat, &lng, &f.Version, timeColumn{&f.CreatedAt}, timeColumn{&f.UpdatedAt})
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrFarmerNotFound
+		}
 		return nil, err
 	}
-
-	f.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
-	f.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	if lat.Valid && lng.Valid {
+		f.Latitude, f.Longitude = &lat.Float64, &lng.Float64
+	}
 	return &f, nil
 }
 
 // -------------------------
 // UPDATE (idempotent with version)
 // -------------------------
-func (r *FarmerRepository) Update(f *models.Farmer) error {
+func (r *FarmerRepository) Update(ctx context.Context, f *models.Farmer) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	// Increment version
 	f.Version++
-	f.UpdatedAt = time.Now().UTC()
+	f.UpdatedAt = timeNow()
 
-	_, err := r.db.Exec(`
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE farmers 
 		SET name = ?, phone = ?, version = ?, updated_at = ?
 		WHERE id = ?`,
-		f.Name, f.Phone, f.Version, f.UpdatedAt.Format(time.RFC3339), f.ID,
+		f.Name, f.Phone, f.Version, formatTime(f.UpdatedAt), f.ID,
 	)
 	return err
 }
 
+// SetLocation records the farmer's registered farm location.
+func (r *FarmerRepository) SetLocation(ctx context.Context, id string, lat, lng float64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE farmers
+		SET latitude = ?, longitude = ?, version = version + 1, updated_at = ?
+		WHERE id = ?`,
+		lat, lng, formatTime(timeNow()), id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrFarmerNotFound
+	}
+	return nil
+}
+
 // -------------------------
 // DELETE
 // -------------------------
-func (r *FarmerRepository) Delete(id string) error {
-	_, err := r.db.Exec(`DELETE FROM farmers WHERE id = ?`, id)
+func (r *FarmerRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM farmers WHERE id = ?`, id)
 	return err
 }
 
 
-func (r *FarmerRepository) GetByPhone(phone string) (*models.Farmer, error) {
-    row := r.db.QueryRow(`
-        SELECT id, name, phone, password_hash, version, created_at, updated_at
-        FROM farmers WHERE phone = $1`, phone)
-
-    var f models.Farmer
-    var createdAtStr, updatedAtStr string   // ← change to string
-
-    err := row.Scan(
-        &f.ID,
-        &f.Name,
-        &f.Phone,
-        &f.PasswordHash,
-        &f.Version,
-        &createdAtStr,   // ← scan as string
-        &updatedAtStr,
-    )
-    if err != nil {
-        if err == sql.ErrNoRows {
-            return nil, errors.New("farmer not found")
-        }
-        return nil, err
-    }
-
-    // Manually parse the strings to time.Time
-    f.CreatedAt, err = time.Parse(time.RFC3339, createdAtStr)
-    if err != nil {
-        return nil, fmt.Errorf("failed to parse created_at: %w", err)
-    }
-
-    f.UpdatedAt, err = time.Parse(time.RFC3339, updatedAtStr)
-    if err != nil {
-        return nil, fmt.Errorf("failed to parse updated_at: %w", err)
-    }
-
-    return &f, nil
-}
\ No newline at end of file
+// GetByPhone finds the farmer registered with a phone number, for login.
+func (r *FarmerRepository) GetByPhone(ctx context.Context, phone string) (*models.Farmer, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, name, phone, password_hash, latitude, longitude, version, created_at, updated_at
+		FROM farmers WHERE phone = ?`, phone)
+
+	var f models.Farmer
+	var lat, lng sql.NullFloat64
+	err := row.Scan(&f.ID, &f.Name, &f.Phone, &f.PasswordHash, &lat, &lng, &f.Version, timeColumn{&f.CreatedAt}, timeColumn{&f.UpdatedAt})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrFarmerNotFound
+		}
+		return nil, err
+	}
+	if lat.Valid && lng.Valid {
+		f.Latitude, f.Longitude = &lat.Float64, &lng.Float64
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"agri-sync-backend/internal/models"
+)
+
+// PostgresFarmerRepository is the FarmerStore for PostgreSQL, where
+// timestamps are TIMESTAMPTZ columns.
+type PostgresFarmerRepository struct {
+	db DBTX
+}
+
+func NewPostgresFarmerRepository(db DBTX) *PostgresFarmerRepository {
+	return &PostgresFarmerRepository{db: db}
+}
+
+const farmerColumns = `id, name, phone, password_hash, latitude, longitude, version, created_at, updated_at`
+
+// CREATE
+func (r *PostgresFarmerRepository) Create(ctx context.Context, f *models.Farmer) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	f.CreatedAt = now
+	f.UpdatedAt = now
+	f.Version = 1
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO farmers (id, name, phone, password_hash, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		f.ID, f.Name, f.Phone, f.PasswordHash, f.Version, f.CreatedAt, f.UpdatedAt,
+	)
+	return err
+}
+
+// READ
+func (r *PostgresFarmerRepository) GetByID(ctx context.Context, id string) (*models.Farmer, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	f, err := scanPostgresFarmer(r.db.QueryRowContext(ctx, `SELECT `+farmerColumns+` FROM farmers WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrFarmerNotFound
+	}
+	return f, err
+}
+
+func (r *PostgresFarmerRepository) GetByPhone(ctx context.Context, phone string) (*models.Farmer, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	f, err := scanPostgresFarmer(r.db.QueryRowContext(ctx, `SELECT `+farmerColumns+` FROM farmers WHERE phone = $1`, phone))
+	if err == sql.ErrNoRows {
+		return nil, ErrFarmerNotFound
+	}
+	return f, err
+}
+
+// UPDATE
+func (r *PostgresFarmerRepository) Update(ctx context.Context, f *models.Farmer) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	f.Version++
+	f.UpdatedAt = timeNow()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE farmers
+		SET name = $1, phone = $2, version = $3, updated_at = $4
+		WHERE id = $5`,
+		f.Name, f.Phone, f.Version, f.UpdatedAt, f.ID,
+	)
+	return err
+}
+
+// SetLocation records the farmer's registered farm location.
+func (r *PostgresFarmerRepository) SetLocation(ctx context.Context, id string, lat, lng float64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE farmers
+		SET latitude = $1, longitude = $2, version = version + 1, updated_at = $3
+		WHERE id = $4`,
+		lat, lng, timeNow(), id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrFarmerNotFound
+	}
+	return nil
+}
+
+// DELETE
+func (r *PostgresFarmerRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM farmers WHERE id = $1`, id)
+	return err
+}
+
+func scanPostgresFarmer(s rowScanner) (*models.Farmer, error) {
+	var f models.Farmer
+	var phone, passwordHash sql.NullString
+	var lat, lng sql.NullFloat64
+
+	err := s.Scan(&f.ID, &f.Name, &phone, &passwordHash, &lat, &lng, &f.Version, timeColumn{&f.CreatedAt}, timeColumn{&f.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+	f.Phone = phone.String
+	f.PasswordHash = passwordHash.String
+	if lat.Valid && lng.Valid {
+		f.Latitude, f.Longitude = &lat.Float64, &lng.Float64
+	}
+	return &f, nil
+}
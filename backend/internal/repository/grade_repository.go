@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"agri-sync-backend/internal/models"
+)
+
+var ErrGradeNotFound = errors.New("grade not found")
+
+type GradeRepository struct {
+	db DBTX
+}
+
+func NewGradeRepository(db DBTX) *GradeRepository {
+	return &GradeRepository{db: db}
+}
+
+const gradeColumns = `id, crop_type, kind, code, name, unit, min_value, max_value,
+	version, created_at, updated_at`
+
+// CREATE
+func (r *GradeRepository) Create(ctx context.Context, g *models.Grade) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	g.CreatedAt = now
+	g.UpdatedAt = now
+	g.Version = 1
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO grades (`+gradeColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		g.ID, g.CropType, g.Kind, g.Code, g.Name, g.Unit, g.MinValue, g.MaxValue,
+		g.Version, formatTime(g.CreatedAt), formatTime(g.UpdatedAt),
+	)
+	return err
+}
+
+// READ
+func (r *GradeRepository) GetByID(ctx context.Context, id string) (*models.Grade, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+gradeColumns+` FROM grades WHERE id = ?`, id)
+	g, err := scanGrade(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrGradeNotFound
+	}
+	return g, err
+}
+
+// GetByCode looks up a definition by its natural key.
+func (r *GradeRepository) GetByCode(ctx context.Context, cropType string, kind models.GradeKind, code string) (*models.Grade, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+gradeColumns+` FROM grades
+		WHERE crop_type = ? AND kind = ? AND code = ?`, cropType, kind, code)
+	g, err := scanGrade(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrGradeNotFound
+	}
+	return g, err
+}
+
+// UPDATE
+func (r *GradeRepository) Update(ctx context.Context, g *models.Grade) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	g.Version++
+	g.UpdatedAt = timeNow()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE grades
+		SET crop_type = ?, kind = ?, code = ?, name = ?, unit = ?, min_value = ?, max_value = ?,
+		    version = ?, updated_at = ?
+		WHERE id = ?`,
+		g.CropType, g.Kind, g.Code, g.Name, g.Unit, g.MinValue, g.MaxValue,
+		g.Version, formatTime(g.UpdatedAt), g.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrGradeNotFound
+	}
+	return nil
+}
+
+// DELETE
+func (r *GradeRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM grades WHERE id = ?`, id)
+	return err
+}
+
+// List returns all definitions, optionally narrowed to one crop.
+func (r *GradeRepository) List(ctx context.Context, cropType string) ([]*models.Grade, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+gradeColumns+` FROM grades
+		WHERE ? = '' OR crop_type = ?
+		ORDER BY crop_type, kind, code`, cropType, cropType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Grade
+	for rows.Next() {
+		g, err := scanGrade(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+func scanGrade(s rowScanner) (*models.Grade, error) {
+	var g models.Grade
+	var minValue, maxValue sql.NullFloat64
+
+	err := s.Scan(&g.ID, &g.CropType, &g.Kind, &g.Code, &g.Name, &g.Unit, &minValue, &maxValue,
+		&g.Version, timeColumn{&g.CreatedAt}, timeColumn{&g.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	if minValue.Valid {
+		g.MinValue = &minValue.Float64
+	}
+	if maxValue.Valid {
+		g.MaxValue = &maxValue.Float64
+	}
+	return &g, nil
+}
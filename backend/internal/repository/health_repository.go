@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNoProbeRow = errors.New("health_probe row is missing")
+
+// HealthRepository backs the readiness checks.
+type HealthRepository struct {
+	db DBTX
+}
+
+func NewHealthRepository(db DBTX) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+// ProbeWrite commits a write to the single health_probe row, proving the
+// database takes writes.
+func (r *HealthRepository) ProbeWrite(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `UPDATE health_probe SET probed_at = ? WHERE id = 1`, formatTime(timeNow()))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNoProbeRow
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"agri-sync-backend/internal/models"
+)
+
+var (
+	ErrLotNotFound      = errors.New("lot not found")
+	ErrLotSealed        = errors.New("lot has been dispatched")
+	ErrLotInTransit     = errors.New("lot is in transit")
+	ErrAlreadyInLot     = errors.New("collection is already in a lot")
+	ErrTransferNotFound = errors.New("transfer not found")
+	ErrAlreadyReceived  = errors.New("transfer already received")
+)
+
+type LotRepository struct {
+	db DBTX
+}
+
+func NewLotRepository(db DBTX) *LotRepository {
+	return &LotRepository{db: db}
+}
+
+const lotColumns = `id, code, center_id, crop_type, weight_kg, status, created_by, version, created_at, updated_at`
+
+const transferColumns = `id, lot_id, position, from_name, to_kind, to_name, weight_out_kg, weight_in_kg,
+	dispatched_by, received_by, dispatched_at, received_at`
+
+// CREATE (lot and its collections in one transaction)
+func (r *LotRepository) Create(ctx context.Context, l *models.Lot) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	l.CreatedAt = now
+	l.UpdatedAt = now
+	l.Version = 1
+	l.Status = models.LotOpen
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO lots (`+lotColumns+`)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?, ?, ?)`,
+		l.ID, l.Code, nullableString(l.CenterID), l.CropType, l.Status, l.CreatedBy,
+		l.Version, formatTime(l.CreatedAt), formatTime(l.UpdatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	if l.WeightKg, err = addLotCollections(ctx, tx, l.ID, l.CollectionIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// READ (with collection IDs and transfers)
+func (r *LotRepository) GetByID(ctx context.Context, id string) (*models.Lot, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+lotColumns+` FROM lots WHERE id = ?`, id)
+	l, err := scanLot(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrLotNotFound
+		}
+		return nil, err
+	}
+	if err := r.loadChildren(ctx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// List returns lots, newest first, optionally only those of one center.
+func (r *LotRepository) List(ctx context.Context, centerID string) ([]*models.Lot, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+lotColumns+` FROM lots
+		WHERE ? = '' OR center_id = ?
+		ORDER BY created_at DESC`, centerID, centerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*models.Lot
+	for rows.Next() {
+		l, err := scanLot(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, l := range list {
+		if err := r.loadChildren(ctx, l); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// AddCollections adds collections to an open lot and updates its weight.
+func (r *LotRepository) AddCollections(ctx context.Context, lotID string, collectionIDs []string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status models.LotStatus
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM lots WHERE id = ?`, lotID).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrLotNotFound
+		}
+		return err
+	}
+	if status != models.LotOpen {
+		return ErrLotSealed
+	}
+
+	if _, err := addLotCollections(ctx, tx, lotID, collectionIDs); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE lots SET version = version + 1, updated_at = ? WHERE id = ?`,
+		formatTime(timeNow()), lotID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Dispatch records a new leg of the lot's journey and seals the lot. The
+// previous leg must have been received.
+func (r *LotRepository) Dispatch(ctx context.Context, t *models.LotTransfer) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	t.DispatchedAt = timeNow()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inTransit int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM lot_transfers WHERE lot_id = ? AND received_at IS NULL`,
+		t.LotID).Scan(&inTransit); err != nil {
+		return err
+	}
+	if inTransit > 0 {
+		return ErrLotInTransit
+	}
+
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(position), 0) + 1 FROM lot_transfers WHERE lot_id = ?`,
+		t.LotID).Scan(&t.Position); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO lot_transfers (`+transferColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NULL, ?, NULL, ?, NULL)`,
+		t.ID, t.LotID, t.Position, t.FromName, t.ToKind, t.ToName, t.WeightOutKg,
+		t.DispatchedBy, formatTime(t.DispatchedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE lots SET status = ?, version = version + 1, updated_at = ? WHERE id = ?`,
+		models.LotDispatched, formatTime(t.DispatchedAt), t.LotID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrLotNotFound
+	}
+	return tx.Commit()
+}
+
+// Receive records the weight that arrived at the end of a leg.
+func (r *LotRepository) Receive(ctx context.Context, lotID, transferID string, weightInKg float64, receivedBy string) (*models.LotTransfer, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := formatTime(timeNow())
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE lot_transfers
+		SET weight_in_kg = ?, received_by = ?, received_at = ?
+		WHERE id = ? AND lot_id = ? AND received_at IS NULL`,
+		weightInKg, receivedBy, now, transferID, lotID)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := res.RowsAffected()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+transferColumns+` FROM lot_transfers WHERE id = ? AND lot_id = ?`, transferID, lotID)
+	t, err := scanTransfer(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+	if n == 0 {
+		return t, ErrAlreadyReceived
+	}
+	return t, nil
+}
+
+// LotOf returns the lot a collection belongs to, or "" if none.
+func (r *LotRepository) LotOf(ctx context.Context, collectionID string) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var lotID string
+	err := r.db.QueryRowContext(ctx, `SELECT lot_id FROM lot_collections WHERE collection_id = ?`, collectionID).Scan(&lotID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return lotID, err
+}
+
+// Trace assembles the lot's provenance: its transfers, and its collections
+// grouped by farmer with the plot each came from.
+func (r *LotRepository) Trace(ctx context.Context, id string) (*models.LotTrace, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	lot, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	trace := &models.LotTrace{Lot: lot, Farmers: []*models.TraceFarmer{}}
+
+	// Shrinkage runs from the weight collected to the last weight received
+	for i := len(lot.Transfers) - 1; i >= 0; i-- {
+		if in := lot.Transfers[i].WeightInKg; in != nil {
+			trace.ShrinkageKg = lot.WeightKg - *in
+			if lot.WeightKg > 0 {
+				trace.ShrinkagePct = trace.ShrinkageKg / lot.WeightKg * 100
+			}
+			break
+		}
+	}
+
+	// Plots are looked up including deleted ones: provenance outlives edits
+	plots := map[string]*models.Plot{}
+	plotRows, err := r.db.QueryContext(ctx, `
+		SELECT `+plotColumns+` FROM plots
+		WHERE id IN (
+			SELECT c.plot_id FROM collections c
+			JOIN lot_collections lc ON lc.collection_id = c.id
+			WHERE lc.lot_id = ?)`, id)
+	if err != nil {
+		return nil, err
+	}
+	for plotRows.Next() {
+		p, err := scanPlot(plotRows)
+		if err != nil {
+			plotRows.Close()
+			return nil, err
+		}
+		plots[p.ID] = p
+	}
+	plotRows.Close()
+	if err := plotRows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+prefixColumns("c", collectionColumns)+`, f.name, f.phone
+		FROM collections c
+		JOIN lot_collections lc ON lc.collection_id = c.id
+		JOIN farmers f ON f.id = c.farmer_id
+		WHERE lc.lot_id = ?
+		ORDER BY f.name, c.created_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byFarmer := map[string]*models.TraceFarmer{}
+	for rows.Next() {
+		var name, phone string
+		col, err := scanCollection(rows, &name, &phone)
+		if err != nil {
+			return nil, err
+		}
+		farmer, ok := byFarmer[col.FarmerID]
+		if !ok {
+			farmer = &models.TraceFarmer{ID: col.FarmerID, Name: name, Phone: phone}
+			byFarmer[col.FarmerID] = farmer
+			trace.Farmers = append(trace.Farmers, farmer)
+		}
+		farmer.WeightKg += col.WeightKg
+		farmer.Deliveries = append(farmer.Deliveries, &models.TraceDelivery{Collection: col, Plot: plots[col.PlotID]})
+	}
+	return trace, rows.Err()
+}
+
+func (r *LotRepository) loadChildren(ctx context.Context, l *models.Lot) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT collection_id FROM lot_collections WHERE lot_id = ? ORDER BY collection_id`, l.ID)
+	if err != nil {
+		return err
+	}
+	l.CollectionIDs = []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		l.CollectionIDs = append(l.CollectionIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT `+transferColumns+` FROM lot_transfers WHERE lot_id = ? ORDER BY position`, l.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	l.Transfers = []*models.LotTransfer{}
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return err
+		}
+		l.Transfers = append(l.Transfers, t)
+	}
+	return rows.Err()
+}
+
+// addLotCollections links collections to a lot and recomputes its weight,
+// returning the new weight.
+func addLotCollections(ctx context.Context, tx DBTX, lotID string, collectionIDs []string) (float64, error) {
+	for _, id := range collectionIDs {
+		var existing string
+		err := tx.QueryRowContext(ctx, `SELECT lot_id FROM lot_collections WHERE collection_id = ?`, id).Scan(&existing)
+		if err == nil {
+			return 0, fmt.Errorf("%w: %s", ErrAlreadyInLot, id)
+		}
+		if err != sql.ErrNoRows {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO lot_collections (collection_id, lot_id) VALUES (?, ?)`, id, lotID); err != nil {
+			return 0, err
+		}
+	}
+
+	var weight float64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(c.weight_kg), 0)
+		FROM lot_collections lc JOIN collections c ON c.id = lc.collection_id
+		WHERE lc.lot_id = ?`, lotID).Scan(&weight); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE lots SET weight_kg = ? WHERE id = ?`, weight, lotID); err != nil {
+		return 0, err
+	}
+	return weight, nil
+}
+
+// prefixColumns qualifies a column list with a table alias.
+func prefixColumns(alias, columns string) string {
+	fields := strings.Split(columns, ",")
+	for i, f := range fields {
+		fields[i] = alias + "." + strings.TrimSpace(f)
+	}
+	return strings.Join(fields, ", ")
+}
+
+func scanLot(s rowScanner) (*models.Lot, error) {
+	var l models.Lot
+	var centerID sql.NullString
+
+	err := s.Scan(&l.ID, &l.Code, &centerID, &l.CropType, &l.WeightKg, &l.Status, &l.CreatedBy,
+		&l.Version, timeColumn{&l.CreatedAt}, timeColumn{&l.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	l.CenterID = centerID.String
+	return &l, nil
+}
+
+func scanTransfer(s rowScanner) (*models.LotTransfer, error) {
+	var t models.LotTransfer
+	var weightIn sql.NullFloat64
+	var receivedBy sql.NullString
+
+	err := s.Scan(&t.ID, &t.LotID, &t.Position, &t.FromName, &t.ToKind, &t.ToName, &t.WeightOutKg, &weightIn,
+		&t.DispatchedBy, &receivedBy, timeColumn{&t.DispatchedAt}, nullTimeColumn{&t.ReceivedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	t.ReceivedBy = receivedBy.String
+	if weightIn.Valid {
+		shrinkage := t.WeightOutKg - weightIn.Float64
+		t.WeightInKg, t.ShrinkageKg = &weightIn.Float64, &shrinkage
+	}
+	return &t, nil
+}
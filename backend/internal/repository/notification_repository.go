@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"agri-sync-backend/internal/models"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationRepository struct {
+	db DBTX
+}
+
+func NewNotificationRepository(db DBTX) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+const notificationColumns = `id, user_id, kind, subject_type, subject_id, message, read_at, created_at`
+
+// insertNotifications records notifications inside the caller's
+// transaction, so they are sent only if the change they announce is saved.
+func insertNotifications(ctx context.Context, db DBTX, notes []*models.Notification) error {
+	now := timeNow()
+	for _, n := range notes {
+		n.CreatedAt = now
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO notifications (`+notificationColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, NULL, ?)`,
+			n.ID, n.UserID, n.Kind, n.SubjectType, n.SubjectID, n.Message, formatTime(now),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListByUser returns a user's notifications, newest first.
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID string, unreadOnly bool) ([]*models.Notification, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+notificationColumns+` FROM notifications
+		WHERE user_id = ? AND (NOT ? OR read_at IS NULL)
+		ORDER BY created_at DESC, rowid DESC`, userID, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.SubjectType, &n.SubjectID, &n.Message, nullTimeColumn{&n.ReadAt}, timeColumn{&n.CreatedAt}); err != nil {
+			return nil, err
+		}
+		list = append(list, &n)
+	}
+	return list, rows.Err()
+}
+
+// MarkRead marks one of the user's notifications as read. Marking it again
+// is harmless.
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = COALESCE(read_at, ?)
+		WHERE id = ? AND user_id = ?`,
+		formatTime(timeNow()), id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read.
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = ?
+		WHERE user_id = ? AND read_at IS NULL`,
+		formatTime(timeNow()), userID)
+	return err
+}
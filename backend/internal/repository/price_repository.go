@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"agri-sync-backend/internal/models"
+)
+
+var ErrPriceNotFound = errors.New("price not found")
+
+type PriceRepository struct {
+	db DBTX
+}
+
+func NewPriceRepository(db DBTX) *PriceRepository {
+	return &PriceRepository{db: db}
+}
+
+const priceColumns = `id, crop_type, grade, region, price_per_unit, unit, multiplier,
+	effective_from, effective_to, version, created_at, updated_at`
+
+// CREATE
+func (r *PriceRepository) Create(ctx context.Context, p *models.Price) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	p.Version = 1
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO prices (`+priceColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.ID, p.CropType, p.Grade, p.Region, p.PricePerUnit, p.Unit, p.Multiplier,
+		formatTime(p.EffectiveFrom), formatOptionalTime(p.EffectiveTo),
+		p.Version, formatTime(p.CreatedAt), formatTime(p.UpdatedAt),
+	)
+	return err
+}
+
+// READ
+func (r *PriceRepository) GetByID(ctx context.Context, id string) (*models.Price, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+priceColumns+` FROM prices WHERE id = ?`, id)
+	p, err := scanPrice(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPriceNotFound
+	}
+	return p, err
+}
+
+// UPDATE
+func (r *PriceRepository) Update(ctx context.Context, p *models.Price) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	p.Version++
+	p.UpdatedAt = timeNow()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE prices
+		SET crop_type = ?, grade = ?, region = ?, price_per_unit = ?, unit = ?, multiplier = ?,
+		    effective_from = ?, effective_to = ?, version = ?, updated_at = ?
+		WHERE id = ?`,
+		p.CropType, p.Grade, p.Region, p.PricePerUnit, p.Unit, p.Multiplier,
+		formatTime(p.EffectiveFrom), formatOptionalTime(p.EffectiveTo),
+		p.Version, formatTime(p.UpdatedAt), p.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPriceNotFound
+	}
+	return nil
+}
+
+// DELETE
+func (r *PriceRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM prices WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPriceNotFound
+	}
+	return nil
+}
+
+// ListActive returns every catalog row in effect at the given time. This is
+// the table devices download and cache for offline use.
+func (r *PriceRepository) ListActive(ctx context.Context, at time.Time) ([]*models.Price, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ts := formatTime(at)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+priceColumns+`
+		FROM prices
+		WHERE effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)
+		ORDER BY crop_type, grade, region`, ts, ts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Price
+	for rows.Next() {
+		p, err := scanPrice(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// FindEffective returns the most specific price for a crop at the given time.
+// A row matching both grade and region wins over one matching only one of
+// them, which in turn wins over the crop's base price.
+func (r *PriceRepository) FindEffective(ctx context.Context, cropType, grade, region string, at time.Time) (*models.Price, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ts := formatTime(at)
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+priceColumns+`
+		FROM prices
+		WHERE crop_type = ?
+		  AND (grade = ? OR grade = '')
+		  AND (region = ? OR region = '')
+		  AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)
+		ORDER BY CASE WHEN grade <> '' THEN 1 ELSE 0 END + CASE WHEN region <> '' THEN 1 ELSE 0 END DESC,
+		         effective_from DESC
+		LIMIT 1`, cropType, grade, region, ts, ts)
+	p, err := scanPrice(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPriceNotFound
+	}
+	return p, err
+}
+
+// Quote resolves the price for a crop, grade and region, returning the
+// price and the unit it is quoted in. Grade rows that carry a multiplier are
+// applied to the crop's base price.
+func (r *PriceRepository) Quote(ctx context.Context, cropType, grade, region string, at time.Time) (float64, string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	p, err := r.FindEffective(ctx, cropType, grade, region, at)
+	if err != nil {
+		return 0, "", err
+	}
+	if p.Multiplier == nil {
+		return p.PricePerUnit, p.Unit, nil
+	}
+
+	base, err := r.FindEffective(ctx, cropType, "", region, at)
+	if err != nil {
+		return 0, "", err
+	}
+	return base.PricePerUnit * *p.Multiplier, base.Unit, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPrice(s rowScanner) (*models.Price, error) {
+	var p models.Price
+	var multiplier sql.NullFloat64
+
+	err := s.Scan(&p.ID, &p.CropType, &p.Grade, &p.Region, &p.PricePerUnit, &p.Unit, &multiplier,
+		timeColumn{&p.EffectiveFrom}, nullTimeColumn{&p.EffectiveTo}, &p.Version, timeColumn{&p.CreatedAt}, timeColumn{&p.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	if multiplier.Valid {
+		p.Multiplier = &multiplier.Float64
+	}
+	return &p, nil
+}
@@ -0,0 +1,606 @@
+package repository_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// storedLayout is how SQLite keeps timestamps: UTC, milliseconds, fixed
+// width. PostgreSQL keeps TIMESTAMPTZ and returns a time.Time instead.
+var storedLayout = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z$`)
+
+// roundTripFixtures are the rows the round-trip subtests hang theirs on.
+// Rows are left in place; several are referenced by lots and disputes,
+// which are never deleted.
+type roundTripFixtures struct {
+	farmer     *models.Farmer
+	collector  *models.Collector
+	center     *models.CollectionCenter
+	collection *models.Collection
+}
+
+// TestTimestampRoundTrips writes every entity through its repository and
+// checks that each timestamp, and each nullable column, reads back exactly
+// as written.
+func TestTimestampRoundTrips(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := t.Context()
+		fx := &roundTripFixtures{
+			farmer:    &models.Farmer{ID: uuid.New().String(), Name: "Round Trip Farmer", Phone: "+2547" + b.run + "7"},
+			collector: &models.Collector{ID: uuid.New().String(), Name: "Round Trip Collector", Phone: "+2547" + b.run + "8"},
+			center:    &models.CollectionCenter{ID: uuid.New().String(), Code: "rt-" + b.run, Name: "Round Trip Center", Active: true},
+		}
+		if err := b.stores.Farmers.Create(ctx, fx.farmer); err != nil {
+			t.Fatalf("farmer create: %v", err)
+		}
+		if err := b.stores.Collectors.Create(ctx, fx.collector); err != nil {
+			t.Fatalf("collector create: %v", err)
+		}
+		if err := repository.NewCenterRepository(b.db).Create(ctx, fx.center); err != nil {
+			t.Fatalf("center create: %v", err)
+		}
+		captured := time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+		lat, lng := -0.4, 36.9
+		fx.collection = &models.Collection{
+			ID: uuid.New().String(), FarmerID: fx.farmer.ID, CollectorID: fx.collector.ID, CenterID: fx.center.ID,
+			CropType: "rt-" + b.run, Quantity: 8, Unit: "kg", WeightKg: 8, PricePerKg: 30,
+			Latitude: &lat, Longitude: &lng, LocationCapturedAt: &captured,
+		}
+		if err := b.stores.Collections.Create(ctx, fx.collection); err != nil {
+			t.Fatalf("collection create: %v", err)
+		}
+
+		t.Run("farmer", func(t *testing.T) { farmerRoundTrip(t, b, fx) })
+		t.Run("collector", func(t *testing.T) { collectorRoundTrip(t, b, fx) })
+		t.Run("clerk", func(t *testing.T) { clerkRoundTrip(t, b) })
+		t.Run("center", func(t *testing.T) { centerRoundTrip(t, b, fx) })
+		t.Run("collection", func(t *testing.T) { collectionRoundTrip(t, b, fx, captured) })
+		t.Run("crop type", func(t *testing.T) { cropTypeRoundTrip(t, b) })
+		t.Run("unit", func(t *testing.T) { unitRoundTrip(t, b) })
+		t.Run("grade", func(t *testing.T) { gradeRoundTrip(t, b) })
+		t.Run("price", func(t *testing.T) { priceRoundTrip(t, b) })
+		t.Run("farm", func(t *testing.T) { farmRoundTrip(t, b, fx) })
+		t.Run("lot", func(t *testing.T) { lotRoundTrip(t, b, fx) })
+		t.Run("scale", func(t *testing.T) { scaleRoundTrip(t, b, fx) })
+		t.Run("attachment", func(t *testing.T) { attachmentRoundTrip(t, b, fx) })
+		t.Run("dispute", func(t *testing.T) { disputeRoundTrip(t, b, fx) })
+		t.Run("audit", func(t *testing.T) { auditRoundTrip(t, b, fx) })
+	})
+}
+
+func farmerRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	checkStored(t, b, "farmers", "created_at", fx.farmer.ID)
+	if err := b.stores.Farmers.Update(ctx, fx.farmer); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := b.stores.Farmers.GetByID(ctx, fx.farmer.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, fx.farmer.CreatedAt, got.UpdatedAt, fx.farmer.UpdatedAt)
+	if got.Latitude != nil || got.Longitude != nil {
+		t.Errorf("location = %v,%v, want none", got.Latitude, got.Longitude)
+	}
+}
+
+func collectorRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	checkStored(t, b, "collectors", "updated_at", fx.collector.ID)
+	if err := b.stores.Collectors.Update(ctx, fx.collector); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := b.stores.Collectors.GetByID(ctx, fx.collector.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, fx.collector.CreatedAt, got.UpdatedAt, fx.collector.UpdatedAt)
+}
+
+func clerkRoundTrip(t *testing.T, b *backend) {
+	ctx := t.Context()
+	repo := repository.NewClerkRepository(b.db)
+	clerk := &models.Clerk{ID: uuid.New().String(), Name: "Round Trip Clerk", Phone: "+2547" + b.run + "9", PasswordHash: "hash"}
+	if err := repo.Create(ctx, clerk); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	checkStored(t, b, "clerks", "created_at", clerk.ID)
+	got, err := repo.GetByPhone(ctx, clerk.Phone)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, clerk.CreatedAt, got.UpdatedAt, clerk.UpdatedAt)
+	if got.ID != clerk.ID || got.PasswordHash != "hash" {
+		t.Errorf("got %s with hash %q, want %s with the stored hash", got.ID, got.PasswordHash, clerk.ID)
+	}
+	if _, err := repo.GetByPhone(ctx, "+2547"+b.run+"0"); err != repository.ErrClerkNotFound {
+		t.Errorf("unknown phone: err = %v, want ErrClerkNotFound", err)
+	}
+}
+
+func centerRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	centers := repository.NewCenterRepository(b.db)
+	if err := centers.Update(ctx, fx.center); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := centers.GetByID(ctx, fx.center.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, fx.center.CreatedAt, got.UpdatedAt, fx.center.UpdatedAt)
+}
+
+func collectionRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures, captured time.Time) {
+	ctx := t.Context()
+	checkStored(t, b, "collections", "created_at", fx.collection.ID)
+	checkStored(t, b, "collections", "location_captured_at", fx.collection.ID)
+	if err := b.stores.Collections.Update(ctx, fx.collection); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := b.stores.Collections.GetByID(ctx, fx.collection.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, fx.collection.CreatedAt, got.UpdatedAt, fx.collection.UpdatedAt)
+	if got.LocationCapturedAt == nil || !sameTime(*got.LocationCapturedAt, captured) {
+		t.Errorf("location_captured_at = %v, want %v", got.LocationCapturedAt, captured)
+	}
+	if got.LocationAccuracyM != nil {
+		t.Errorf("location_accuracy_m = %v, want none", *got.LocationAccuracyM)
+	}
+}
+
+func cropTypeRoundTrip(t *testing.T, b *backend) {
+	ctx := t.Context()
+	cropTypes := repository.NewCropTypeRepository(b.db)
+	ct := &models.CropType{Code: "rt-" + b.run, NameEn: "Round Trip", NameSw: "Safari", Unit: "kg", Active: true}
+	if err := cropTypes.Create(ctx, ct); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := cropTypes.Update(ctx, ct); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := cropTypes.GetByCode(ctx, ct.Code)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, ct.CreatedAt, got.UpdatedAt, ct.UpdatedAt)
+
+	// Sync cursors compare stored timestamps; the boundary is inclusive to
+	// the millisecond.
+	changed, err := cropTypes.ListChangedSince(ctx, ct.UpdatedAt)
+	if err != nil || !hasCropType(changed, ct.Code) {
+		t.Errorf("changed since its updated_at: missing (err = %v)", err)
+	}
+	changed, err = cropTypes.ListChangedSince(ctx, ct.UpdatedAt.Add(time.Millisecond))
+	if err != nil || hasCropType(changed, ct.Code) {
+		t.Errorf("changed 1ms after its updated_at: listed (err = %v)", err)
+	}
+}
+
+func unitRoundTrip(t *testing.T, b *backend) {
+	ctx := t.Context()
+	units := repository.NewUnitRepository(b.db)
+	u := &models.Unit{Code: "rt-" + b.run, NameEn: "Crate", NameSw: "Kreti", Dimension: "count"}
+	if err := units.Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, err := units.GetByCode(ctx, u.Code)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, u.CreatedAt, got.UpdatedAt, u.UpdatedAt)
+
+	uc := &models.UnitConversion{CropType: "rt-" + b.run, Unit: u.Code, ToKg: 12}
+	if err := units.SaveConversion(ctx, uc); err != nil {
+		t.Fatalf("save conversion: %v", err)
+	}
+	list, err := units.ListConversions(ctx)
+	if err != nil {
+		t.Fatalf("list conversions: %v", err)
+	}
+	var conv *models.UnitConversion
+	for _, c := range list {
+		if c.Unit == u.Code {
+			conv = c
+		}
+	}
+	if conv == nil {
+		t.Fatalf("conversion for %s missing", u.Code)
+	}
+	checkTimes(t, conv.CreatedAt, uc.CreatedAt, conv.UpdatedAt, uc.UpdatedAt)
+}
+
+func gradeRoundTrip(t *testing.T, b *backend) {
+	ctx := t.Context()
+	grades := repository.NewGradeRepository(b.db)
+	g := &models.Grade{ID: uuid.New().String(), CropType: "rt-" + b.run, Kind: models.GradeKindGrade, Code: "A", Name: "Grade A"}
+	if err := grades.Create(ctx, g); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := grades.Update(ctx, g); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := grades.GetByID(ctx, g.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, g.CreatedAt, got.UpdatedAt, g.UpdatedAt)
+	if got.MinValue != nil || got.MaxValue != nil {
+		t.Errorf("range = %v..%v, want none", got.MinValue, got.MaxValue)
+	}
+}
+
+func priceRoundTrip(t *testing.T, b *backend) {
+	ctx := t.Context()
+	prices := repository.NewPriceRepository(b.db)
+	from := time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Millisecond)
+	to := from.Add(48*time.Hour + 123*time.Millisecond)
+	p := &models.Price{
+		ID: uuid.New().String(), CropType: "rt-" + b.run, PricePerUnit: 42, Unit: "kg",
+		EffectiveFrom: from, EffectiveTo: &to,
+	}
+	if err := prices.Create(ctx, p); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := prices.Update(ctx, p); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	checkStored(t, b, "prices", "effective_from", p.ID)
+	got, err := prices.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, p.CreatedAt, got.UpdatedAt, p.UpdatedAt)
+	if !sameTime(got.EffectiveFrom, from) {
+		t.Errorf("effective_from = %v, want %v", got.EffectiveFrom, from)
+	}
+	if got.EffectiveTo == nil || !sameTime(*got.EffectiveTo, to) {
+		t.Errorf("effective_to = %v, want %v", got.EffectiveTo, to)
+	}
+
+	// The window is [effective_from, effective_to).
+	if _, err := prices.FindEffective(ctx, p.CropType, "", "", from); err != nil {
+		t.Errorf("not in effect at effective_from: %v", err)
+	}
+	if _, err := prices.FindEffective(ctx, p.CropType, "", "", to); err == nil {
+		t.Errorf("still in effect at effective_to")
+	}
+}
+
+func farmRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	farms := repository.NewFarmRepository(b.db)
+	f := &models.Farm{
+		ID: uuid.New().String(), FarmerID: fx.farmer.ID, Name: "Round Trip Farm", AreaAcres: 2,
+		Plots: []*models.Plot{{ID: uuid.New().String(), Name: "Lower", CropType: "tea", StockCount: 100, StockUnit: "bushes"}},
+	}
+	if err := farms.Create(ctx, f); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, err := farms.GetByID(ctx, f.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, f.CreatedAt, got.UpdatedAt, f.UpdatedAt)
+	if got.DeletedAt != nil {
+		t.Errorf("deleted_at = %v, want none", got.DeletedAt)
+	}
+	if len(got.Plots) != 1 {
+		t.Fatalf("%d plots, want 1", len(got.Plots))
+	}
+	checkTimes(t, got.Plots[0].CreatedAt, f.Plots[0].CreatedAt, got.Plots[0].UpdatedAt, f.Plots[0].UpdatedAt)
+
+	before := time.Now().UTC().Truncate(time.Millisecond)
+	if err := farms.Delete(ctx, f.ID, got.Version); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	after := time.Now().UTC()
+	list, err := farms.List(ctx, fx.farmer.ID, f.CreatedAt)
+	if err != nil {
+		t.Fatalf("list changed since: %v", err)
+	}
+	for _, d := range list {
+		if d.ID != f.ID {
+			continue
+		}
+		if d.DeletedAt == nil || !within(*d.DeletedAt, before, after) {
+			t.Errorf("deleted_at = %v, want between %v and %v", d.DeletedAt, before, after)
+		} else if !sameTime(d.UpdatedAt, *d.DeletedAt) {
+			t.Errorf("updated_at = %v, want deleted_at %v", d.UpdatedAt, d.DeletedAt)
+		}
+		return
+	}
+	t.Errorf("deleted farm missing from changes since %v", f.CreatedAt)
+}
+
+func lotRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	lots := repository.NewLotRepository(b.db)
+	l := &models.Lot{
+		ID: uuid.New().String(), Code: "rt-" + b.run, CenterID: fx.center.ID, CropType: fx.collection.CropType,
+		CreatedBy: fx.collector.ID, CollectionIDs: []string{fx.collection.ID},
+	}
+	if err := lots.Create(ctx, l); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, err := lots.GetByID(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, l.CreatedAt, got.UpdatedAt, l.UpdatedAt)
+
+	tr := &models.LotTransfer{
+		ID: uuid.New().String(), LotID: l.ID, FromName: fx.center.Name, ToKind: "factory", ToName: "Round Trip Factory",
+		WeightOutKg: fx.collection.WeightKg, DispatchedBy: fx.collector.ID,
+	}
+	if err := lots.Dispatch(ctx, tr); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	got, err = lots.GetByID(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("get after dispatch: %v", err)
+	}
+	if len(got.Transfers) != 1 {
+		t.Fatalf("%d transfers, want 1", len(got.Transfers))
+	}
+	if !sameTime(got.Transfers[0].DispatchedAt, tr.DispatchedAt) {
+		t.Errorf("transfer dispatched_at = %v, want %v", got.Transfers[0].DispatchedAt, tr.DispatchedAt)
+	}
+	if got.Transfers[0].ReceivedAt != nil {
+		t.Errorf("transfer received_at = %v before it was received", got.Transfers[0].ReceivedAt)
+	}
+	if !sameTime(got.UpdatedAt, tr.DispatchedAt) {
+		t.Errorf("updated_at = %v, want %v", got.UpdatedAt, tr.DispatchedAt)
+	}
+
+	before := time.Now().UTC().Truncate(time.Millisecond)
+	received, err := lots.Receive(ctx, l.ID, tr.ID, fx.collection.WeightKg-0.5, fx.collector.ID)
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if received.ReceivedAt == nil || !within(*received.ReceivedAt, before, time.Now().UTC()) {
+		t.Errorf("transfer received_at = %v, want about %v", received.ReceivedAt, before)
+	}
+}
+
+func scaleRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	scales := repository.NewScaleRepository(b.db)
+	sc := &models.Scale{ID: uuid.New().String(), Serial: "rt-" + b.run, Name: "Round Trip Scale", CenterID: fx.center.ID, Secret: "secret", Active: true}
+	if err := scales.Create(ctx, sc); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := scales.Update(ctx, sc); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := scales.GetByID(ctx, sc.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, sc.CreatedAt, got.UpdatedAt, sc.UpdatedAt)
+
+	rd := &models.ScaleReading{
+		ID: uuid.New().String(), ScaleID: sc.ID, Sequence: 1, GrossKg: 10, TareKg: 1, NetKg: 9,
+		MeasuredAt: time.Now().UTC().Add(-time.Second).Truncate(time.Millisecond), Signature: "sig", SubmittedBy: fx.collector.ID,
+	}
+	if err := scales.RecordReading(ctx, rd); err != nil {
+		t.Fatalf("record reading: %v", err)
+	}
+	reading, err := scales.GetReading(ctx, rd.ID)
+	if err != nil {
+		t.Fatalf("get reading: %v", err)
+	}
+	if !sameTime(reading.MeasuredAt, rd.MeasuredAt) {
+		t.Errorf("reading measured_at = %v, want %v", reading.MeasuredAt, rd.MeasuredAt)
+	}
+	if !sameTime(reading.CreatedAt, rd.CreatedAt) {
+		t.Errorf("reading created_at = %v, want %v", reading.CreatedAt, rd.CreatedAt)
+	}
+}
+
+func attachmentRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	attachments := repository.NewAttachmentRepository(b.db)
+	a := &models.Attachment{
+		ID: uuid.New().String(), OwnerType: "collection", OwnerID: fx.collection.ID, SHA256: "rt-" + b.run,
+		SizeBytes: 3, MimeType: "image/png", Filename: "receipt.png", UploadedBy: fx.collector.ID,
+	}
+	if err := attachments.Create(ctx, a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, err := attachments.GetByID(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !sameTime(got.CreatedAt, a.CreatedAt) {
+		t.Errorf("created_at = %v, want %v", got.CreatedAt, a.CreatedAt)
+	}
+
+	u := &models.UploadSession{
+		ID: uuid.New().String(), OwnerType: "collection", OwnerID: fx.collection.ID, Filename: "scan.pdf", SizeBytes: 10, CreatedBy: fx.collector.ID,
+	}
+	if err := attachments.CreateUpload(ctx, u); err != nil {
+		t.Fatalf("create upload: %v", err)
+	}
+	upload, err := attachments.GetUpload(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("get upload: %v", err)
+	}
+	if !sameTime(upload.CreatedAt, u.CreatedAt) {
+		t.Errorf("upload created_at = %v, want %v", upload.CreatedAt, u.CreatedAt)
+	}
+	if err := attachments.DeleteUpload(ctx, u.ID); err != nil {
+		t.Errorf("delete upload: %v", err)
+	}
+}
+
+func disputeRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	disputes := repository.NewDisputeRepository(b.db)
+	notifications := repository.NewNotificationRepository(b.db)
+
+	d := &models.Dispute{ID: uuid.New().String(), CollectionID: fx.collection.ID, FarmerID: fx.farmer.ID, CollectorID: fx.collector.ID, Reason: "short weight"}
+	opened := &models.Notification{
+		ID: uuid.New().String(), UserID: fx.collector.ID, Kind: models.NotifyDisputeOpened,
+		SubjectType: "dispute", SubjectID: d.ID, Message: "A dispute was opened",
+	}
+	if err := disputes.Create(ctx, d, []*models.Notification{opened}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, err := disputes.GetByID(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	checkTimes(t, got.CreatedAt, d.CreatedAt, got.UpdatedAt, d.UpdatedAt)
+	if got.ResolvedAt != nil {
+		t.Errorf("open dispute resolved_at = %v", got.ResolvedAt)
+	}
+
+	list, err := notifications.ListByUser(ctx, fx.collector.ID, false)
+	if err != nil {
+		t.Fatalf("list notifications: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("%d notifications, want 1", len(list))
+	}
+	if !sameTime(list[0].CreatedAt, opened.CreatedAt) {
+		t.Errorf("notification created_at = %v, want %v", list[0].CreatedAt, opened.CreatedAt)
+	}
+	if list[0].ReadAt != nil {
+		t.Errorf("unread notification read_at = %v", list[0].ReadAt)
+	}
+	before := time.Now().UTC().Truncate(time.Millisecond)
+	if err := notifications.MarkRead(ctx, opened.ID, fx.collector.ID); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	list, err = notifications.ListByUser(ctx, fx.collector.ID, false)
+	if err != nil {
+		t.Fatalf("list notifications after read: %v", err)
+	}
+	if len(list) != 1 || list[0].ReadAt == nil || !within(*list[0].ReadAt, before, time.Now().UTC()) {
+		t.Errorf("notification read_at wrong after mark read, want about %v", before)
+	}
+
+	adj := &models.Adjustment{
+		ID: uuid.New().String(), CollectionID: fx.collection.ID, Kind: models.AdjustmentDispute,
+		PreviousWeightKg: fx.collection.WeightKg, NewWeightKg: fx.collection.WeightKg + 1,
+		PreviousPricePerKg: fx.collection.PricePerKg, NewPricePerKg: fx.collection.PricePerKg,
+		Amount: fx.collection.PricePerKg, Reason: "reweighed", CreatedBy: "admin",
+	}
+	collection, err := b.stores.Collections.GetByID(ctx, fx.collection.ID)
+	if err != nil {
+		t.Fatalf("get collection: %v", err)
+	}
+	d.Status, d.Resolution, d.ResolvedBy = models.DisputeUpheld, "reweighed", "admin"
+	if err := disputes.Resolve(ctx, d, adj, collection.Version, nil); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	got, err = disputes.GetByID(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("get after resolve: %v", err)
+	}
+	if got.ResolvedAt == nil || d.ResolvedAt == nil || !sameTime(*got.ResolvedAt, *d.ResolvedAt) {
+		t.Errorf("resolved_at = %v, want %v", got.ResolvedAt, d.ResolvedAt)
+	}
+	checkTimes(t, got.CreatedAt, d.CreatedAt, got.UpdatedAt, d.UpdatedAt)
+
+	adjustments, err := repository.NewAdjustmentRepository(b.db).ListByCollection(ctx, fx.collection.ID)
+	if err != nil {
+		t.Fatalf("list adjustments: %v", err)
+	}
+	if len(adjustments) != 1 {
+		t.Fatalf("%d adjustments, want 1", len(adjustments))
+	}
+	if !sameTime(adjustments[0].CreatedAt, adj.CreatedAt) {
+		t.Errorf("adjustment created_at = %v, want %v", adjustments[0].CreatedAt, adj.CreatedAt)
+	}
+}
+
+func auditRoundTrip(t *testing.T, b *backend, fx *roundTripFixtures) {
+	ctx := t.Context()
+	audit := repository.NewAuditRepository(b.db)
+	e := &models.AuditEntry{ActorID: "roundtrip", ActorRole: "admin", Action: "update", EntityType: "collection", EntityID: fx.collection.ID}
+	if err := audit.Append(ctx, e); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	list, err := audit.List(ctx, repository.AuditFilter{EntityID: fx.collection.ID, From: e.At, To: e.At.Add(time.Millisecond), Limit: 10})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("%d entries at %v, want 1", len(list), e.At)
+	}
+	if !sameTime(list[0].At, e.At) {
+		t.Errorf("at = %v, want %v", list[0].At, e.At)
+	}
+	if list[0].Hash != e.Hash {
+		t.Errorf("hash = %s, want %s", list[0].Hash, e.Hash)
+	}
+	if list[0].Before != nil {
+		t.Errorf("before = %s, want none", list[0].Before)
+	}
+	v, err := audit.Verify(ctx)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !v.Valid {
+		t.Errorf("chain broken at %d: %s", v.BrokenAt, v.Problem)
+	}
+}
+
+// checkTimes checks a created_at / updated_at pair read back.
+func checkTimes(t *testing.T, gotCreated, wantCreated, gotUpdated, wantUpdated time.Time) {
+	t.Helper()
+	if !sameTime(gotCreated, wantCreated) {
+		t.Errorf("created_at = %v, want %v", gotCreated, wantCreated)
+	}
+	if !sameTime(gotUpdated, wantUpdated) {
+		t.Errorf("updated_at = %v, want %v", gotUpdated, wantUpdated)
+	}
+}
+
+// checkStored checks the raw value of a timestamp column.
+func checkStored(t *testing.T, b *backend, table, column, id string) {
+	t.Helper()
+	var v any
+	if err := b.db.QueryRowContext(t.Context(), `SELECT `+column+` FROM `+table+` WHERE id = ?`, id).Scan(&v); err != nil {
+		t.Errorf("read %s.%s: %v", table, column, err)
+		return
+	}
+	var text string
+	switch v := v.(type) {
+	case time.Time:
+		return
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		text = fmt.Sprintf("%T", v)
+	}
+	if !storedLayout.MatchString(text) {
+		t.Errorf("%s.%s stored as %q", table, column, text)
+	}
+}
+
+func within(t, from, to time.Time) bool {
+	return !t.Before(from) && !t.After(to)
+}
+
+func hasCropType(list []*models.CropType, code string) bool {
+	for _, ct := range list {
+		if ct.Code == code {
+			return true
+		}
+	}
+	return false
+}
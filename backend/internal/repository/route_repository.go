@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"agri-sync-backend/internal/models"
+)
+
+var ErrRouteNotFound = errors.New("route not found")
+
+type RouteRepository struct {
+	db DBTX
+}
+
+func NewRouteRepository(db DBTX) *RouteRepository {
+	return &RouteRepository{db: db}
+}
+
+const routeColumns = `id, code, name, collector_id, days, active, version, created_at, updated_at`
+
+// CREATE (route and its stops in one transaction)
+func (r *RouteRepository) Create(ctx context.Context, rt *models.Route) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	rt.CreatedAt = now
+	rt.UpdatedAt = now
+	rt.Version = 1
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO routes (`+routeColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rt.ID, rt.Code, rt.Name, nullableString(rt.CollectorID), strings.Join(rt.Days, ","), rt.Active,
+		rt.Version, formatTime(rt.CreatedAt), formatTime(rt.UpdatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	if err := insertStops(ctx, tx, rt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// READ (with stops)
+func (r *RouteRepository) GetByID(ctx context.Context, id string) (*models.Route, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+routeColumns+` FROM routes WHERE id = ?`, id)
+	rt, err := scanRoute(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRouteNotFound
+		}
+		return nil, err
+	}
+	if rt.Stops, err = r.listStops(ctx, rt.ID); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// UPDATE replaces the route's fields and its full list of stops.
+func (r *RouteRepository) Update(ctx context.Context, rt *models.Route) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rt.Version++
+	rt.UpdatedAt = timeNow()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE routes
+		SET code = ?, name = ?, collector_id = ?, days = ?, active = ?, version = ?, updated_at = ?
+		WHERE id = ?`,
+		rt.Code, rt.Name, nullableString(rt.CollectorID), strings.Join(rt.Days, ","), rt.Active,
+		rt.Version, formatTime(rt.UpdatedAt), rt.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRouteNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM route_stops WHERE route_id = ?`, rt.ID); err != nil {
+		return err
+	}
+	if err := insertStops(ctx, tx, rt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// List returns all routes, or only those assigned to collectorID when it is
+// non-empty. Stops are included.
+func (r *RouteRepository) List(ctx context.Context, collectorID string) ([]*models.Route, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+routeColumns+` FROM routes
+		WHERE ? = '' OR collector_id = ?
+		ORDER BY code`, collectorID, collectorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*models.Route
+	for rows.Next() {
+		rt, err := scanRoute(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, rt)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, rt := range list {
+		if rt.Stops, err = r.listStops(ctx, rt.ID); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// ExpectedFarmers returns the farmers assigned to a stop.
+func (r *RouteRepository) ExpectedFarmers(ctx context.Context, stopID string) ([]*models.ExpectedFarmer, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT f.id, f.name, f.phone
+		FROM route_stop_farmers sf
+		JOIN farmers f ON f.id = sf.farmer_id
+		WHERE sf.stop_id = ?
+		ORDER BY f.name`, stopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.ExpectedFarmer
+	for rows.Next() {
+		var f models.ExpectedFarmer
+		if err := rows.Scan(&f.ID, &f.Name, &f.Phone); err != nil {
+			return nil, err
+		}
+		list = append(list, &f)
+	}
+	return list, rows.Err()
+}
+
+func (r *RouteRepository) listStops(ctx context.Context, routeID string) ([]*models.RouteStop, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, route_id, center_id, position, planned_time
+		FROM route_stops
+		WHERE route_id = ?
+		ORDER BY position`, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stops := []*models.RouteStop{}
+	byID := map[string]*models.RouteStop{}
+	for rows.Next() {
+		s := models.RouteStop{FarmerIDs: []string{}}
+		if err := rows.Scan(&s.ID, &s.RouteID, &s.CenterID, &s.Position, &s.PlannedTime); err != nil {
+			return nil, err
+		}
+		stops = append(stops, &s)
+		byID[s.ID] = &s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	farmerRows, err := r.db.QueryContext(ctx, `
+		SELECT sf.stop_id, sf.farmer_id
+		FROM route_stop_farmers sf
+		JOIN route_stops s ON s.id = sf.stop_id
+		WHERE s.route_id = ?
+		ORDER BY sf.stop_id, sf.farmer_id`, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer farmerRows.Close()
+
+	for farmerRows.Next() {
+		var stopID, farmerID string
+		if err := farmerRows.Scan(&stopID, &farmerID); err != nil {
+			return nil, err
+		}
+		if s := byID[stopID]; s != nil {
+			s.FarmerIDs = append(s.FarmerIDs, farmerID)
+		}
+	}
+	return stops, farmerRows.Err()
+}
+
+func insertStops(ctx context.Context, tx DBTX, rt *models.Route) error {
+	for _, s := range rt.Stops {
+		s.RouteID = rt.ID
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO route_stops (id, route_id, center_id, position, planned_time)
+			VALUES (?, ?, ?, ?, ?)`,
+			s.ID, s.RouteID, s.CenterID, s.Position, s.PlannedTime,
+		)
+		if err != nil {
+			return err
+		}
+		for _, farmerID := range s.FarmerIDs {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO route_stop_farmers (stop_id, farmer_id) VALUES (?, ?)
+				ON CONFLICT DO NOTHING`,
+				s.ID, farmerID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func scanRoute(s rowScanner) (*models.Route, error) {
+	var rt models.Route
+	var collectorID sql.NullString
+	var days string
+
+	err := s.Scan(&rt.ID, &rt.Code, &rt.Name, &collectorID, &days, &rt.Active,
+		&rt.Version, timeColumn{&rt.CreatedAt}, timeColumn{&rt.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	rt.CollectorID = collectorID.String
+	rt.Days = splitList(days)
+	return &rt, nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"agri-sync-backend/internal/models"
+)
+
+var (
+	ErrScaleNotFound   = errors.New("scale not found")
+	ErrReadingNotFound = errors.New("scale reading not found")
+	ErrReadingUsed     = errors.New("scale reading already backs a collection")
+	ErrStaleSequence   = errors.New("reading sequence already used")
+)
+
+type ScaleRepository struct {
+	db DBTX
+}
+
+func NewScaleRepository(db DBTX) *ScaleRepository {
+	return &ScaleRepository{db: db}
+}
+
+const scaleColumns = `id, serial, name, center_id, secret, active, last_sequence, version, created_at, updated_at`
+
+const readingColumns = `id, scale_id, sequence, gross_kg, tare_kg, net_kg, measured_at, signature, submitted_by, created_at`
+
+// CREATE
+func (r *ScaleRepository) Create(ctx context.Context, s *models.Scale) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	s.Version = 1
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO scales (`+scaleColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?)`,
+		s.ID, s.Serial, s.Name, nullableString(s.CenterID), s.Secret, s.Active,
+		s.Version, formatTime(s.CreatedAt), formatTime(s.UpdatedAt),
+	)
+	return err
+}
+
+// READ
+func (r *ScaleRepository) GetByID(ctx context.Context, id string) (*models.Scale, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+scaleColumns+` FROM scales WHERE id = ?`, id)
+	s, err := scanScale(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrScaleNotFound
+	}
+	return s, err
+}
+
+// UPDATE (the secret and sequence are not editable)
+func (r *ScaleRepository) Update(ctx context.Context, s *models.Scale) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	s.Version++
+	s.UpdatedAt = timeNow()
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE scales
+		SET serial = ?, name = ?, center_id = ?, active = ?, version = ?, updated_at = ?
+		WHERE id = ?`,
+		s.Serial, s.Name, nullableString(s.CenterID), s.Active,
+		s.Version, formatTime(s.UpdatedAt), s.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrScaleNotFound
+	}
+	return nil
+}
+
+func (r *ScaleRepository) List(ctx context.Context) ([]*models.Scale, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+scaleColumns+` FROM scales ORDER BY serial`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Scale
+	for rows.Next() {
+		s, err := scanScale(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// RecordReading stores a verified reading. Sequence numbers must increase
+// per scale, so a captured payload cannot be replayed.
+func (r *ScaleRepository) RecordReading(ctx context.Context, rd *models.ScaleReading) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rd.CreatedAt = timeNow()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE scales SET last_sequence = ?
+		WHERE id = ? AND last_sequence < ?`,
+		rd.Sequence, rd.ScaleID, rd.Sequence)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrStaleSequence
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO scale_readings (`+readingColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rd.ID, rd.ScaleID, rd.Sequence, rd.GrossKg, rd.TareKg, rd.NetKg,
+		formatTime(rd.MeasuredAt), rd.Signature, rd.SubmittedBy,
+		formatTime(rd.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetReading returns a reading and the collection that used it, if any.
+func (r *ScaleRepository) GetReading(ctx context.Context, id string) (*models.ScaleReading, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+prefixColumns("r", readingColumns)+`, COALESCE(c.id, '')
+		FROM scale_readings r
+		LEFT JOIN collections c ON c.scale_reading_id = r.id
+		WHERE r.id = ?`, id)
+
+	var rd models.ScaleReading
+	err := row.Scan(&rd.ID, &rd.ScaleID, &rd.Sequence, &rd.GrossKg, &rd.TareKg, &rd.NetKg,
+		timeColumn{&rd.MeasuredAt}, &rd.Signature, &rd.SubmittedBy, timeColumn{&rd.CreatedAt}, &rd.CollectionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReadingNotFound
+		}
+		return nil, err
+	}
+
+	return &rd, nil
+}
+
+func scanScale(s rowScanner) (*models.Scale, error) {
+	var sc models.Scale
+	var centerID sql.NullString
+
+	err := s.Scan(&sc.ID, &sc.Serial, &sc.Name, &centerID, &sc.Secret, &sc.Active, &sc.LastSequence,
+		&sc.Version, timeColumn{&sc.CreatedAt}, timeColumn{&sc.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	sc.CenterID = centerID.String
+	return &sc, nil
+}
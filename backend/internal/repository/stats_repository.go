@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"agri-sync-backend/internal/models"
+)
+
+// StatsRepository answers the whole-business questions behind /metrics.
+type StatsRepository struct {
+	db DBTX
+}
+
+func NewStatsRepository(db DBTX) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// PendingPayout is what farmers are owed and not yet paid: pending and
+// verified collections plus the adjustments on them. An adjustment is
+// settled with its collection's payout, so those on paid collections went
+// out with the payment and those on rejected ones are not owed. The farmer
+// wallet counts adjustments the same way.
+func (r *StatsRepository) PendingPayout(ctx context.Context) (float64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var total float64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COALESCE(SUM(weight_kg * price_per_kg), 0) FROM collections WHERE status IN (?1, ?2))
+			+ (SELECT COALESCE(SUM(a.amount), 0) FROM collection_adjustments a
+				JOIN collections c ON c.id = a.collection_id
+				WHERE c.status IN (?1, ?2))`,
+		models.StatusPending, models.StatusVerified).Scan(&total)
+	return total, err
+}
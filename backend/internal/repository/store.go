@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/models"
+)
+
+var (
+	ErrFarmerNotFound     = errors.New("farmer not found")
+	ErrCollectorNotFound  = errors.New("collector not found")
+	ErrCollectionNotFound = errors.New("collection not found")
+)
+
+// FarmerStore persists farmers. GetByID, GetByPhone and SetLocation return
+// ErrFarmerNotFound when no farmer matches.
+type FarmerStore interface {
+	Create(ctx context.Context, f *models.Farmer) error
+	GetByID(ctx context.Context, id string) (*models.Farmer, error)
+	GetByPhone(ctx context.Context, phone string) (*models.Farmer, error)
+	Update(ctx context.Context, f *models.Farmer) error
+	SetLocation(ctx context.Context, id string, lat, lng float64) error
+	Delete(ctx context.Context, id string) error
+}
+
+// CollectorStore persists collectors. GetByID and GetByPhone return
+// ErrCollectorNotFound when no collector matches.
+type CollectorStore interface {
+	Create(ctx context.Context, c *models.Collector) error
+	GetByID(ctx context.Context, id string) (*models.Collector, error)
+	GetByPhone(ctx context.Context, phone string) (*models.Collector, error)
+	Update(ctx context.Context, c *models.Collector) error
+	Delete(ctx context.Context, id string) error
+}
+
+// CollectionStore persists collections. GetByID returns
+// ErrCollectionNotFound for an unknown id and UpdateWithVersion returns
+// ErrConflict when the version is stale.
+type CollectionStore interface {
+	Create(ctx context.Context, c *models.Collection) error
+	GetByID(ctx context.Context, id string) (*models.Collection, error)
+	Update(ctx context.Context, c *models.Collection) error
+	Delete(ctx context.Context, id string) error
+
+	List(ctx context.Context, f CollectionFilter) ([]*models.Collection, error)
+	ListByFarmer(ctx context.Context, farmerID string) ([]*models.Collection, error)
+	ListAll(ctx context.Context) ([]*models.Collection, error)
+
+	UpdateStatus(ctx context.Context, id string, status models.TransactionStatus) error
+	UpdateWithVersion(ctx context.Context, c *models.Collection) error
+
+	TotalsByCrop(ctx context.Context, from, to time.Time) ([]*models.CropTotal, error)
+	TotalsByCenter(ctx context.Context, from, to time.Time) ([]*models.CenterTotal, error)
+}
+
+// Stores are the implementations for one database driver.
+type Stores struct {
+	Farmers     FarmerStore
+	Collectors  CollectorStore
+	Collections CollectionStore
+
+	driver string
+}
+
+// NewStores returns the stores for the driver the database was opened
+// with (config.DriverSQLite or config.DriverPostgres).
+func NewStores(db DBTX, driver string) Stores {
+	if driver == config.DriverPostgres {
+		return Stores{
+			Farmers:     NewPostgresFarmerRepository(db),
+			Collectors:  NewPostgresCollectorRepository(db),
+			Collections: NewPostgresCollectionRepository(db),
+			driver:      driver,
+		}
+	}
+	return Stores{
+		Farmers:     NewFarmerRepository(db),
+		Collectors:  NewCollectorRepository(db),
+		Collections: NewCollectionRepository(db),
+		driver:      driver,
+	}
+}
+
+// With returns the same stores running on db, typically the transaction
+// of a WithTx call.
+func (s Stores) With(db DBTX) Stores {
+	return NewStores(db, s.driver)
+}
@@ -0,0 +1,366 @@
+package repository_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"agri-sync-backend/internal/geo"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// The store tests check that every implementation of the repository stores
+// behaves the same way; a new implementation is done when they pass
+// unchanged.
+
+func TestFarmerStore(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := t.Context()
+		store := b.stores.Farmers
+		f := &models.Farmer{ID: uuid.New().String(), Name: "Store Farmer", Phone: "+2547" + b.run + "1", PasswordHash: "hash"}
+		if err := store.Create(ctx, f); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if f.Version != 1 {
+			t.Errorf("create: version = %d, want 1", f.Version)
+		}
+
+		got, err := store.GetByID(ctx, f.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Name != f.Name || got.Phone != f.Phone || got.PasswordHash != f.PasswordHash {
+			t.Errorf("get: got %+v, want %+v", got, f)
+		}
+		if got.Version != 1 {
+			t.Errorf("get: version = %d, want 1", got.Version)
+		}
+		checkTimes(t, got.CreatedAt, f.CreatedAt, got.UpdatedAt, f.UpdatedAt)
+		if got.Latitude != nil {
+			t.Errorf("get: unexpected location %v", *got.Latitude)
+		}
+
+		byPhone, err := store.GetByPhone(ctx, f.Phone)
+		if err != nil {
+			t.Errorf("get by phone: %v", err)
+		} else if byPhone.ID != f.ID {
+			t.Errorf("get by phone: id = %s, want %s", byPhone.ID, f.ID)
+		}
+		if _, err := store.GetByPhone(ctx, "+000"+b.run); !errors.Is(err, repository.ErrFarmerNotFound) {
+			t.Errorf("get by unknown phone: err = %v, want %v", err, repository.ErrFarmerNotFound)
+		}
+		if _, err := store.GetByID(ctx, uuid.New().String()); !errors.Is(err, repository.ErrFarmerNotFound) {
+			t.Errorf("get unknown id: err = %v, want %v", err, repository.ErrFarmerNotFound)
+		}
+
+		f.Name = "Renamed Farmer"
+		if err := store.Update(ctx, f); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if got, err := store.GetByID(ctx, f.ID); err != nil {
+			t.Errorf("get after update: %v", err)
+		} else if got.Name != "Renamed Farmer" || got.Version != 2 {
+			t.Errorf("update: name %q version %d, want %q version 2", got.Name, got.Version, "Renamed Farmer")
+		}
+
+		if err := store.SetLocation(ctx, f.ID, -0.5, 36.25); err != nil {
+			t.Fatalf("set location: %v", err)
+		}
+		if got, err := store.GetByID(ctx, f.ID); err != nil {
+			t.Errorf("get after set location: %v", err)
+		} else {
+			if got.Latitude == nil || *got.Latitude != -0.5 || got.Longitude == nil || *got.Longitude != 36.25 {
+				t.Errorf("set location: got %v,%v, want -0.5,36.25", got.Latitude, got.Longitude)
+			}
+			if got.Version != 3 {
+				t.Errorf("set location: version = %d, want 3", got.Version)
+			}
+		}
+		if byPhone, err := store.GetByPhone(ctx, f.Phone); err != nil {
+			t.Errorf("get by phone after set location: %v", err)
+		} else if byPhone.Latitude == nil {
+			t.Errorf("get by phone: location missing")
+		}
+		if err := store.SetLocation(ctx, uuid.New().String(), 0, 0); !errors.Is(err, repository.ErrFarmerNotFound) {
+			t.Errorf("set location on unknown id: err = %v, want %v", err, repository.ErrFarmerNotFound)
+		}
+
+		if err := store.Delete(ctx, f.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := store.GetByID(ctx, f.ID); !errors.Is(err, repository.ErrFarmerNotFound) {
+			t.Errorf("get after delete: err = %v, want %v", err, repository.ErrFarmerNotFound)
+		}
+	})
+}
+
+func TestCollectorStore(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := t.Context()
+		store := b.stores.Collectors
+		c := &models.Collector{ID: uuid.New().String(), Name: "Store Collector", Phone: "+2547" + b.run + "2", PasswordHash: "hash"}
+		if err := store.Create(ctx, c); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if c.Version != 1 {
+			t.Errorf("create: version = %d, want 1", c.Version)
+		}
+
+		got, err := store.GetByID(ctx, c.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Name != c.Name || got.Phone != c.Phone || got.PasswordHash != c.PasswordHash || got.Version != 1 {
+			t.Errorf("get: got %+v, want %+v", got, c)
+		}
+		checkTimes(t, got.CreatedAt, c.CreatedAt, got.UpdatedAt, c.UpdatedAt)
+
+		byPhone, err := store.GetByPhone(ctx, c.Phone)
+		if err != nil {
+			t.Errorf("get by phone: %v", err)
+		} else if byPhone.ID != c.ID {
+			t.Errorf("get by phone: id = %s, want %s", byPhone.ID, c.ID)
+		}
+		if _, err := store.GetByPhone(ctx, "+000"+b.run); !errors.Is(err, repository.ErrCollectorNotFound) {
+			t.Errorf("get by unknown phone: err = %v, want %v", err, repository.ErrCollectorNotFound)
+		}
+		if _, err := store.GetByID(ctx, uuid.New().String()); !errors.Is(err, repository.ErrCollectorNotFound) {
+			t.Errorf("get unknown id: err = %v, want %v", err, repository.ErrCollectorNotFound)
+		}
+
+		c.Phone = "+2547" + b.run + "3"
+		if err := store.Update(ctx, c); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if got, err := store.GetByID(ctx, c.ID); err != nil {
+			t.Errorf("get after update: %v", err)
+		} else if got.Phone != c.Phone || got.Version != 2 {
+			t.Errorf("update: phone %q version %d, want %q version 2", got.Phone, got.Version, c.Phone)
+		}
+
+		if err := store.Delete(ctx, c.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := store.GetByID(ctx, c.ID); !errors.Is(err, repository.ErrCollectorNotFound) {
+			t.Errorf("get after delete: err = %v, want %v", err, repository.ErrCollectorNotFound)
+		}
+	})
+}
+
+func TestCollectionStore(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := t.Context()
+		farmer := &models.Farmer{ID: uuid.New().String(), Name: "Delivering Farmer", Phone: "+2547" + b.run + "4"}
+		collector := &models.Collector{ID: uuid.New().String(), Name: "Weighing Collector", Phone: "+2547" + b.run + "5"}
+		center := &models.CollectionCenter{ID: uuid.New().String(), Code: "store-" + b.run, Name: "Store Center", Active: true}
+		if err := b.stores.Farmers.Create(ctx, farmer); err != nil {
+			t.Fatalf("fixture farmer: %v", err)
+		}
+		if err := b.stores.Collectors.Create(ctx, collector); err != nil {
+			t.Fatalf("fixture collector: %v", err)
+		}
+		if err := repository.NewCenterRepository(b.db).Create(ctx, center); err != nil {
+			t.Fatalf("fixture center: %v", err)
+		}
+
+		store := b.stores.Collections
+		tea, coffee := "store-tea-"+b.run, "store-coffee-"+b.run
+		lat, lng, accuracy := -0.4, 36.9, 12.5
+		captured := time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+		from := time.Now().UTC().Add(-time.Minute)
+
+		geotagged := &models.Collection{
+			ID: uuid.New().String(), FarmerID: farmer.ID, CollectorID: collector.ID, CenterID: center.ID,
+			CropType: tea, Quantity: 10, Unit: "kg", PricePerUnit: 20, WeightKg: 10, PricePerKg: 20,
+			Grade: "A", QualityReadings: map[string]float64{"moisture": 11.5},
+			Latitude: &lat, Longitude: &lng, LocationAccuracyM: &accuracy, LocationCapturedAt: &captured,
+			Flags: []string{"outside_geofence"},
+		}
+		plain := &models.Collection{
+			ID: uuid.New().String(), FarmerID: farmer.ID, CollectorID: collector.ID,
+			CropType: coffee, Quantity: 4, Unit: "kg", PricePerUnit: 50, WeightKg: 4, PricePerKg: 50,
+		}
+		rejected := &models.Collection{
+			ID: uuid.New().String(), FarmerID: farmer.ID, CollectorID: collector.ID, CenterID: center.ID,
+			CropType: tea, Quantity: 3, Unit: "kg", WeightKg: 3, PricePerKg: 20,
+			Status: models.StatusRejected, RejectionReason: "wet leaf",
+		}
+		for _, c := range []*models.Collection{geotagged, plain, rejected} {
+			if err := store.Create(ctx, c); err != nil {
+				t.Fatalf("create %s: %v", c.CropType, err)
+			}
+			// Distinct created_at values, so "newest first" is well defined
+			time.Sleep(5 * time.Millisecond)
+		}
+		to := time.Now().UTC().Add(time.Minute)
+
+		got, err := store.GetByID(ctx, geotagged.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.FarmerID != farmer.ID || got.CollectorID != collector.ID || got.CenterID != center.ID ||
+			got.CropType != tea || got.WeightKg != 10 || got.PricePerKg != 20 || got.Grade != "A" {
+			t.Errorf("get: got %+v", got)
+		}
+		if got.Status != models.StatusPending {
+			t.Errorf("get: status = %q, want %q", got.Status, models.StatusPending)
+		}
+		if got.Version != 1 {
+			t.Errorf("get: version = %d, want 1", got.Version)
+		}
+		if !reflect.DeepEqual(got.QualityReadings, geotagged.QualityReadings) {
+			t.Errorf("get: quality readings = %v, want %v", got.QualityReadings, geotagged.QualityReadings)
+		}
+		if !reflect.DeepEqual(got.Flags, []string{"outside_geofence"}) {
+			t.Errorf("get: flags = %v, want [outside_geofence]", got.Flags)
+		}
+		if got.Latitude == nil || *got.Latitude != lat || got.Longitude == nil || *got.Longitude != lng ||
+			got.LocationAccuracyM == nil || *got.LocationAccuracyM != accuracy {
+			t.Errorf("get: location = %v,%v ±%v", got.Latitude, got.Longitude, got.LocationAccuracyM)
+		}
+		if got.LocationCapturedAt == nil || !got.LocationCapturedAt.Equal(captured) {
+			t.Errorf("get: location_captured_at = %v, want %v", got.LocationCapturedAt, captured)
+		}
+		if !sameTime(got.CreatedAt, geotagged.CreatedAt) {
+			t.Errorf("get: created_at = %v, want %v", got.CreatedAt, geotagged.CreatedAt)
+		}
+
+		if got, err := store.GetByID(ctx, plain.ID); err != nil {
+			t.Errorf("get without extras: %v", err)
+		} else {
+			if got.Flags == nil || len(got.Flags) != 0 {
+				t.Errorf("get without extras: flags = %#v, want empty", got.Flags)
+			}
+			if got.CenterID != "" || got.Latitude != nil || got.LocationCapturedAt != nil {
+				t.Errorf("get without extras: unexpected center or location in %+v", got)
+			}
+		}
+		if _, err := store.GetByID(ctx, uuid.New().String()); !errors.Is(err, repository.ErrCollectionNotFound) {
+			t.Errorf("get unknown id: err = %v, want %v", err, repository.ErrCollectionNotFound)
+		}
+
+		if list, err := store.ListByFarmer(ctx, farmer.ID); err != nil {
+			t.Errorf("list by farmer: %v", err)
+		} else if want := ids(rejected, plain, geotagged); ids(list...) != want {
+			t.Errorf("list by farmer: got %s, want newest first %s", ids(list...), want)
+		}
+		if list, err := store.List(ctx, repository.CollectionFilter{FarmerID: farmer.ID, Flagged: true}); err != nil {
+			t.Errorf("list flagged: %v", err)
+		} else if ids(list...) != geotagged.ID {
+			t.Errorf("list flagged: got %s, want %s", ids(list...), geotagged.ID)
+		}
+		box := &geo.BoundingBox{MinLat: lat - 0.01, MaxLat: lat + 0.01, MinLng: lng - 0.01, MaxLng: lng + 0.01}
+		if list, err := store.List(ctx, repository.CollectionFilter{FarmerID: farmer.ID, Within: box}); err != nil {
+			t.Errorf("list within: %v", err)
+		} else if ids(list...) != geotagged.ID {
+			t.Errorf("list within: got %s, want %s", ids(list...), geotagged.ID)
+		}
+
+		if err := store.UpdateStatus(ctx, plain.ID, models.StatusVerified); err != nil {
+			t.Errorf("update status: %v", err)
+		} else if got, err := store.GetByID(ctx, plain.ID); err != nil {
+			t.Errorf("get after update status: %v", err)
+		} else {
+			if got.Status != models.StatusVerified {
+				t.Errorf("update status: status = %q, want %q", got.Status, models.StatusVerified)
+			}
+			if got.UpdatedAt.Before(got.CreatedAt) {
+				t.Errorf("update status: updated_at %v before created_at %v", got.UpdatedAt, got.CreatedAt)
+			}
+		}
+
+		stale := *geotagged
+		stale.Version = 7
+		stale.Status = models.StatusVerified
+		if err := store.UpdateWithVersion(ctx, &stale); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("update with stale version: err = %v, want %v", err, repository.ErrConflict)
+		}
+		current := *geotagged
+		current.Status = models.StatusVerified
+		if err := store.UpdateWithVersion(ctx, &current); err != nil {
+			t.Errorf("update with version: %v", err)
+		} else if got, err := store.GetByID(ctx, geotagged.ID); err != nil {
+			t.Errorf("get after versioned update: %v", err)
+		} else if got.Status != models.StatusVerified || got.Version != 2 {
+			t.Errorf("update with version: status %q version %d, want %q version 2", got.Status, got.Version, models.StatusVerified)
+		}
+
+		// Re-recorded as one 5 kg bag: the unit fields change with the kg ones
+		plain.Quantity, plain.Unit, plain.PricePerUnit, plain.WeightKg = 1, "bag", 250, 5
+		if err := store.Update(ctx, plain); err != nil {
+			t.Errorf("update: %v", err)
+		} else if got, err := store.GetByID(ctx, plain.ID); err != nil {
+			t.Errorf("get after update: %v", err)
+		} else if got.Quantity != 1 || got.Unit != "bag" || got.PricePerUnit != 250 || got.WeightKg != 5 || got.PricePerKg != 50 {
+			t.Errorf("update: got %v %s at %v (%v kg at %v), want 1 bag at 250 (5 kg at 50)",
+				got.Quantity, got.Unit, got.PricePerUnit, got.WeightKg, got.PricePerKg)
+		}
+
+		if totals, err := store.TotalsByCrop(ctx, from, to); err != nil {
+			t.Errorf("totals by crop: %v", err)
+		} else {
+			byCrop := map[string]*models.CropTotal{}
+			for _, total := range totals {
+				byCrop[total.CropType] = total
+			}
+			// Rejected deliveries are left out
+			if tt := byCrop[tea]; tt == nil || tt.Count != 1 || tt.TotalQuantity != 10 || math.Abs(tt.TotalValue-200) > 1e-9 {
+				t.Errorf("totals by crop: tea = %+v, want 1 delivery of 10 worth 200", tt)
+			}
+			if ct := byCrop[coffee]; ct == nil || ct.Count != 1 || ct.TotalQuantity != 5 {
+				t.Errorf("totals by crop: coffee = %+v, want 1 delivery of 5", ct)
+			}
+		}
+		if totals, err := store.TotalsByCrop(ctx, to, to.Add(time.Hour)); err != nil {
+			t.Errorf("totals for an empty range: %v", err)
+		} else {
+			for _, total := range totals {
+				if total.CropType == tea || total.CropType == coffee {
+					t.Errorf("totals outside range: %+v", total)
+				}
+			}
+		}
+		if totals, err := store.TotalsByCenter(ctx, from, to); err != nil {
+			t.Errorf("totals by center: %v", err)
+		} else {
+			var atCenter, noCenter *models.CenterTotal
+			for _, total := range totals {
+				switch {
+				case total.CenterID == center.ID && total.CropType == tea:
+					atCenter = total
+				case total.CenterID == "" && total.CropType == coffee:
+					noCenter = total
+				}
+			}
+			if atCenter == nil || atCenter.Count != 1 || atCenter.TotalQuantity != 10 {
+				t.Errorf("totals by center: center = %+v, want 1 delivery of 10", atCenter)
+			}
+			if noCenter == nil || noCenter.Count != 1 {
+				t.Errorf("totals by center: no center = %+v, want 1 delivery", noCenter)
+			}
+		}
+
+		for _, c := range []*models.Collection{geotagged, plain, rejected} {
+			if err := store.Delete(ctx, c.ID); err != nil {
+				t.Errorf("delete: %v", err)
+			}
+		}
+		if _, err := store.GetByID(ctx, plain.ID); !errors.Is(err, repository.ErrCollectionNotFound) {
+			t.Errorf("get after delete: err = %v, want %v", err, repository.ErrCollectionNotFound)
+		}
+	})
+}
+
+func ids(list ...*models.Collection) string {
+	out := make([]string, len(list))
+	for i, c := range list {
+		out[i] = c.ID
+	}
+	return strings.Join(out, ",")
+}
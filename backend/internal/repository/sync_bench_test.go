@@ -0,0 +1,175 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/database"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// collectorsPerCPU sets how many collectors sync at once: RunParallel runs
+// this many goroutines per GOMAXPROCS.
+const collectorsPerCPU = 8
+
+// historyEvery is how often a collector reads the farmer's history.
+const historyEvery = 10
+
+// BenchmarkSync measures how many collections a SQLite database takes when
+// many collectors sync at once. Each op stores one upload as the API would:
+// look up the farmer, then insert the collection and append to the audit log
+// in one transaction; every tenth op also reads the farmer's history.
+//
+//	go test -run '^$' -bench Sync ./internal/repository/
+//
+// The legacy sub-benchmark opens the database the way the server did
+// before WAL and the split pools (one shared pool, rollback journal), for
+// comparison. Its writes may fail with "database is locked"; they are
+// counted, not fatal.
+func BenchmarkSync(b *testing.B) {
+	b.Run("wal", func(b *testing.B) {
+		db, err := database.ConnectSQLite(filepath.Join(b.TempDir(), "sync.db"), database.DefaultSQLiteOptions)
+		if err != nil {
+			b.Fatalf("connect: %v", err)
+		}
+		defer db.Close()
+		if err := database.RunMigrations(db, config.DriverSQLite); err != nil {
+			b.Fatalf("migrations: %v", err)
+		}
+		benchmarkSync(b, db, false)
+	})
+	b.Run("legacy", func(b *testing.B) {
+		path := filepath.Join(b.TempDir(), "sync.db")
+		db, err := database.ConnectSQLite(path, database.DefaultSQLiteOptions)
+		if err != nil {
+			b.Fatalf("connect: %v", err)
+		}
+		err = database.RunMigrations(db, config.DriverSQLite)
+		db.Close()
+		if err != nil {
+			b.Fatalf("migrations: %v", err)
+		}
+		old, err := sql.Open("sqlite3", path)
+		if err != nil {
+			b.Fatalf("open: %v", err)
+		}
+		defer old.Close()
+		for _, pragma := range []string{"PRAGMA journal_mode = DELETE", "PRAGMA foreign_keys = ON"} {
+			if _, err := old.Exec(pragma); err != nil {
+				b.Fatalf("%s: %v", pragma, err)
+			}
+		}
+		benchmarkSync(b, old, true)
+	})
+}
+
+func benchmarkSync(b *testing.B, db repository.DB, tolerateFailures bool) {
+	ctx := b.Context()
+	stores := repository.NewStores(db, config.DriverSQLite)
+	collectors := collectorsPerCPU * runtime.GOMAXPROCS(0)
+	farmerIDs := make([]string, collectors)
+	collectorIDs := make([]string, collectors)
+	for i := range collectors {
+		f := &models.Farmer{ID: uuid.New().String(), Name: "Bench Farmer", Phone: fmt.Sprintf("+25470%07d", i), PasswordHash: "hash"}
+		c := &models.Collector{ID: uuid.New().String(), Name: "Bench Collector", Phone: fmt.Sprintf("+25471%07d", i), PasswordHash: "hash"}
+		if err := stores.Farmers.Create(ctx, f); err != nil {
+			b.Fatalf("fixture farmer: %v", err)
+		}
+		if err := stores.Collectors.Create(ctx, c); err != nil {
+			b.Fatalf("fixture collector: %v", err)
+		}
+		farmerIDs[i], collectorIDs[i] = f.ID, c.ID
+	}
+
+	var (
+		next      atomic.Int64
+		mu        sync.Mutex
+		latencies []time.Duration
+		failures  = map[string]int{}
+	)
+	b.SetParallelism(collectorsPerCPU)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(1)-1) % collectors
+		var mine []time.Duration
+		for n := 0; pb.Next(); n++ {
+			began := time.Now()
+			err := syncOne(ctx, db, stores, farmerIDs[i], collectorIDs[i])
+			if err == nil && (n+1)%historyEvery == 0 {
+				_, err = stores.Collections.ListByFarmer(ctx, farmerIDs[i])
+			}
+			if err != nil {
+				mu.Lock()
+				failures[err.Error()]++
+				mu.Unlock()
+				continue
+			}
+			mine = append(mine, time.Since(began))
+		}
+		mu.Lock()
+		latencies = append(latencies, mine...)
+		mu.Unlock()
+	})
+	b.StopTimer()
+
+	failed := 0
+	for msg, n := range failures {
+		failed += n
+		if !tolerateFailures {
+			b.Errorf("%d x %s", n, msg)
+		}
+	}
+	b.ReportMetric(float64(failed)/float64(b.N), "failed/op")
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		b.ReportMetric(float64(percentile(latencies, 50).Microseconds()), "p50-µs")
+		b.ReportMetric(float64(percentile(latencies, 99).Microseconds()), "p99-µs")
+	}
+}
+
+// syncOne stores one uploaded collection the way CreateCollection does,
+// minus the catalog lookups.
+func syncOne(ctx context.Context, db repository.DB, stores repository.Stores, farmerID, collectorID string) error {
+	if _, err := stores.Farmers.GetByID(ctx, farmerID); err != nil {
+		return fmt.Errorf("farmer lookup: %w", err)
+	}
+	return repository.WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		c := &models.Collection{
+			ID: uuid.New().String(), FarmerID: farmerID, CollectorID: collectorID,
+			CropType: "tea", Quantity: 12, Unit: "kg", PricePerUnit: 25, WeightKg: 12, PricePerKg: 25,
+			Status: models.StatusPending,
+		}
+		if err := stores.With(tx).Collections.Create(ctx, c); err != nil {
+			return fmt.Errorf("collection insert: %w", err)
+		}
+		after, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		entry := &models.AuditEntry{
+			ActorID: collectorID, ActorRole: "collector",
+			Action: models.AuditCreate, EntityType: "collection", EntityID: c.ID, After: after,
+		}
+		if err := repository.NewAuditRepository(tx).Append(ctx, entry); err != nil {
+			return fmt.Errorf("audit append: %w", err)
+		}
+		return nil
+	})
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	return sorted[(len(sorted)-1)*p/100]
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Timestamps are stored in one layout in every table: UTC with millisecond
+// precision and a fixed width, so they compare and sort correctly as text,
+// e.g. 2026-02-14T08:30:00.123Z. PostgreSQL keeps them as TIMESTAMPTZ and
+// accepts the same text as a query argument.
+const timeLayout = "2006-01-02T15:04:05.000Z"
+
+// timeNow returns the current time exactly as it will read back from the
+// database.
+func timeNow() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// formatTime encodes t as a query argument.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// formatOptionalTime encodes t, or NULL when t is nil.
+func formatOptionalTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// parseTime decodes a stored timestamp. Anything not in timeLayout is an
+// error rather than a zero time.
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q, want %s", s, timeLayout)
+	}
+	return t, nil
+}
+
+// decodeTime reads a timestamp column value. SQLite returns the stored text
+// and PostgreSQL a time.Time; NULL decodes to nil.
+func decodeTime(src any) (*time.Time, error) {
+	var t time.Time
+	var err error
+	switch v := src.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		t = v.UTC()
+	case string:
+		t, err = parseTime(v)
+	case []byte:
+		t, err = parseTime(string(v))
+	default:
+		err = fmt.Errorf("cannot read %T as a timestamp", src)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// timeColumn scans a NOT NULL timestamp column into dst:
+//
+//	rows.Scan(&f.ID, timeColumn{&f.CreatedAt})
+//
+// A malformed value fails the Scan, naming the column.
+type timeColumn struct{ dst *time.Time }
+
+func (c timeColumn) Scan(src any) error {
+	t, err := decodeTime(src)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return errors.New("unexpected NULL timestamp")
+	}
+	*c.dst = *t
+	return nil
+}
+
+// nullTimeColumn scans a nullable timestamp column; NULL leaves dst nil.
+type nullTimeColumn struct{ dst **time.Time }
+
+func (c nullTimeColumn) Scan(src any) error {
+	t, err := decodeTime(src)
+	if err != nil {
+		return err
+	}
+	*c.dst = t
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/database"
+	"agri-sync-backend/internal/models"
+	"agri-sync-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var errAbort = errors.New("abort")
+
+// TestWithTx checks that stores and repositories built on a WithTx
+// transaction commit and roll back with it, including methods that would
+// otherwise open a transaction of their own.
+func TestWithTx(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := t.Context()
+		farmer := &models.Farmer{ID: uuid.New().String(), Name: "Tx Farmer", Phone: "+2547" + b.run + "6", PasswordHash: "hash"}
+		farm := &models.Farm{
+			ID: uuid.New().String(), FarmerID: farmer.ID, Name: "Tx Farm", AreaAcres: 1,
+			Plots: []*models.Plot{{ID: uuid.New().String(), Name: "Upper", CropType: "tea", StockCount: 10, StockUnit: "bushes"}},
+		}
+		create := func(ctx context.Context, tx *sql.Tx) error {
+			if err := b.stores.With(tx).Farmers.Create(ctx, farmer); err != nil {
+				return err
+			}
+			return repository.NewFarmRepository(tx).Create(ctx, farm)
+		}
+
+		t.Run("rollback", func(t *testing.T) {
+			err := repository.WithTx(ctx, b.db, func(ctx context.Context, tx *sql.Tx) error {
+				if err := create(ctx, tx); err != nil {
+					return err
+				}
+				return errAbort
+			})
+			if err != errAbort {
+				t.Fatalf("err = %v, want %v", err, errAbort)
+			}
+			if _, err := b.stores.Farmers.GetByID(ctx, farmer.ID); !errors.Is(err, repository.ErrFarmerNotFound) {
+				t.Errorf("farmer survived: err = %v", err)
+			}
+			if _, err := repository.NewFarmRepository(b.db).GetByID(ctx, farm.ID); !errors.Is(err, repository.ErrFarmNotFound) {
+				t.Errorf("farm survived: err = %v", err)
+			}
+		})
+
+		t.Run("commit", func(t *testing.T) {
+			if err := repository.WithTx(ctx, b.db, create); err != nil {
+				t.Fatalf("commit: %v", err)
+			}
+			if _, err := b.stores.Farmers.GetByID(ctx, farmer.ID); err != nil {
+				t.Errorf("farmer get: %v", err)
+			}
+			if got, err := repository.NewFarmRepository(b.db).GetByID(ctx, farm.ID); err != nil {
+				t.Errorf("farm get: %v", err)
+			} else if len(got.Plots) != 1 {
+				t.Errorf("farm has %d plots, want 1", len(got.Plots))
+			}
+		})
+
+		// A WithTx inside another joins it, and stores built on the
+		// database use the transaction carried by the context, so the
+		// outer rollback takes everything with it.
+		t.Run("nested", func(t *testing.T) {
+			joined := &models.Farmer{ID: uuid.New().String(), Name: "Joined Farmer", Phone: "+2547" + b.run + "9"}
+			err := repository.WithTx(ctx, b.db, func(ctx context.Context, _ *sql.Tx) error {
+				err := repository.WithTx(ctx, b.db, func(ctx context.Context, _ *sql.Tx) error {
+					return b.stores.Farmers.Create(ctx, joined)
+				})
+				if err != nil {
+					return err
+				}
+				if _, err := b.stores.Farmers.GetByID(ctx, joined.ID); err != nil {
+					return err
+				}
+				return errAbort
+			})
+			if err != errAbort {
+				t.Fatalf("err = %v, want %v", err, errAbort)
+			}
+			if _, err := b.stores.Farmers.GetByID(ctx, joined.ID); !errors.Is(err, repository.ErrFarmerNotFound) {
+				t.Errorf("farmer survived the outer rollback: err = %v", err)
+			}
+		})
+
+		// Beginning a second transaction on SQLite would wait for the one
+		// write connection, which the outer transaction holds.
+		t.Run("nested begin", func(t *testing.T) {
+			if b.db.Driver() != config.DriverSQLite {
+				t.Skip("PostgreSQL begins the second transaction on another connection")
+			}
+			err := repository.WithTx(ctx, b.db, func(ctx context.Context, _ *sql.Tx) error {
+				tx, err := b.db.BeginTx(ctx, nil)
+				if err == nil {
+					tx.Rollback()
+				}
+				return err
+			})
+			if !errors.Is(err, database.ErrNestedTx) {
+				t.Errorf("err = %v, want %v", err, database.ErrNestedTx)
+			}
+		})
+	})
+}
+
+// TestQueryContext checks that calls give up when their context does.
+func TestQueryContext(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		cancelled, cancel := context.WithCancel(t.Context())
+		cancel()
+		if _, err := b.stores.Farmers.GetByID(cancelled, uuid.New().String()); !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelled context: err = %v, want %v", err, context.Canceled)
+		}
+
+		limit := repository.QueryTimeout()
+		repository.SetQueryTimeout(time.Nanosecond)
+		defer repository.SetQueryTimeout(limit)
+		if _, err := b.stores.Farmers.GetByID(t.Context(), uuid.New().String()); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("query timeout: err = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
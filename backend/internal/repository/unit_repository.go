@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"agri-sync-backend/internal/models"
+)
+
+var (
+	ErrUnitNotFound = errors.New("unit not found")
+	ErrNoConversion = errors.New("no conversion to kg for unit")
+)
+
+type UnitRepository struct {
+	db DBTX
+}
+
+func NewUnitRepository(db DBTX) *UnitRepository {
+	return &UnitRepository{db: db}
+}
+
+// CREATE
+func (r *UnitRepository) Create(ctx context.Context, u *models.Unit) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO units (code, name_en, name_sw, dimension, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		u.Code, u.NameEn, u.NameSw, u.Dimension,
+		formatTime(u.CreatedAt), formatTime(u.UpdatedAt),
+	)
+	return err
+}
+
+// READ
+func (r *UnitRepository) GetByCode(ctx context.Context, code string) (*models.Unit, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var u models.Unit
+	err := r.db.QueryRowContext(ctx, `
+		SELECT code, name_en, name_sw, dimension, created_at, updated_at
+		FROM units WHERE code = ?`, code).
+		Scan(&u.Code, &u.NameEn, &u.NameSw, &u.Dimension, timeColumn{&u.CreatedAt}, timeColumn{&u.UpdatedAt})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUnitNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *UnitRepository) List(ctx context.Context) ([]*models.Unit, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT code, name_en, name_sw, dimension, created_at, updated_at
+		FROM units ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Unit
+	for rows.Next() {
+		var u models.Unit
+		if err := rows.Scan(&u.Code, &u.NameEn, &u.NameSw, &u.Dimension, timeColumn{&u.CreatedAt}, timeColumn{&u.UpdatedAt}); err != nil {
+			return nil, err
+		}
+		list = append(list, &u)
+	}
+	return list, rows.Err()
+}
+
+// SaveConversion inserts or replaces the factor for a crop and unit.
+func (r *UnitRepository) SaveConversion(ctx context.Context, uc *models.UnitConversion) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := timeNow()
+	uc.CreatedAt = now
+	uc.UpdatedAt = now
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO unit_conversions (crop_type, unit, to_kg, version, created_at, updated_at)
+		VALUES (?, ?, ?, 1, ?, ?)
+		ON CONFLICT (crop_type, unit) DO UPDATE
+		SET to_kg = excluded.to_kg, version = unit_conversions.version + 1, updated_at = excluded.updated_at
+		RETURNING version, created_at`,
+		uc.CropType, uc.Unit, uc.ToKg,
+		formatTime(uc.CreatedAt), formatTime(uc.UpdatedAt),
+	).Scan(&uc.Version, timeColumn{&uc.CreatedAt})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UnitRepository) ListConversions(ctx context.Context) ([]*models.UnitConversion, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT crop_type, unit, to_kg, version, created_at, updated_at
+		FROM unit_conversions ORDER BY crop_type, unit`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.UnitConversion
+	for rows.Next() {
+		var uc models.UnitConversion
+		if err := rows.Scan(&uc.CropType, &uc.Unit, &uc.ToKg, &uc.Version, timeColumn{&uc.CreatedAt}, timeColumn{&uc.UpdatedAt}); err != nil {
+			return nil, err
+		}
+		list = append(list, &uc)
+	}
+	return list, rows.Err()
+}
+
+// ToKg returns how many kg one unit of the crop weighs, preferring a
+// crop-specific conversion over the generic one.
+func (r *UnitRepository) ToKg(ctx context.Context, cropType, unit string) (float64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var factor float64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT to_kg FROM unit_conversions
+		WHERE unit = ? AND (crop_type = ? OR crop_type = '')
+		ORDER BY crop_type DESC
+		LIMIT 1`, unit, cropType).Scan(&factor)
+	if err == sql.ErrNoRows {
+		return 0, ErrNoConversion
+	}
+	return factor, err
+}
@@ -0,0 +1,102 @@
+package scale
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+const secret = "5c4a1f0e9b7d3a2c8e6f4b1d0a9c7e5f"
+
+func signed() Reading {
+	r := Reading{
+		ScaleID: "scale-1", Sequence: 42,
+		GrossKg: 12.5, TareKg: 0.5, NetKg: 12,
+		MeasuredAt: time.Date(2026, 2, 14, 8, 30, 0, 0, time.UTC),
+	}
+	r.Sign(secret)
+	return r
+}
+
+func TestVerify(t *testing.T) {
+	nairobi := time.FixedZone("EAT", 3*60*60)
+	tests := []struct {
+		name   string
+		change func(r *Reading)
+		secret string
+		want   bool
+	}{
+		{"as signed", func(r *Reading) {}, secret, true},
+		{"same instant in another zone", func(r *Reading) { r.MeasuredAt = r.MeasuredAt.In(nairobi) }, secret, true},
+		{"less than a gram heavier", func(r *Reading) { r.NetKg += 0.0004 }, secret, true},
+		{"upper-case signature", func(r *Reading) { r.Signature = strings.ToUpper(r.Signature) }, secret, true},
+
+		{"wrong secret", func(r *Reading) {}, "another secret", false},
+		{"empty secret", func(r *Reading) {}, "", false},
+		{"other scale", func(r *Reading) { r.ScaleID = "scale-2" }, secret, false},
+		{"replayed sequence", func(r *Reading) { r.Sequence++ }, secret, false},
+		{"gross changed", func(r *Reading) { r.GrossKg = 13.5 }, secret, false},
+		{"tare changed", func(r *Reading) { r.TareKg = 0.4 }, secret, false},
+		{"net a gram heavier", func(r *Reading) { r.NetKg += 0.001 }, secret, false},
+		{"measured a second later", func(r *Reading) { r.MeasuredAt = r.MeasuredAt.Add(time.Second) }, secret, false},
+		{"no signature", func(r *Reading) { r.Signature = "" }, secret, false},
+		{"not hex", func(r *Reading) { r.Signature = "zz" + r.Signature[2:] }, secret, false},
+		{"odd length", func(r *Reading) { r.Signature = r.Signature[1:] }, secret, false},
+		{"truncated", func(r *Reading) { r.Signature = r.Signature[:32] }, secret, false},
+		{"one bit flipped", func(r *Reading) {
+			b, _ := hex.DecodeString(r.Signature)
+			b[0] ^= 1
+			r.Signature = hex.EncodeToString(b)
+		}, secret, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := signed()
+			tt.change(&r)
+			if got := r.Verify(tt.secret); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsistent(t *testing.T) {
+	tests := []struct {
+		name             string
+		gross, tare, net float64
+		want             bool
+	}{
+		{"net is gross less tare", 12.5, 0.5, 12, true},
+		{"no tare", 8, 0, 8, true},
+		{"within a gram", 12.5, 0.5, 12.0009, true},
+		{"two grams out", 12.5, 0.5, 12.002, false},
+		{"tare not taken off", 12.5, 0.5, 12.5, false},
+		{"tare added", 12.5, 0.5, 13, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Reading{GrossKg: tt.gross, TareKg: tt.tare, NetKg: tt.net}
+			if got := r.Consistent(); got != tt.want {
+				t.Errorf("Consistent(%v - %v = %v) = %v, want %v", tt.gross, tt.tare, tt.net, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 64 || strings.Trim(a, "0123456789abcdef") != "" {
+		t.Errorf("secret %q is not 32 bytes of hex", a)
+	}
+	if a == b {
+		t.Errorf("two secrets are both %q", a)
+	}
+}
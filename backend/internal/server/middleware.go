@@ -0,0 +1,197 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"agri-sync-backend/internal/apierr"
+	"agri-sync-backend/internal/logging"
+	"agri-sync-backend/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxRequestIDLength = 128
+
+// requestID tags each request with an ID, reusing the client's X-Request-ID
+// when it sends a sensible one. The ID is echoed back in the response,
+// recorded in the audit log and added to every record logged through the
+// request context's logger.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if id == "" || len(id) > maxRequestIDLength || !printable(id) {
+			id = uuid.New().String()
+		}
+		c.Set("requestId", id)
+		c.Header("X-Request-ID", id)
+		// So a request reported by its ID can be found among the traces
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", id))
+		ctx := c.Request.Context()
+		c.Request = c.Request.WithContext(logging.NewContext(ctx, logging.FromContext(ctx).With("request_id", id)))
+		c.Next()
+	}
+}
+
+// probe says whether a request is a health probe or metrics scrape. They
+// come every few seconds and say nothing about users, so they get no span
+// and are logged only at debug level.
+func probe(c *gin.Context) bool {
+	switch c.FullPath() {
+	case "/healthz", "/readyz", "/health", "/metrics":
+		return true
+	}
+	return false
+}
+
+// traced says whether a request gets a span.
+func traced(c *gin.Context) bool {
+	return !probe(c)
+}
+
+// logRequests logs each request once it is served: at info level, warn for
+// 4xx and error for 5xx. Probes are logged at debug level, or warn when
+// they fail. The query string is left out, as searches may hold phone
+// numbers.
+func logRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case probe(c) && status < http.StatusBadRequest:
+			level = slog.LevelDebug
+		case probe(c), status < http.StatusInternalServerError && status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		}
+		ctx := c.Request.Context()
+		logger := logging.FromContext(ctx)
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+		}
+		if userID := c.GetString("userId"); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		logger.LogAttrs(ctx, level, "Request", attrs...)
+	}
+}
+
+// recoverPanics turns a handler's panic into a 500, logging it with its
+// stack. http.ErrAbortHandler is passed on, as net/http expects.
+func recoverPanics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			ctx := c.Request.Context()
+			logging.FromContext(ctx).ErrorContext(ctx, "Handler panicked", "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			apierr.Abort(c, apierr.New(apierr.InternalError, ""))
+		}()
+		c.Next()
+	}
+}
+
+func printable(s string) bool {
+	for _, r := range s {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// maxBody caps request bodies at limit. The routes in fileRoutes take whole
+// files and cap them at max_upload_bytes themselves.
+func maxBody(limit int64, fileRoutes ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(fileRoutes))
+	for _, route := range fileRoutes {
+		skip[route] = true
+	}
+	return func(c *gin.Context) {
+		if skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			apierr.Abort(c, apierr.New(apierr.PayloadTooLarge, "Request body too large").With("max_bytes", limit))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// maxSyncBatch is the largest X-Sync-Batch-Size believed; bigger values
+// are ignored rather than skewing the histogram.
+const maxSyncBatch = 10000
+
+// instrument records each request's count and latency under its route
+// pattern. Requests that match no route are recorded as "unmatched". A
+// device starting a sync run says how many changes it has queued in
+// X-Sync-Batch-Size, on the run's first request.
+func instrument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		if v := c.GetHeader("X-Sync-Batch-Size"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= maxSyncBatch {
+				metrics.ObserveSyncBatch(n)
+			}
+		}
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// bearerToken lets through only requests carrying token as their bearer
+// token. An empty token lets everything through.
+func bearerToken(token string) gin.HandlerFunc {
+	want := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), want) != 1 {
+			apierr.Abort(c, apierr.New(apierr.Unauthenticated, "Invalid metrics token"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,31 +1,54 @@
 package server
 
 import (
-	"database/sql"
+	"fmt"
 	"net/http"
 	"time"
+	"agri-sync-backend/internal/apierr"
 	"agri-sync-backend/internal/auth"
+	"agri-sync-backend/internal/backup"
+	"agri-sync-backend/internal/blob"
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/database"
 	"agri-sync-backend/internal/handler"
+	"agri-sync-backend/internal/models"
 	"agri-sync-backend/internal/repository"
+	"agri-sync-backend/internal/tracing"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func SetupRouter(db *sql.DB) *gin.Engine {
-	r := gin.Default()
+func SetupRouter(db *database.DB, cfg *config.Config) (*gin.Engine, error) {
+	// Gin's own route listing and warnings only at debug level
+	if cfg.LogLevel != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	r := gin.New()
+	r.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithGinFilter(traced)))
+	r.Use(requestID())
+	r.Use(logRequests())
+	r.Use(instrument())
+	r.Use(recoverPanics())
+	auth.Configure(cfg.JWTSecret, cfg.TokenTTL)
 
 	// Custom CORS so browser preflight allows Authorization header
 	corsConfig := cors.Config{
-		// allow only local dev origin when sending credentials
-		AllowOrigins:     []string{"http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		// only the configured origins, since credentials are sent
+		AllowOrigins:     cfg.CORSOrigins,
+		AllowMethods:     []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-Request-ID", "X-Device-ID", "X-Sync-Batch-Size", "Upload-Offset", "traceparent", "tracestate"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition", "Location", "X-Request-ID", "Upload-Offset", "Upload-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
 	r.Use(cors.New(corsConfig))
+	r.Use(maxBody(cfg.MaxBodyBytes, "/collections/:id/attachments", "/disputes/:id/attachments", "/uploads/:id"))
+
+	r.NoRoute(func(c *gin.Context) {
+		apierr.Abort(c, apierr.New(apierr.NotFound, "No such endpoint"))
+	})
 
 	// ensure preflight gets a short-circuit response (cors middleware usually handles this,
 	// but adding an explicit handler can help if some middleware runs before it)
@@ -33,33 +56,71 @@ func SetupRouter(db *sql.DB) *gin.Engine {
 		c.AbortWithStatus(http.StatusNoContent)
 	})
 
-	// Repositories
-	farmerRepo := repository.NewFarmerRepository(db)
-	collectorRepo := repository.NewCollectorRepository(db)
-	collectionRepo := repository.NewCollectionRepository(db)
+	// Repositories; farmers, collectors and collections have an
+	// implementation per database driver
+	stores := repository.NewStores(db, cfg.DBDriver)
+	farmerRepo := stores.Farmers
+	farmRepo := repository.NewFarmRepository(db)
+	lotRepo := repository.NewLotRepository(db)
+	scaleRepo := repository.NewScaleRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
 
-	// Health
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"version": "0.1.0",
-			"db":      "connected",
-		})
-	})
+	// Attachment storage
+	blobStore, err := blob.NewLocalStore(cfg.BlobDir)
+	if err != nil {
+		return nil, fmt.Errorf("blob store unavailable: %w", err)
+	}
+	uploadStaging, err := blob.NewStaging(cfg.UploadDir)
+	if err != nil {
+		return nil, fmt.Errorf("upload staging unavailable: %w", err)
+	}
+	backupOpts, err := backup.OptionsFrom(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("backups unavailable: %w", err)
+	}
+	collectorRepo := stores.Collectors
+	collectionRepo := stores.Collections
+	centerRepo := repository.NewCenterRepository(db)
+	routeRepo := repository.NewRouteRepository(db)
+	cropTypeRepo := repository.NewCropTypeRepository(db)
+	unitRepo := repository.NewUnitRepository(db)
+	priceRepo := repository.NewPriceRepository(db)
+	gradeRepo := repository.NewGradeRepository(db)
+	adjustmentRepo := repository.NewAdjustmentRepository(db)
+	disputeRepo := repository.NewDisputeRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	clerkRepo := repository.NewClerkRepository(db)
+	collectionDeps := &handlers.CollectionDeps{
+		Collections:          collectionRepo,
+		Farmers:              farmerRepo,
+		Farms:                farmRepo,
+		Centers:              centerRepo,
+		CropTypes:            cropTypeRepo,
+		Units:                unitRepo,
+		Prices:               priceRepo,
+		Grades:               gradeRepo,
+		Scales:               scaleRepo,
+		Audit:                auditRepo,
+		PriceTolerance:       cfg.PriceTolerance,
+		GeofenceRadiusM:      cfg.GeofenceRadiusM,
+		GeofenceMaxAccuracyM: cfg.GeofenceMaxAccuracyM,
+		ScaleToleranceKg:     cfg.ScaleToleranceKg,
+	}
 
 	// Public signup routes
 	r.POST("/farmers", func(c *gin.Context) {
-		handlers.CreateFarmer(c, farmerRepo)
+		handlers.CreateFarmer(c, farmerRepo, auditRepo)
 	})
 	r.POST("/collectors", func(c *gin.Context) {
-		handlers.CreateCollector(c, collectorRepo)
+		handlers.CreateCollector(c, collectorRepo, auditRepo)
 	})
 
 	// Auth (now with repos passed)
 	authGroup := r.Group("/auth")
 	{
 		authGroup.POST("/login", func(c *gin.Context) {
-			handlers.Login(c, farmerRepo, collectorRepo)
+			handlers.Login(c, farmerRepo, collectorRepo, clerkRepo, auditRepo)
 		})
 	}
 
@@ -71,7 +132,7 @@ func SetupRouter(db *sql.DB) *gin.Engine {
 
 		// Collections
 		protected.POST("/collections", func(c *gin.Context) {
-			handlers.CreateCollection(c, collectionRepo)
+			handlers.CreateCollection(c, collectionDeps)
 		})
 		protected.GET("/collections", func(c *gin.Context) {
 			handlers.ListCollections(c, collectionRepo)
@@ -80,7 +141,148 @@ func SetupRouter(db *sql.DB) *gin.Engine {
 			handlers.GetCollection(c, collectionRepo)
 		})
 		protected.PATCH("/collections/:id/status", func(c *gin.Context) {
-			handlers.UpdateCollectionStatus(c, collectionRepo)
+			handlers.UpdateCollectionStatus(c, collectionRepo, auditRepo)
+		})
+		protected.POST("/collections/:id/regrade", func(c *gin.Context) {
+			handlers.RegradeCollection(c, collectionRepo, adjustmentRepo, gradeRepo, priceRepo, unitRepo, centerRepo, auditRepo)
+		})
+		protected.POST("/collections/:id/attachments", func(c *gin.Context) {
+			handlers.UploadAttachment(c, models.OwnerCollection, attachmentRepo, collectionRepo, disputeRepo, auditRepo, blobStore, uploadStaging, cfg.MaxUploadBytes)
+		})
+		protected.GET("/collections/:id/attachments", func(c *gin.Context) {
+			handlers.ListAttachments(c, models.OwnerCollection, attachmentRepo, collectionRepo, disputeRepo)
+		})
+		protected.POST("/collections/:id/uploads", func(c *gin.Context) {
+			handlers.CreateUpload(c, models.OwnerCollection, attachmentRepo, collectionRepo, disputeRepo, auditRepo, uploadStaging, cfg.MaxUploadBytes)
+		})
+		protected.GET("/collections/:id/adjustments", func(c *gin.Context) {
+			handlers.ListCollectionAdjustments(c, collectionRepo, adjustmentRepo)
+		})
+		protected.POST("/collections/:id/disputes", func(c *gin.Context) {
+			handlers.CreateDispute(c, disputeRepo, collectionRepo, auditRepo)
+		})
+
+		// Clerks review disputes; admins add them
+		protected.POST("/clerks", func(c *gin.Context) {
+			handlers.CreateClerk(c, clerkRepo, auditRepo)
+		})
+
+		// Disputes
+		protected.GET("/disputes", func(c *gin.Context) {
+			handlers.ListDisputes(c, disputeRepo)
+		})
+		protected.GET("/disputes/queue", func(c *gin.Context) {
+			handlers.DisputeQueue(c, disputeRepo)
+		})
+		protected.GET("/disputes/:id", func(c *gin.Context) {
+			handlers.GetDispute(c, disputeRepo)
+		})
+		protected.POST("/disputes/:id/resolve", func(c *gin.Context) {
+			handlers.ResolveDispute(c, disputeRepo, collectionRepo, adjustmentRepo, auditRepo)
+		})
+		protected.POST("/disputes/:id/attachments", func(c *gin.Context) {
+			handlers.UploadAttachment(c, models.OwnerDispute, attachmentRepo, collectionRepo, disputeRepo, auditRepo, blobStore, uploadStaging, cfg.MaxUploadBytes)
+		})
+		protected.GET("/disputes/:id/attachments", func(c *gin.Context) {
+			handlers.ListAttachments(c, models.OwnerDispute, attachmentRepo, collectionRepo, disputeRepo)
+		})
+		protected.POST("/disputes/:id/uploads", func(c *gin.Context) {
+			handlers.CreateUpload(c, models.OwnerDispute, attachmentRepo, collectionRepo, disputeRepo, auditRepo, uploadStaging, cfg.MaxUploadBytes)
+		})
+
+		// Notifications
+		protected.GET("/notifications", func(c *gin.Context) {
+			handlers.ListNotifications(c, notificationRepo)
+		})
+		protected.POST("/notifications/read", func(c *gin.Context) {
+			handlers.MarkAllNotificationsRead(c, notificationRepo, auditRepo)
+		})
+		protected.POST("/notifications/:id/read", func(c *gin.Context) {
+			handlers.MarkNotificationRead(c, notificationRepo, auditRepo)
+		})
+
+		// Collection centers and routes
+		protected.GET("/centers", func(c *gin.Context) {
+			handlers.ListCenters(c, centerRepo)
+		})
+		protected.POST("/centers", func(c *gin.Context) {
+			handlers.CreateCenter(c, centerRepo, auditRepo)
+		})
+		protected.PUT("/centers/:id", func(c *gin.Context) {
+			handlers.UpdateCenter(c, centerRepo, auditRepo)
+		})
+		protected.GET("/routes", func(c *gin.Context) {
+			handlers.ListRoutes(c, routeRepo)
+		})
+		protected.POST("/routes", func(c *gin.Context) {
+			handlers.CreateRoute(c, routeRepo, centerRepo, auditRepo)
+		})
+		protected.PUT("/routes/:id", func(c *gin.Context) {
+			handlers.UpdateRoute(c, routeRepo, centerRepo, auditRepo)
+		})
+		protected.GET("/collector/route", func(c *gin.Context) {
+			handlers.GetRouteOfTheDay(c, routeRepo, centerRepo)
+		})
+
+		// Crop type registry
+		protected.GET("/crop-types", func(c *gin.Context) {
+			handlers.ListCropTypes(c, cropTypeRepo)
+		})
+		protected.POST("/crop-types", func(c *gin.Context) {
+			handlers.CreateCropType(c, cropTypeRepo, unitRepo, auditRepo)
+		})
+		protected.PUT("/crop-types/:code", func(c *gin.Context) {
+			handlers.UpdateCropType(c, cropTypeRepo, unitRepo, auditRepo)
+		})
+
+		// Units of measure
+		protected.GET("/units", func(c *gin.Context) {
+			handlers.ListUnits(c, unitRepo)
+		})
+		protected.POST("/units", func(c *gin.Context) {
+			handlers.CreateUnit(c, unitRepo, auditRepo)
+		})
+		protected.PUT("/units/conversions", func(c *gin.Context) {
+			handlers.SaveUnitConversion(c, unitRepo, auditRepo)
+		})
+
+		// Price catalog
+		protected.GET("/prices", func(c *gin.Context) {
+			handlers.ListPrices(c, priceRepo)
+		})
+		protected.POST("/prices", func(c *gin.Context) {
+			handlers.CreatePrice(c, priceRepo, unitRepo, auditRepo)
+		})
+		protected.PUT("/prices/:id", func(c *gin.Context) {
+			handlers.UpdatePrice(c, priceRepo, unitRepo, auditRepo)
+		})
+		protected.DELETE("/prices/:id", func(c *gin.Context) {
+			handlers.DeletePrice(c, priceRepo, auditRepo)
+		})
+
+		// Quality grades
+		protected.GET("/grades", func(c *gin.Context) {
+			handlers.ListGrades(c, gradeRepo)
+		})
+		protected.POST("/grades", func(c *gin.Context) {
+			handlers.CreateGrade(c, gradeRepo, auditRepo)
+		})
+		protected.PUT("/grades/:id", func(c *gin.Context) {
+			handlers.UpdateGrade(c, gradeRepo, auditRepo)
+		})
+		protected.DELETE("/grades/:id", func(c *gin.Context) {
+			handlers.DeleteGrade(c, gradeRepo, auditRepo)
+		})
+
+		// Reports
+		protected.GET("/reports/crops", func(c *gin.Context) {
+			handlers.GetCropReport(c, collectionRepo)
+		})
+		protected.GET("/reports/centers", func(c *gin.Context) {
+			handlers.GetCenterReport(c, collectionRepo)
+		})
+		protected.GET("/reports/disputes", func(c *gin.Context) {
+			handlers.GetDisputeReport(c, disputeRepo)
 		})
 
 		// Farmer-specific endpoints
@@ -88,17 +290,111 @@ func SetupRouter(db *sql.DB) *gin.Engine {
 			handlers.GetFarmerHistory(c, collectionRepo)
 		})
 		protected.GET("/farmer/wallet", func(c *gin.Context) {
-			handlers.GetFarmerWallet(c, collectionRepo)
+			handlers.GetFarmerWallet(c, collectionRepo, adjustmentRepo, cfg.Currency)
 		})
 
 		// Profile endpoints
 		protected.GET("/farmers/:id", func(c *gin.Context) {
 			handlers.GetFarmerProfile(c, farmerRepo)
 		})
+		protected.PUT("/farmers/:id/location", func(c *gin.Context) {
+			handlers.SetFarmerLocation(c, farmerRepo, auditRepo)
+		})
+
+		// Farms and plots
+		protected.GET("/farms", func(c *gin.Context) {
+			handlers.ListFarms(c, farmRepo)
+		})
+		protected.GET("/farms/:id", func(c *gin.Context) {
+			handlers.GetFarm(c, farmRepo)
+		})
+		protected.POST("/farms", func(c *gin.Context) {
+			handlers.CreateFarm(c, farmRepo, farmerRepo, cropTypeRepo, auditRepo)
+		})
+		protected.PUT("/farms/:id", func(c *gin.Context) {
+			handlers.UpdateFarm(c, farmRepo, cropTypeRepo, auditRepo)
+		})
+		protected.DELETE("/farms/:id", func(c *gin.Context) {
+			handlers.DeleteFarm(c, farmRepo, auditRepo)
+		})
+
+		// Lots and traceability
+		protected.GET("/lots", func(c *gin.Context) {
+			handlers.ListLots(c, lotRepo)
+		})
+		protected.GET("/lots/:id", func(c *gin.Context) {
+			handlers.GetLot(c, lotRepo)
+		})
+		protected.POST("/lots", func(c *gin.Context) {
+			handlers.CreateLot(c, lotRepo, collectionRepo, centerRepo, auditRepo)
+		})
+		protected.POST("/lots/:id/collections", func(c *gin.Context) {
+			handlers.AddLotCollections(c, db, stores, auditRepo)
+		})
+		protected.POST("/lots/:id/transfers", func(c *gin.Context) {
+			handlers.DispatchLot(c, lotRepo, centerRepo, auditRepo)
+		})
+		protected.POST("/lots/:id/transfers/:transferId/receive", func(c *gin.Context) {
+			handlers.ReceiveLotTransfer(c, lotRepo, auditRepo)
+		})
+		protected.GET("/lots/:id/trace", func(c *gin.Context) {
+			handlers.TraceLot(c, lotRepo)
+		})
+
+		// Attachments and resumable uploads
+		protected.GET("/attachments/:id", func(c *gin.Context) {
+			handlers.DownloadAttachment(c, attachmentRepo, collectionRepo, disputeRepo, blobStore)
+		})
+		protected.HEAD("/uploads/:id", func(c *gin.Context) {
+			handlers.GetUpload(c, attachmentRepo, uploadStaging)
+		})
+		protected.GET("/uploads/:id", func(c *gin.Context) {
+			handlers.GetUpload(c, attachmentRepo, uploadStaging)
+		})
+		protected.PATCH("/uploads/:id", func(c *gin.Context) {
+			handlers.AppendUpload(c, attachmentRepo, auditRepo, blobStore, uploadStaging)
+		})
+
+		// Digital scales
+		protected.GET("/scales", func(c *gin.Context) {
+			handlers.ListScales(c, scaleRepo)
+		})
+		protected.POST("/scales", func(c *gin.Context) {
+			handlers.CreateScale(c, scaleRepo, centerRepo, auditRepo)
+		})
+		protected.PUT("/scales/:id", func(c *gin.Context) {
+			handlers.UpdateScale(c, scaleRepo, centerRepo, auditRepo)
+		})
+		protected.POST("/scales/readings", func(c *gin.Context) {
+			handlers.SubmitScaleReading(c, scaleRepo, auditRepo)
+		})
+		protected.GET("/scales/readings/:id", func(c *gin.Context) {
+			handlers.GetScaleReading(c, scaleRepo)
+		})
+
+		// Audit log
+		protected.GET("/audit", func(c *gin.Context) {
+			handlers.ListAuditLog(c, auditRepo)
+		})
+		protected.GET("/audit/verify", func(c *gin.Context) {
+			handlers.VerifyAuditLog(c, auditRepo)
+		})
+
+		// Backups
+		protected.POST("/backups", func(c *gin.Context) {
+			handlers.CreateBackup(c, db, auditRepo, backupOpts)
+		})
+		protected.GET("/backups", func(c *gin.Context) {
+			handlers.ListBackups(c, backupOpts)
+		})
+		protected.POST("/backups/:file/verify", func(c *gin.Context) {
+			handlers.VerifyBackup(c, backupOpts)
+		})
+
 		protected.GET("/collectors/:id", func(c *gin.Context) {
 			handlers.GetCollectorProfile(c, collectorRepo)
 		})
 	}
 
-	return r
+	return r, nil
 }
\ No newline at end of file
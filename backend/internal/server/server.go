@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"agri-sync-backend/internal/backup"
+	"agri-sync-backend/internal/buildinfo"
+	"agri-sync-backend/internal/config"
+	"agri-sync-backend/internal/database"
+	"agri-sync-backend/internal/health"
+	"agri-sync-backend/internal/metrics"
+	"agri-sync-backend/internal/repository"
+	"agri-sync-backend/internal/worker"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server is the API's HTTP server and the background workers beside it.
+type Server struct {
+	cfg      *config.Config
+	http     *http.Server
+	workers  *worker.Group
+	draining atomic.Bool
+
+	// live and ready back /healthz and /readyz
+	live  *health.Registry
+	ready *health.Registry
+}
+
+// New builds the server and starts its background workers.
+func New(db *database.DB, cfg *config.Config) (*Server, error) {
+	s := &Server{
+		cfg:     cfg,
+		workers: worker.NewGroup(),
+		live:    health.NewRegistry(cfg.HealthCheckTimeout),
+		ready:   health.NewRegistry(cfg.HealthCheckTimeout),
+	}
+
+	// Liveness fails only when a restart would help
+	s.live.Add("workers", health.Workers(s.workers))
+
+	s.ready.Add("shutdown", health.CheckerFunc(func(ctx context.Context) (string, error) {
+		if s.draining.Load() {
+			return "", errors.New("shutting down")
+		}
+		return "serving", nil
+	}))
+	s.ready.Add("database", health.Database(db))
+	s.ready.Add("database_write", health.DatabaseWrite(repository.NewHealthRepository(db)))
+	s.ready.Add("migrations", health.Migrations(db, cfg.DBDriver))
+	if cfg.DBDriver == config.DriverSQLite {
+		s.ready.Add("disk", health.DiskSpace(filepath.Dir(cfg.DBPath), cfg.HealthMinFreeBytes))
+	}
+
+	router, err := SetupRouter(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+	router.GET("/healthz", s.report(s.live))
+	router.GET("/readyz", s.report(s.ready))
+	// Before /healthz and /readyz there was only /health
+	router.GET("/health", s.report(s.ready))
+
+	repository.SetQueryObserver(metrics.ObserveQuery)
+	metrics.ReportBusiness(repository.NewStores(db, cfg.DBDriver).Collections, repository.NewStatsRepository(db))
+	router.GET("/metrics", bearerToken(cfg.MetricsToken), gin.WrapH(metrics.Handler()))
+
+	s.http = &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	if cfg.BackupInterval > 0 {
+		opts, err := backup.OptionsFrom(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.workers.Every("backup", cfg.BackupInterval, func(ctx context.Context) error {
+			m, err := backup.Create(ctx, db, opts)
+			if m != nil {
+				slog.InfoContext(ctx, "Scheduled backup written", "file", m.File)
+			}
+			return err
+		})
+	}
+	return s, nil
+}
+
+// report serves a registry's checks: 200 if all pass, else 503. /readyz
+// turns 503 once shutdown starts, so load balancers stop sending traffic
+// before the listener closes.
+func (s *Server) report(checks *health.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checks.Run(c.Request.Context())
+		status := http.StatusOK
+		if report.Status != health.StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{
+			"status":     report.Status,
+			"version":    buildinfo.Version,
+			"commit":     buildinfo.Commit,
+			"build_time": buildinfo.BuildTime,
+			"checks":     report.Checks,
+		})
+	}
+}
+
+// Run serves until ctx is cancelled, then shuts down: /readyz turns 503
+// for the shutdown delay, the listener closes, and in-flight requests and
+// background workers get the shutdown timeout to finish.
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if s.cfg.TLSCertFile != "" {
+			slog.Info("AgriSync API listening", "addr", s.cfg.ListenAddr, "tls", true)
+			serveErr <- s.http.ListenAndServeTLS(s.cfg.TLSCertFile, s.cfg.TLSKeyFile)
+		} else {
+			slog.Info("AgriSync API listening", "addr", s.cfg.ListenAddr, "tls", false)
+			serveErr <- s.http.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		// The listener failed, e.g. the port is taken
+		stopCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+		defer cancel()
+		return errors.Join(err, s.workers.Stop(stopCtx))
+	case <-ctx.Done():
+	}
+
+	s.draining.Store(true)
+	slog.Info("Shutting down", "not_ready_for", s.cfg.ShutdownDelay.String(), "drain_timeout", s.cfg.ShutdownTimeout.String())
+	time.Sleep(s.cfg.ShutdownDelay)
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+	workersStopped := make(chan error, 1)
+	go func() { workersStopped <- s.workers.Stop(stopCtx) }()
+
+	var problems []error
+	if err := s.http.Shutdown(stopCtx); err != nil {
+		s.http.Close()
+		problems = append(problems, fmt.Errorf("requests still running at the deadline were cut off: %w", err))
+	}
+	if err := <-workersStopped; err != nil {
+		problems = append(problems, fmt.Errorf("background workers still running at the deadline were cancelled: %w", err))
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		problems = append(problems, err)
+	}
+	return errors.Join(problems...)
+}
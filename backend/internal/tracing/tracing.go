@@ -0,0 +1,88 @@
+// Package tracing sets up OpenTelemetry tracing. Code that makes spans
+// takes its tracer from the global provider installed here, so with the
+// "none" exporter spans cost next to nothing and go nowhere.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"agri-sync-backend/internal/buildinfo"
+	"agri-sync-backend/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// ServiceName is the service.name spans are reported under, unless
+// OTEL_SERVICE_NAME says otherwise.
+const ServiceName = "agrisync-api"
+
+// Setup installs W3C trace-context propagation and, unless the exporter is
+// "none", a tracer provider sending spans to it. The returned func flushes
+// spans not yet sent; call it on shutdown.
+func Setup(ctx context.Context, cfg *config.Config) (shutdown func(context.Context) error, err error) {
+	// Incoming trace context is honoured even when nothing is exported, so
+	// a request's trace id still reaches its children
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.TraceExporter {
+	case config.TraceExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case config.TraceExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case config.TraceExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.TraceOTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(tracesURL(cfg.TraceOTLPEndpoint)))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.TraceExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName), semconv.ServiceVersion(buildinfo.Version)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// The ratio applies to clients' traces too, so the app cannot raise
+		// the volume; spans within a kept trace are all kept
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(cfg.TraceSampleRatio),
+			sdktrace.WithRemoteParentSampled(sdktrace.TraceIDRatioBased(cfg.TraceSampleRatio)),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// tracesURL adds the OTLP/HTTP traces path to a collector's base URL, as
+// in http://localhost:4318, leaving a URL with a path as it is.
+func tracesURL(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || strings.Trim(u.Path, "/") != "" {
+		return endpoint
+	}
+	u.Path = "/v1/traces"
+	return u.String()
+}
@@ -0,0 +1,148 @@
+// Package worker runs the server's background jobs and stops them on
+// shutdown.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("agri-sync-backend/internal/worker")
+
+// Group runs named jobs on an interval until Stop.
+type Group struct {
+	stopping chan struct{}
+	stopOnce sync.Once
+	// runCtx is given to each run; it is cancelled when Stop gives up
+	// waiting.
+	runCtx context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs []*job
+}
+
+// job is a job's schedule and what it last did.
+type job struct {
+	name     string
+	interval time.Duration
+
+	// guarded by Group.mu
+	running   bool
+	since     time.Time // when the job last started or finished a run
+	lastRun   time.Time // when the last run finished
+	lastError error
+}
+
+// Status is a job's state, for health checks.
+type Status struct {
+	Name      string
+	Interval  time.Duration
+	Running   bool
+	Since     time.Time // start of the current run, or end of the last one
+	LastRun   time.Time // zero until a run finishes
+	LastError error     // of the last run
+}
+
+// Stalled reports whether the job has gone two intervals without starting
+// or finishing a run: it is stuck in a run, or its loop has died.
+func (s Status) Stalled(now time.Time) bool {
+	return now.Sub(s.Since) > 2*s.Interval
+}
+
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{stopping: make(chan struct{}), runCtx: ctx, cancel: cancel}
+}
+
+// Every runs fn every interval, first after one interval. A run that
+// fails is logged and the job carries on. Runs of one job never overlap;
+// each is traced as a span of its own.
+func (g *Group) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	j := &job{name: name, interval: interval, since: time.Now()}
+	g.mu.Lock()
+	g.jobs = append(g.jobs, j)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-g.stopping:
+				return
+			case <-ticker.C:
+			}
+			g.record(j, true, nil)
+			ctx, span := tracer.Start(g.runCtx, "worker "+name)
+			err := fn(ctx)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				slog.ErrorContext(ctx, "Background job failed", "job", name, "error", err)
+			}
+			span.End()
+			g.record(j, false, err)
+		}
+	}()
+}
+
+func (g *Group) record(j *job, running bool, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	j.running, j.since = running, now
+	if !running {
+		j.lastRun, j.lastError = now, err
+	}
+}
+
+// Jobs returns the state of every job, in the order they were added.
+func (g *Group) Jobs() []Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	list := make([]Status, len(g.jobs))
+	for i, j := range g.jobs {
+		list[i] = Status{
+			Name:      j.name,
+			Interval:  j.interval,
+			Running:   j.running,
+			Since:     j.since,
+			LastRun:   j.lastRun,
+			LastError: j.lastError,
+		}
+	}
+	return list
+}
+
+// Stop starts no new runs and waits for the running ones to finish. If ctx
+// ends first, their context is cancelled and Stop returns ctx.Err()
+// without waiting further.
+func (g *Group) Stop(ctx context.Context) error {
+	g.stopOnce.Do(func() { close(g.stopping) })
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	defer g.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	select {
+	case <-done: // finished just as ctx ended
+		return nil
+	default:
+		return ctx.Err()
+	}
+}
@@ -1,3 +1,5 @@
+import { languageHeader, problemError } from './lib/problem';
+
 export const API_BASE = 'http://localhost:8080';
 const TOKEN_KEY = 'agrisync-token';
 const ROLE_KEY = 'agrisync-role';
@@ -23,13 +25,10 @@ export function authHeader(): Record<string, string> {
 export async function login(phone: string, password: string, role: 'farmer'|'collector'|'admin') {
   const res = await fetch(`${API_BASE}/auth/login`, {
     method: 'POST',
-    headers: { 'Content-Type': 'application/json' },
+    headers: { 'Content-Type': 'application/json', ...languageHeader() },
     body: JSON.stringify({ phone, password, role }),
   });
-  if (!res.ok) {
-    const text = await res.text();
-    throw new Error(text || res.statusText);
-  }
+  if (!res.ok) throw await problemError(res);
   const data = await res.json();
   if (data.token) setToken(data.token);
   if (data.role) setStoredRole(data.role);
@@ -43,26 +42,20 @@ export async function login(phone: string, password: string, role: 'farmer'|'col
 export async function signupFarmer(payload: { name: string; phone: string; password: string }) {
   const res = await fetch(`${API_BASE}/farmers`, {
     method: 'POST',
-    headers: { 'Content-Type': 'application/json' },
+    headers: { 'Content-Type': 'application/json', ...languageHeader() },
     body: JSON.stringify(payload),
   });
-  if (!res.ok) {
-    const text = await res.text();
-    throw new Error(text || res.statusText);
-  }
+  if (!res.ok) throw await problemError(res);
   return await res.json();
 }
 
 export async function signupCollector(payload: { name: string; phone: string; password: string }) {
   const res = await fetch(`${API_BASE}/collectors`, {
     method: 'POST',
-    headers: { 'Content-Type': 'application/json' },
+    headers: { 'Content-Type': 'application/json', ...languageHeader() },
     body: JSON.stringify(payload),
   });
-  if (!res.ok) {
-    const text = await res.text();
-    throw new Error(text || res.statusText);
-  }
+  if (!res.ok) throw await problemError(res);
   return await res.json();
 }
 
@@ -77,7 +70,7 @@ export async function fetchProfile(): Promise<any | null> {
   const path = role === 'farmer' ? `/farmers/${id}` : `/collectors/${id}`;
   const res = await fetch(`${API_BASE}${path}`, {
     method: 'GET',
-    headers: { 'Content-Type': 'application/json', ...authHeader() },
+    headers: { 'Content-Type': 'application/json', ...authHeader(), ...languageHeader() },
   });
   if (!res.ok) return null;
   const json = await res.json();
@@ -1,6 +1,7 @@
 import { enqueueOp } from './syncQueue';
 import { v4 as uuidv4 } from 'uuid';
 import { API_BASE, authHeader } from '../auth';
+import { newTraceId, traceHeaders } from './trace';
 
 export type CreateCollectionPayload = {
   farmerId: string;
@@ -27,6 +28,7 @@ export async function createCollection(payload: CreateCollectionPayload) {
         headers: {
           'Content-Type': 'application/json',
           ...authHeader(),
+          ...traceHeaders(newTraceId()),
         },
         body: JSON.stringify(op.body),
       });
@@ -61,6 +63,7 @@ export async function updateCollectionStatus(id: string, status: string, version
         headers: {
           'Content-Type': 'application/json',
           ...authHeader(),
+          ...traceHeaders(newTraceId()),
         },
         body: JSON.stringify(op.body),
       });
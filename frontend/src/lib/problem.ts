@@ -0,0 +1,43 @@
+// Errors from the API are RFC 7807 problems (application/problem+json).
+// Branch on `code`; show `title`, which the server writes in the language
+// asked for with Accept-Language.
+
+export type FieldProblem = {
+  field: string;
+  rule: string;
+  param?: string;
+  message: string;
+};
+
+export type Problem = {
+  type: string;
+  title: string;
+  status: number;
+  code: string;
+  detail?: string;
+  instance?: string;
+  request_id?: string;
+  errors?: FieldProblem[];
+  [extension: string]: unknown;
+};
+
+/** Accept-Language for API requests, so problems come back translated. */
+export function languageHeader(): Record<string, string> {
+  return navigator.language ? { 'Accept-Language': navigator.language } : {};
+}
+
+/** The problem in an error response, or null if the body is not one. */
+export async function readProblem(res: Response): Promise<Problem | null> {
+  const ct = res.headers.get('content-type') || '';
+  if (!ct.includes('json')) return null;
+  const body = await res.json().catch(() => null);
+  return body && typeof body.code === 'string' ? (body as Problem) : null;
+}
+
+/** An Error for a failed response, with the problem's title as message. */
+export async function problemError(res: Response): Promise<Error & { problem?: Problem }> {
+  const problem = await readProblem(res);
+  const err: Error & { problem?: Problem } = new Error(problem?.title || res.statusText);
+  if (problem) err.problem = problem;
+  return err;
+}
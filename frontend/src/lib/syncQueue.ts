@@ -1,6 +1,8 @@
 import { db } from './db';
 import type { OutgoingOp } from './db';
 import { authHeader } from '../auth';
+import { newTraceId, traceHeaders } from './trace';
+import { languageHeader, readProblem } from './problem';
 
 const MAX_RETRIES = 5;
 const BASE_DELAY_MS = 1000;
@@ -9,9 +11,11 @@ function delay(ms: number) {
   return new Promise((res) => setTimeout(res, ms));
 }
 
-async function sendOp(op: OutgoingOp) {
+// batchSize is sent with the first request of a queue run; traceId is the run's
+async function sendOp(op: OutgoingOp, traceId: string, batchSize?: number) {
   try {
-    const headers: any = { 'Content-Type': 'application/json', ...authHeader() };
+    const headers: any = { 'Content-Type': 'application/json', ...authHeader(), ...languageHeader(), ...traceHeaders(traceId) };
+    if (batchSize) headers['X-Sync-Batch-Size'] = String(batchSize);
 
     const res = await fetch(op.url, {
       method: op.method,
@@ -26,8 +30,8 @@ async function sendOp(op: OutgoingOp) {
     }
 
     if (!res.ok) {
-      const txt = await res.text().catch(() => res.statusText);
-      throw new Error(`HTTP ${res.status}: ${txt}`);
+      const problem = await readProblem(res);
+      throw new Error(`HTTP ${res.status}: ${problem?.code ?? res.statusText}`);
     }
 
     return await res.json().catch(() => ({}));
@@ -49,6 +53,8 @@ export async function processQueueOnce() {
   running = true;
   try {
     const pending = await db.outgoing.where('status').equals('pending').limit(10).toArray();
+    let batchSize: number | undefined = pending.length;
+    const traceId = newTraceId();
     for (const op of pending) {
       await db.outgoing.update(op.id, { status: 'processing' });
       try {
@@ -56,7 +62,9 @@ export async function processQueueOnce() {
         let success = false;
         while (attempt < MAX_RETRIES && !success) {
           try {
-            await sendOp(op);
+            const size = batchSize;
+            batchSize = undefined;
+            await sendOp(op, traceId, size);
             success = true;
             await db.outgoing.update(op.id, { status: 'done' });
             await db.outgoing.delete(op.id);
@@ -0,0 +1,19 @@
+// W3C trace context (https://www.w3.org/TR/trace-context/) for API requests.
+// Every request in one sync run shares a trace id, so the server's traces
+// show the whole run together.
+
+function randomHex(bytes: number): string {
+  const buf = new Uint8Array(bytes);
+  crypto.getRandomValues(buf);
+  return Array.from(buf, (b) => b.toString(16).padStart(2, '0')).join('');
+}
+
+/** A new trace id, for one sync run. */
+export function newTraceId(): string {
+  return randomHex(16);
+}
+
+/** The traceparent header for one request in the trace. */
+export function traceHeaders(traceId: string): Record<string, string> {
+  return { traceparent: `00-${traceId}-${randomHex(8)}-01` };
+}
@@ -1,13 +1,17 @@
 import { getAllCollections } from './db';
 import { API_BASE, authHeader, getToken, getStoredRole } from './auth';
+import { newTraceId, traceHeaders } from './lib/trace';
+import { readProblem } from './lib/problem';
 
 const COLLECTIONS_ENDPOINT = `${API_BASE}/collections`;
 
 /**
  * Post with per-item exponential backoff retries.
  * Treat 4xx (except 429) as permanent. Treat 5xx and network errors as transient.
+ * batchSize is sent (once, on the first attempt) with the first item of a sync run.
+ * traceId ties the run's requests together in the server's traces.
  */
-async function postWithRetry(item: any, maxAttempts = 5) {
+async function postWithRetry(item: any, traceId: string, maxAttempts = 5, batchSize?: number) {
   let attempt = 0;
   let delay = 500;
   while (attempt < maxAttempts) {
@@ -18,6 +22,8 @@ async function postWithRetry(item: any, maxAttempts = 5) {
         headers: {
           'Content-Type': 'application/json',
           ...authHeader(),
+          ...traceHeaders(traceId),
+          ...(batchSize && attempt === 1 ? { 'X-Sync-Batch-Size': String(batchSize) } : {}),
         },
         body: JSON.stringify({
           farmer_id: item.farmerId,
@@ -32,13 +38,11 @@ async function postWithRetry(item: any, maxAttempts = 5) {
       if (res.ok) return { ok: true, res };
       if (res.status >= 400 && res.status < 500 && res.status !== 429) {
         try {
-          const ct = res.headers.get('content-type') || '';
-          const msg = ct.includes('application/json')
-            ? JSON.stringify(await res.json().catch(() => ({})))
-            : await res.text();
-          console.warn('[sync] permanent client error', res.status, 'posting collection', item?.id ?? '(unknown)', msg);
+          const problem = await readProblem(res);
+          const msg = problem ? `${problem.code}: ${problem.detail ?? problem.title}` : res.statusText;
+          console.warn('[sync] permanent client error', res.status, 'posting collection', item?.id ?? '(unknown)', 'trace', traceId, msg);
         } catch (_) {
-          console.warn('[sync] permanent client error', res.status, 'posting collection', item?.id ?? '(unknown)');
+          console.warn('[sync] permanent client error', res.status, 'posting collection', item?.id ?? '(unknown)', 'trace', traceId);
         }
         return { ok: false, res, permanent: true };
       }
@@ -75,10 +79,12 @@ export function startSyncProcess(intervalMs = 30000) {
       const all = await getAllCollections();
       const pending = all.filter((x: any) => x.status === 'pending' || x.status === 'error');
       if (!pending.length) return;
+      const traceId = newTraceId();
 
-      for (const item of pending) {
+      for (let i = 0; i < pending.length; i++) {
+        const item = pending[i];
         try {
-          const result = await postWithRetry(item, 5);
+          const result = await postWithRetry(item, traceId, 5, i === 0 ? pending.length : undefined);
           if (result.ok) {
             try {
               const db = await import('./db');